@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/klaital/wannetiot/pkg/alarms"
+	"github.com/klaital/wannetiot/pkg/lights"
+)
+
+// AlarmResponse adds the computed schedule to an alarm.
+type AlarmResponse struct {
+	alarms.Alarm
+	NextWakeup *time.Time `json:"next_wakeup,omitempty"`
+	NextDue    *time.Time `json:"next_due,omitempty"`
+}
+
+func (srv *Server) alarmResponse(a alarms.Alarm) AlarmResponse {
+	r := AlarmResponse{Alarm: a}
+	if start, due, ok := srv.alarms.NextWakeup(a, time.Now()); ok {
+		r.NextWakeup = &start
+		r.NextDue = &due
+	}
+	return r
+}
+
+// writeJSON sends v as the response body with the given status code.
+func (srv *Server) writeJSON(resp http.ResponseWriter, status int, v interface{}) {
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(status)
+	if err := json.NewEncoder(resp).Encode(v); err != nil {
+		srv.Logger.WithError(err).Error("Failed to write response body")
+	}
+}
+
+// validateAlarm checks the alarm's references to curves, effects, zones and
+// scenes, which the alarms package can't check itself.
+func (srv *Server) validateAlarm(a alarms.Alarm) error {
+	if a.Curve != "" {
+		if _, err := lights.LookupCurve(a.Curve); err != nil {
+			return err
+		}
+	}
+	if a.Effect != "" {
+		if _, err := lights.ParseEffect(a.Effect); err != nil {
+			return err
+		}
+	}
+	if _, err := lights.SelectZones(a.Zones...); err != nil {
+		return err
+	}
+	if a.Scene != "" && srv.scenes != nil {
+		if _, err := srv.scenes.Scene(a.Scene); err != nil {
+			// A missing scene is a mistake in the alarm, not a missing resource
+			return fmt.Errorf("%w: scene %q: %v", alarms.ErrInvalidAlarm, a.Scene, err)
+		}
+	}
+	return nil
+}
+
+// serveAlarms handles the unversioned alarm routes, with the /api/v1 handlers:
+//
+//	GET    /alarms
+//	POST   /alarms
+//	GET    /alarms/{id}
+//	PUT    /alarms/{id}
+//	DELETE /alarms/{id}
+//	POST   /alarms/{id}/skip    skip the next occurrence
+//	DELETE /alarms/{id}/skip    un-skip the next occurrence
+func (srv *Server) serveAlarms(resp http.ResponseWriter, req *http.Request, path []string, body []byte) {
+	// Trailing slashes leave an empty final token
+	if len(path) > 0 && path[len(path)-1] == "" {
+		path = path[:len(path)-1]
+	}
+
+	var h apiHandler
+	switch {
+	case len(path) == 0 && req.Method == http.MethodGet:
+		h = srv.apiListAlarms
+	case len(path) == 0 && req.Method == http.MethodPost:
+		h = srv.apiCreateAlarm
+	case len(path) == 1 && req.Method == http.MethodGet:
+		h = srv.apiGetAlarm
+	case len(path) == 1 && req.Method == http.MethodPut:
+		h = srv.apiUpdateAlarm
+	case len(path) == 1 && req.Method == http.MethodDelete:
+		h = srv.apiDeleteAlarm
+	case len(path) == 2 && path[1] == "skip" && (req.Method == http.MethodPost || req.Method == http.MethodDelete):
+		h = srv.apiSkipAlarm(req.Method == http.MethodPost)
+	default:
+		http.Error(resp, "invalid alarms request", http.StatusNotFound)
+		return
+	}
+	srv.serveLegacy(resp, req, h, path, body)
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/klaital/wannetiot/pkg/alarms"
+	"github.com/klaital/wannetiot/pkg/lights"
+	"github.com/klaital/wannetiot/pkg/loggingresponsewriter"
+	"github.com/klaital/wannetiot/pkg/netpanel"
+)
+
+// apiPrefix is where the versioned API is served.
+const apiPrefix = "/api/v1"
+
+// maxAPIBody limits the size of request bodies.
+const maxAPIBody = 1 << 20
+
+// APIError is the body of every failed API request, as {"error": {...}}.
+type APIError struct {
+	Status  int    `json:"-"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+func apiErrorf(status int, code, format string, args ...interface{}) *APIError {
+	return &APIError{Status: status, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// toAPIError maps the errors returned by the lights, alarms and netpanel
+// packages onto status codes. Anything unrecognized is an internal error.
+func toAPIError(err error) *APIError {
+	var apiErr *APIError
+	switch {
+	case errors.As(err, &apiErr):
+		return apiErr
+	case errors.Is(err, lights.ErrSceneNotFound), errors.Is(err, alarms.ErrNotFound), errors.Is(err, errPageNotFound),
+		errors.Is(err, netpanel.ErrUnknownPanel):
+		return &APIError{Status: http.StatusNotFound, Code: "not_found", Message: err.Error()}
+	case errors.Is(err, lights.ErrDuplicateScene), errors.Is(err, alarms.ErrDuplicateID):
+		return &APIError{Status: http.StatusConflict, Code: "conflict", Message: err.Error()}
+	case errors.Is(err, lights.ErrNoEffect), errors.Is(err, lights.ErrNoWakeup), errors.Is(err, lights.ErrSnoozeLimit):
+		return &APIError{Status: http.StatusConflict, Code: "invalid_state", Message: err.Error()}
+	case errors.Is(err, netpanel.ErrUnknownSession):
+		return &APIError{Status: http.StatusConflict, Code: "unknown_session", Message: err.Error()}
+	case errors.Is(err, lights.ErrInvalidScene), errors.Is(err, lights.ErrInvalidEffect),
+		errors.Is(err, lights.ErrUnknownZone), errors.Is(err, lights.ErrUnknownCurve),
+		errors.Is(err, lights.ErrKelvinOutOfRange), errors.Is(err, lights.ErrInvalidMultiplier),
+		errors.Is(err, alarms.ErrInvalidAlarm), errors.Is(err, netpanel.ErrInvalid):
+		return &APIError{Status: http.StatusBadRequest, Code: "invalid_request", Message: err.Error()}
+	}
+	return &APIError{Status: http.StatusInternalServerError, Code: "internal", Message: err.Error()}
+}
+
+// apiRequest is a request matched to a route, with its path parameters and body.
+type apiRequest struct {
+	*http.Request
+	Params map[string]string
+	Body   []byte
+}
+
+// decode unmarshals the JSON body into v. Unknown fields are rejected, to
+// catch typos. An empty body is allowed unless required is set.
+func (r *apiRequest) decode(v interface{}, required bool) error {
+	if len(r.Body) == 0 {
+		if required {
+			return apiErrorf(http.StatusBadRequest, "invalid_json", "a JSON request body is required")
+		}
+		return nil
+	}
+	dec := json.NewDecoder(strings.NewReader(string(r.Body)))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return apiErrorf(http.StatusBadRequest, "invalid_json", "invalid JSON body: %v", err)
+	}
+	return nil
+}
+
+// apiHandler handles one API route. It returns the status code and the value
+// to send as the JSON body, or an error to send as a structured error.
+type apiHandler func(req *apiRequest) (int, interface{}, error)
+
+// apiStreamHandler handles a route that writes its own response, such as an
+// event stream. It can still fail with an error before it starts writing.
+type apiStreamHandler func(resp http.ResponseWriter, req *apiRequest) error
+
+type apiRoute struct {
+	method   string
+	pattern  string
+	segments []string
+	handler  apiHandler
+	stream   apiStreamHandler
+}
+
+// apiRouter matches requests on their method and path. Path segments written
+// as {name} match any value, and are passed to the handler as parameters.
+// Requests are checked against the spec, if it's set, before the handler runs.
+type apiRouter struct {
+	routes []apiRoute
+	spec   *apiSpec
+	logger *logrus.Logger
+}
+
+func (rt *apiRouter) handle(method, pattern string, h apiHandler) {
+	rt.routes = append(rt.routes, apiRoute{
+		method:   method,
+		pattern:  pattern,
+		segments: splitPath(pattern),
+		handler:  h,
+	})
+}
+
+func (rt *apiRouter) handleStream(method, pattern string, h apiStreamHandler) {
+	rt.routes = append(rt.routes, apiRoute{
+		method:   method,
+		pattern:  pattern,
+		segments: splitPath(pattern),
+		stream:   h,
+	})
+}
+
+// splitPath splits the path into its segments, ignoring leading and trailing slashes.
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// match checks the path against the route's pattern, returning its parameters.
+func (r apiRoute) match(segments []string) (map[string]string, bool) {
+	if len(segments) != len(r.segments) {
+		return nil, false
+	}
+	params := make(map[string]string)
+	for i, s := range r.segments {
+		if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+			params[s[1:len(s)-1]] = segments[i]
+		} else if s != segments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+func (rt *apiRouter) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	segments := splitPath(strings.TrimPrefix(req.URL.Path, apiPrefix))
+
+	var allowed []string
+	for _, route := range rt.routes {
+		params, ok := route.match(segments)
+		if !ok {
+			continue
+		}
+		if route.method != req.Method {
+			allowed = append(allowed, route.method)
+			continue
+		}
+		loggingresponsewriter.SetRoute(req.Context(), apiPrefix+route.pattern)
+
+		body, err := io.ReadAll(http.MaxBytesReader(resp, req.Body, maxAPIBody))
+		if err != nil {
+			rt.writeError(resp, apiErrorf(http.StatusRequestEntityTooLarge, "body_too_large", "request body is too large"))
+			return
+		}
+		apiReq := &apiRequest{Request: req, Params: params, Body: body}
+		if rt.spec != nil {
+			if err = rt.spec.validate(route, apiReq); err != nil {
+				rt.fail(resp, req, err)
+				return
+			}
+		}
+		if route.stream != nil {
+			if err = route.stream(resp, apiReq); err != nil {
+				rt.fail(resp, req, err)
+			}
+			return
+		}
+		status, v, err := route.handler(apiReq)
+		if err != nil {
+			rt.fail(resp, req, err)
+			return
+		}
+		rt.writeJSON(resp, status, v)
+		return
+	}
+
+	if len(allowed) > 0 {
+		resp.Header().Set("Allow", strings.Join(allowed, ", "))
+		rt.writeError(resp, apiErrorf(http.StatusMethodNotAllowed, "method_not_allowed", "%s is not allowed on %s", req.Method, req.URL.Path))
+		return
+	}
+	rt.writeError(resp, apiErrorf(http.StatusNotFound, "not_found", "no such endpoint %s", req.URL.Path))
+}
+
+// rawBody is a handler result that is sent as it is, rather than as JSON.
+type rawBody struct {
+	contentType string
+	body        []byte
+}
+
+func (rt *apiRouter) writeJSON(resp http.ResponseWriter, status int, v interface{}) {
+	if status == http.StatusNoContent || v == nil {
+		resp.WriteHeader(status)
+		return
+	}
+	if raw, ok := v.(rawBody); ok {
+		resp.Header().Set("Content-Type", raw.contentType)
+		resp.WriteHeader(status)
+		if _, err := resp.Write(raw.body); err != nil {
+			rt.logger.WithError(err).Error("Failed to write response body")
+		}
+		return
+	}
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(status)
+	if err := json.NewEncoder(resp).Encode(v); err != nil {
+		rt.logger.WithError(err).Error("Failed to write response body")
+	}
+}
+
+// fail sends the error, logging it if it's the server's fault.
+func (rt *apiRouter) fail(resp http.ResponseWriter, req *http.Request, err error) {
+	apiErr := toAPIError(err)
+	if apiErr.Status >= http.StatusInternalServerError {
+		loggingresponsewriter.Entry(req.Context(), rt.logger).WithError(err).WithField("path", req.URL.Path).Error("API request failed")
+	}
+	rt.writeError(resp, apiErr)
+}
+
+func (rt *apiRouter) writeError(resp http.ResponseWriter, err *APIError) {
+	rt.writeJSON(resp, err.Status, struct {
+		Error *APIError `json:"error"`
+	}{err})
+}
@@ -0,0 +1,489 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/klaital/wannetiot/pkg/alarms"
+	"github.com/klaital/wannetiot/pkg/apiclient"
+	"github.com/klaital/wannetiot/pkg/ctlpanel"
+	"github.com/klaital/wannetiot/pkg/lights"
+)
+
+// LightsUpdate is the body of PUT /api/v1/lights. Give one of Scene, Kelvin
+// or Color. Brightness applies to Kelvin and Color, and defaults to 1.0.
+type LightsUpdate struct {
+	Scene      string                 `json:"scene,omitempty"`
+	Kelvin     float64                `json:"kelvin,omitempty"`
+	Color      *lights.ChannelFactors `json:"color,omitempty"`
+	Brightness *float64               `json:"brightness,omitempty"`
+	// Transition is how long to fade for this change only, such as "2s".
+	Transition string `json:"transition,omitempty"`
+	// Zones lists the zones or groups to change. Empty changes all of them.
+	Zones []string `json:"zones,omitempty"`
+}
+
+// SensorsState reports the latest sensor readings.
+type SensorsState struct {
+	Atmo    *AtmoState         `json:"atmo,omitempty"`
+	Dust    *DustState         `json:"dust,omitempty"`
+	Ambient *AmbientState      `json:"ambient,omitempty"`
+	Power   lights.PowerReport `json:"power"`
+}
+
+// AtmoState is the latest temperature and humidity reading.
+type AtmoState struct {
+	TemperatureF float64   `json:"temperature_f"`
+	Humidity     float64   `json:"humidity"`
+	At           time.Time `json:"at"`
+}
+
+// PanelState describes one of the wired control panels.
+type PanelState struct {
+	ID         int      `json:"id"`
+	Enabled    bool     `json:"enabled"`
+	Dimmer     uint16   `json:"dimmer"` // the dimmer knob's last reading, as a percentage
+	SceneCycle []string `json:"scene_cycle,omitempty"`
+}
+
+// StatusResponse acknowledges a request that has no other state to report.
+type StatusResponse struct {
+	Status string `json:"status"`
+}
+
+// apiRoutes builds the /api/v1 router.
+func (srv *Server) apiRoutes() *apiRouter {
+	spec, err := newAPISpec(apiclient.Spec)
+	if err != nil {
+		srv.Logger.WithError(err).Fatal("Failed to load the API spec")
+	}
+	rt := &apiRouter{spec: spec, logger: srv.Logger}
+
+	rt.handle(http.MethodGet, "/lights", srv.apiGetLights)
+	rt.handle(http.MethodPut, "/lights", srv.apiPutLights)
+	rt.handle(http.MethodGet, "/lights/zones", srv.apiGetZones)
+	rt.handle(http.MethodPost, "/lights/effect", srv.apiPlayEffect)
+	rt.handle(http.MethodDelete, "/lights/effect", srv.apiStopEffect)
+	rt.handle(http.MethodPost, "/lights/sleep", srv.apiStartSleep)
+	rt.handle(http.MethodDelete, "/lights/sleep", srv.apiCancelSleep)
+	rt.handle(http.MethodPost, "/lights/wakeup", srv.apiStartWakeup)
+	rt.handle(http.MethodDelete, "/lights/wakeup", srv.apiDismissWakeup)
+	rt.handle(http.MethodPost, "/lights/wakeup/snooze", srv.apiSnoozeWakeup)
+
+	rt.handle(http.MethodGet, "/scenes", srv.apiListScenes)
+	rt.handle(http.MethodPost, "/scenes", srv.apiCreateScene)
+	rt.handle(http.MethodGet, "/scenes/{id}", srv.apiGetScene)
+	rt.handle(http.MethodPut, "/scenes/{id}", srv.apiUpdateScene)
+	rt.handle(http.MethodDelete, "/scenes/{id}", srv.apiDeleteScene)
+	rt.handle(http.MethodPost, "/scenes/{id}/recall", srv.apiRecallScene)
+
+	rt.handle(http.MethodGet, "/alarms", srv.apiListAlarms)
+	rt.handle(http.MethodPost, "/alarms", srv.apiCreateAlarm)
+	rt.handle(http.MethodGet, "/alarms/{id}", srv.apiGetAlarm)
+	rt.handle(http.MethodPut, "/alarms/{id}", srv.apiUpdateAlarm)
+	rt.handle(http.MethodDelete, "/alarms/{id}", srv.apiDeleteAlarm)
+	rt.handle(http.MethodPost, "/alarms/{id}/skip", srv.apiSkipAlarm(true))
+	rt.handle(http.MethodDelete, "/alarms/{id}/skip", srv.apiSkipAlarm(false))
+
+	rt.handle(http.MethodGet, "/status", srv.apiGetStatus)
+
+	rt.handle(http.MethodGet, "/sensors", srv.apiGetSensors)
+	rt.handle(http.MethodGet, "/sensors/history", srv.apiGetSensorHistory)
+	rt.handle(http.MethodGet, "/panels", srv.apiGetPanels)
+	rt.handle(http.MethodGet, "/pager", srv.apiListPages)
+	rt.handle(http.MethodPost, "/pager", srv.apiPage)
+	rt.handle(http.MethodDelete, "/pager/{id}", srv.apiClosePage)
+
+	rt.handle(http.MethodGet, "/netpanels", srv.apiListNetPanels)
+	rt.handle(http.MethodPost, "/netpanels", srv.apiRegisterNetPanel)
+	rt.handle(http.MethodPost, "/netpanels/{id}/heartbeat", srv.apiNetPanelHeartbeat)
+	rt.handle(http.MethodPost, "/netpanels/{id}/events", srv.apiNetPanelEvent)
+	rt.handle(http.MethodGet, "/netpanels/{id}/commands", srv.apiPollNetPanel)
+
+	rt.handleStream(http.MethodGet, "/events", srv.serveEvents)
+	rt.handle(http.MethodPost, "/events", srv.apiPublishEvent)
+
+	rt.handle(http.MethodGet, "/openapi.yaml", func(req *apiRequest) (int, interface{}, error) {
+		return http.StatusOK, rawBody{contentType: "application/yaml", body: apiclient.Spec}, nil
+	})
+
+	return rt
+}
+
+// requestZones reads the zones a request is addressed to, from the body if
+// it lists any, or else from ?zone=, and checks that they exist.
+func requestZones(req *apiRequest, body []string) ([]string, error) {
+	zones := body
+	if len(zones) == 0 {
+		zones = zoneParam(req.Request)
+	}
+	if _, err := lights.SelectZones(zones...); err != nil {
+		return nil, err
+	}
+	return zones, nil
+}
+
+// parseDuration reads an optional duration field from a request body.
+func parseDuration(field, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, apiErrorf(http.StatusBadRequest, "invalid_request", "%s must be a duration such as 30m", field)
+	}
+	return d, nil
+}
+
+func (srv *Server) apiGetLights(req *apiRequest) (int, interface{}, error) {
+	return http.StatusOK, currentLightsState(), nil
+}
+
+func (srv *Server) apiPutLights(req *apiRequest) (int, interface{}, error) {
+	var update LightsUpdate
+	if err := req.decode(&update, true); err != nil {
+		return 0, nil, err
+	}
+	zones, err := requestZones(req, update.Zones)
+	if err != nil {
+		return 0, nil, err
+	}
+	var transition *time.Duration
+	if update.Transition != "" {
+		d, err := parseDuration("transition", update.Transition)
+		if err != nil {
+			return 0, nil, err
+		}
+		transition = &d
+	}
+	brightness := 1.0
+	if update.Brightness != nil {
+		brightness = *update.Brightness
+	}
+
+	var settings lights.LightConfig
+	switch {
+	case update.Scene != "" && update.Kelvin == 0 && update.Color == nil:
+		if settings, err = srv.scenes.SceneSettings(update.Scene); err != nil {
+			return 0, nil, err
+		}
+	case update.Scene == "" && update.Kelvin > 0 && update.Color == nil:
+		if brightness < 0 || brightness > 1 {
+			return 0, nil, apiErrorf(http.StatusBadRequest, "invalid_request", "brightness must be in the range 0.0 - 1.0")
+		}
+		if settings, err = lights.ColorTemperature(update.Kelvin, brightness); err != nil {
+			return 0, nil, err
+		}
+	case update.Scene == "" && update.Kelvin == 0 && update.Color != nil:
+		// Keep the colour as the "custom" scene, as the panel dimmer and /lights/configure do
+		custom, err := srv.scenes.Scene("custom")
+		if err != nil {
+			return 0, nil, err
+		}
+		custom.Kelvin = 0
+		custom.Color = update.Color
+		custom.Brightness = brightness
+		if custom, err = srv.scenes.UpdateScene("custom", custom); err != nil {
+			return 0, nil, err
+		}
+		if settings, err = custom.Settings(); err != nil {
+			return 0, nil, err
+		}
+	default:
+		return 0, nil, apiErrorf(http.StatusBadRequest, "invalid_request", "give exactly one of scene, kelvin or color")
+	}
+
+	lights.HaltWakeup()
+	lights.CancelSleep()
+	if err = setZoneLights(srv.app, zones, settings, transition); err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, currentLightsState(), nil
+}
+
+func (srv *Server) apiGetZones(req *apiRequest) (int, interface{}, error) {
+	return http.StatusOK, zoneStates(), nil
+}
+
+func (srv *Server) apiPlayEffect(req *apiRequest) (int, interface{}, error) {
+	var effect lights.Effect
+	if err := req.decode(&effect, true); err != nil {
+		return 0, nil, err
+	}
+	zones, err := requestZones(req, effect.Zones)
+	if err != nil {
+		return 0, nil, err
+	}
+	effect.Zones = zones
+	lights.HaltWakeup()
+	if _, err = lights.PlayEffect(srv.app, effect); err != nil {
+		return 0, nil, err
+	}
+	return http.StatusAccepted, currentLightsState(), nil
+}
+
+func (srv *Server) apiStopEffect(req *apiRequest) (int, interface{}, error) {
+	if err := lights.StopEffect(srv.app); err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, currentLightsState(), nil
+}
+
+// APISleepRequest is the body of POST /api/v1/lights/sleep.
+type APISleepRequest struct {
+	SleepRequest
+	Zones []string `json:"zones,omitempty"`
+}
+
+func (srv *Server) apiStartSleep(req *apiRequest) (int, interface{}, error) {
+	var sleepReq APISleepRequest
+	if err := req.decode(&sleepReq, false); err != nil {
+		return 0, nil, err
+	}
+	zones, err := requestZones(req, sleepReq.Zones)
+	if err != nil {
+		return 0, nil, err
+	}
+	d, err := parseDuration("duration", sleepReq.Duration)
+	if err != nil {
+		return 0, nil, err
+	}
+	lights.HaltWakeup()
+	lights.DoSleepWith(lights.SleepOptions{Duration: d, Warm: sleepReq.Warm, Zones: zones})
+	if len(zones) == 0 {
+		globalState.LightState = lights.Off
+		saveLightState(srv.app)
+	}
+	return http.StatusAccepted, currentLightsState(), nil
+}
+
+func (srv *Server) apiCancelSleep(req *apiRequest) (int, interface{}, error) {
+	lights.CancelSleep()
+	return http.StatusOK, currentLightsState(), nil
+}
+
+// APIWakeupRequest is the body of POST /api/v1/lights/wakeup.
+type APIWakeupRequest struct {
+	WakeupRequest
+	Scene string   `json:"scene,omitempty"`
+	Zones []string `json:"zones,omitempty"`
+}
+
+func (srv *Server) apiStartWakeup(req *apiRequest) (int, interface{}, error) {
+	var wakeupReq APIWakeupRequest
+	if err := req.decode(&wakeupReq, false); err != nil {
+		return 0, nil, err
+	}
+	zones, err := requestZones(req, wakeupReq.Zones)
+	if err != nil {
+		return 0, nil, err
+	}
+	if wakeupReq.Curve != "" {
+		if _, err = lights.LookupCurve(wakeupReq.Curve); err != nil {
+			return 0, nil, err
+		}
+	}
+	d, err := parseDuration("duration", wakeupReq.Duration)
+	if err != nil {
+		return 0, nil, err
+	}
+	opts := lights.WakeupOptions{Curve: wakeupReq.Curve, Duration: d, Zones: zones}
+	if wakeupReq.Scene != "" {
+		target, err := srv.scenes.SceneSettings(wakeupReq.Scene)
+		if err != nil {
+			return 0, nil, err
+		}
+		opts.Target = &target
+	}
+	lights.DoWakeupWith(opts)
+	return http.StatusAccepted, currentLightsState(), nil
+}
+
+func (srv *Server) apiDismissWakeup(req *apiRequest) (int, interface{}, error) {
+	lights.HaltWakeup()
+	return http.StatusOK, currentLightsState(), nil
+}
+
+func (srv *Server) apiSnoozeWakeup(req *apiRequest) (int, interface{}, error) {
+	if err := lights.SnoozeWakeup(srv.app.WakeupSnoozeDuration); err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, currentLightsState(), nil
+}
+
+func (srv *Server) apiListScenes(req *apiRequest) (int, interface{}, error) {
+	return http.StatusOK, srv.scenes.Scenes(), nil
+}
+
+func (srv *Server) apiCreateScene(req *apiRequest) (int, interface{}, error) {
+	var s lights.Scene
+	if err := req.decode(&s, true); err != nil {
+		return 0, nil, err
+	}
+	created, err := srv.scenes.CreateScene(s)
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusCreated, created, nil
+}
+
+func (srv *Server) apiGetScene(req *apiRequest) (int, interface{}, error) {
+	s, err := srv.scenes.Scene(req.Params["id"])
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, s, nil
+}
+
+func (srv *Server) apiUpdateScene(req *apiRequest) (int, interface{}, error) {
+	var s lights.Scene
+	if err := req.decode(&s, true); err != nil {
+		return 0, nil, err
+	}
+	updated, err := srv.scenes.UpdateScene(req.Params["id"], s)
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, updated, nil
+}
+
+func (srv *Server) apiDeleteScene(req *apiRequest) (int, interface{}, error) {
+	if err := srv.scenes.DeleteScene(req.Params["id"]); err != nil {
+		return 0, nil, err
+	}
+	return http.StatusNoContent, nil, nil
+}
+
+func (srv *Server) apiRecallScene(req *apiRequest) (int, interface{}, error) {
+	zones, err := requestZones(req, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	lights.HaltWakeup()
+	if err = recallScene(srv.app, req.Params["id"], zones...); err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, currentLightsState(), nil
+}
+
+// alarmsConfigured fails the request if the node has no alarm scheduler.
+func (srv *Server) alarmsConfigured() error {
+	if srv.alarms == nil {
+		return apiErrorf(http.StatusServiceUnavailable, "unavailable", "alarms are not configured")
+	}
+	return nil
+}
+
+func (srv *Server) apiListAlarms(req *apiRequest) (int, interface{}, error) {
+	if err := srv.alarmsConfigured(); err != nil {
+		return 0, nil, err
+	}
+	list := make([]AlarmResponse, 0)
+	for _, a := range srv.alarms.List() {
+		list = append(list, srv.alarmResponse(a))
+	}
+	return http.StatusOK, list, nil
+}
+
+// decodeAlarm reads and validates the alarm in the request body.
+func (srv *Server) decodeAlarm(req *apiRequest) (alarms.Alarm, error) {
+	var a alarms.Alarm
+	if err := req.decode(&a, true); err != nil {
+		return a, err
+	}
+	return a, srv.validateAlarm(a)
+}
+
+func (srv *Server) apiCreateAlarm(req *apiRequest) (int, interface{}, error) {
+	if err := srv.alarmsConfigured(); err != nil {
+		return 0, nil, err
+	}
+	a, err := srv.decodeAlarm(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	created, err := srv.alarms.Create(a)
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusCreated, srv.alarmResponse(created), nil
+}
+
+func (srv *Server) apiGetAlarm(req *apiRequest) (int, interface{}, error) {
+	if err := srv.alarmsConfigured(); err != nil {
+		return 0, nil, err
+	}
+	a, err := srv.alarms.Get(req.Params["id"])
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, srv.alarmResponse(a), nil
+}
+
+func (srv *Server) apiUpdateAlarm(req *apiRequest) (int, interface{}, error) {
+	if err := srv.alarmsConfigured(); err != nil {
+		return 0, nil, err
+	}
+	a, err := srv.decodeAlarm(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	updated, err := srv.alarms.Update(req.Params["id"], a)
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, srv.alarmResponse(updated), nil
+}
+
+func (srv *Server) apiDeleteAlarm(req *apiRequest) (int, interface{}, error) {
+	if err := srv.alarmsConfigured(); err != nil {
+		return 0, nil, err
+	}
+	if err := srv.alarms.Delete(req.Params["id"]); err != nil {
+		return 0, nil, err
+	}
+	return http.StatusNoContent, nil, nil
+}
+
+// apiSkipAlarm skips, or un-skips, the alarm's next occurrence.
+func (srv *Server) apiSkipAlarm(skip bool) apiHandler {
+	return func(req *apiRequest) (int, interface{}, error) {
+		if err := srv.alarmsConfigured(); err != nil {
+			return 0, nil, err
+		}
+		a, err := srv.alarms.SkipNext(req.Params["id"], skip)
+		if err != nil {
+			return 0, nil, err
+		}
+		return http.StatusOK, srv.alarmResponse(a), nil
+	}
+}
+
+func (srv *Server) apiGetSensors(req *apiRequest) (int, interface{}, error) {
+	return http.StatusOK, currentSensors(), nil
+}
+
+func (srv *Server) apiGetPanels(req *apiRequest) (int, interface{}, error) {
+	panel := func(id int, enabled bool, p *ctlpanel.ControlPanel) PanelState {
+		state := PanelState{ID: id, Enabled: enabled}
+		if enabled {
+			state.Dimmer, state.SceneCycle = p.State()
+		}
+		return state
+	}
+	return http.StatusOK, []PanelState{
+		panel(1, srv.app.Panel1Enabled, &globalState.ControlPanel1),
+		panel(2, srv.app.Panel2Enabled, &globalState.ControlPanel2),
+	}, nil
+}
+
+// apiPage plays the pager effect, and lights up the panels to show the page was received.
+// A repeat within the cooldown is only counted on the last page.
+func (srv *Server) apiPage(req *apiRequest) (int, interface{}, error) {
+	_, repeat := sendPage(srv.app, "received", "api")
+	acknowledgePager(srv.app, "api", repeat)
+	if repeat {
+		return http.StatusAccepted, StatusResponse{Status: "already_paged"}, nil
+	}
+	return http.StatusAccepted, StatusResponse{Status: "paged"}, nil
+}
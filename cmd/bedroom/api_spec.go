@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+)
+
+// apiSpec checks requests against the OpenAPI spec before they reach the
+// handlers, so that the API can't accept anything the spec doesn't describe.
+type apiSpec struct {
+	doc     *openapi3.T
+	options *openapi3filter.Options
+}
+
+// newAPISpec loads and checks the spec, as YAML or JSON.
+func newAPISpec(spec []byte) (*apiSpec, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(spec)
+	if err != nil {
+		return nil, fmt.Errorf("loading the API spec: %w", err)
+	}
+	if err = doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("invalid API spec: %w", err)
+	}
+	return &apiSpec{
+		doc: doc,
+		options: &openapi3filter.Options{
+			// Tokens are checked before requests reach the router
+			AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+		},
+	}, nil
+}
+
+// route finds the spec's operation for the method and path, which is written
+// with {name} parameters as it is in the spec. It is nil if there's none.
+func (s *apiSpec) route(method, path string) *routers.Route {
+	item := s.doc.Paths.Find(path)
+	if item == nil {
+		return nil
+	}
+	op := item.GetOperation(method)
+	if op == nil {
+		return nil
+	}
+	return &routers.Route{Spec: s.doc, Path: path, PathItem: item, Method: method, Operation: op}
+}
+
+// validationInput describes a request matched to an API route, for checking
+// against the spec.
+func (s *apiSpec) validationInput(route apiRoute, req *apiRequest) *openapi3filter.RequestValidationInput {
+	op := s.route(route.method, apiPrefix+route.pattern)
+	if op == nil {
+		return nil
+	}
+	r := req.Request.Clone(req.Context())
+	r.Body = io.NopCloser(bytes.NewReader(req.Body))
+	if len(req.Body) > 0 {
+		// The API only takes JSON, whatever the client says it sent
+		r.Header.Set("Content-Type", "application/json")
+	}
+	return &openapi3filter.RequestValidationInput{
+		Request:    r,
+		PathParams: req.Params,
+		Route:      op,
+		Options:    s.options,
+	}
+}
+
+// validate checks the request's parameters and body against the spec.
+// Bodies that aren't JSON at all are left to the handler to report. Routes
+// missing from the spec aren't checked, which the tests catch.
+func (s *apiSpec) validate(route apiRoute, req *apiRequest) error {
+	input := s.validationInput(route, req)
+	if input == nil {
+		return nil
+	}
+	if len(req.Body) == 0 || !json.Valid(req.Body) {
+		options := *s.options
+		options.ExcludeRequestBody = true
+		input.Options = &options
+	}
+	if err := openapi3filter.ValidateRequest(req.Context(), input); err != nil {
+		return apiErrorf(http.StatusBadRequest, "invalid_request", "%s", specErrorMessage(err))
+	}
+	return nil
+}
+
+// specErrorMessage describes a validation failure briefly, without the
+// schema that the validator's own messages include.
+func specErrorMessage(err error) string {
+	var field string
+	var reqErr *openapi3filter.RequestError
+	if errors.As(err, &reqErr) && reqErr.Parameter != nil {
+		field = reqErr.Parameter.Name
+	}
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) {
+		if path := schemaErr.JSONPointer(); len(path) > 0 {
+			field = strings.Join(path, ".")
+		}
+		if field != "" {
+			return fmt.Sprintf("%s: %s", field, schemaErr.Reason)
+		}
+		return schemaErr.Reason
+	}
+	if reqErr != nil {
+		if field != "" {
+			return fmt.Sprintf("%s: %s", field, reqErr.Reason)
+		}
+		if reqErr.Reason != "" {
+			return reqErr.Reason
+		}
+	}
+	return err.Error()
+}
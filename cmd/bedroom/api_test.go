@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/sirupsen/logrus"
+
+	"github.com/klaital/wannetiot/pkg/alarms"
+	"github.com/klaital/wannetiot/pkg/config"
+	"github.com/klaital/wannetiot/pkg/lights"
+)
+
+// newTestServer creates a node with no hardware, whose scenes and alarms are
+// kept in a temporary directory. It has one alarm, "wake". The scenes are
+// also the global scenes until the test ends, as main sets them.
+func newTestServer(t *testing.T) *Server {
+	dir := t.TempDir()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	scenes, err := lights.OpenSceneStore(filepath.Join(dir, "lights.json"))
+	if err != nil {
+		t.Fatalf("opening the scenes: %v", err)
+	}
+	saved := globalState.Scenes
+	globalState.Scenes = scenes
+	t.Cleanup(func() { globalState.Scenes = saved })
+	scheduler, err := alarms.New(filepath.Join(dir, "alarms.json"), 30*time.Minute, time.UTC, func(alarms.Alarm, time.Time) {}, logrus.NewEntry(logger))
+	if err != nil {
+		t.Fatalf("opening the alarms: %v", err)
+	}
+	if _, err = scheduler.Create(alarms.Alarm{ID: "wake", Name: "Wake", Time: "07:00", Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	return &Server{Logger: logger, app: &config.Config{}, scenes: scenes, alarms: scheduler}
+}
+
+func TestAPIRouter(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		// noAlarms runs the request on a node without an alarm scheduler
+		noAlarms bool
+
+		wantStatus int
+		wantCode   string // the error code, for failures
+		wantAllow  string
+	}{
+		// Route matching
+		{name: "lights", method: http.MethodGet, path: "/lights", wantStatus: http.StatusOK},
+		{name: "trailing slash", method: http.MethodGet, path: "/lights/", wantStatus: http.StatusOK},
+		{name: "path parameter", method: http.MethodGet, path: "/scenes/low", wantStatus: http.StatusOK},
+		{name: "spec", method: http.MethodGet, path: "/openapi.yaml", wantStatus: http.StatusOK},
+
+		// Unknown paths and methods
+		{name: "unknown path", method: http.MethodGet, path: "/nothing", wantStatus: http.StatusNotFound, wantCode: "not_found"},
+		{name: "extra segment", method: http.MethodGet, path: "/scenes/low/extra", wantStatus: http.StatusNotFound, wantCode: "not_found"},
+		{name: "api root", method: http.MethodGet, path: "", wantStatus: http.StatusNotFound, wantCode: "not_found"},
+		{name: "method not allowed", method: http.MethodPatch, path: "/lights", wantStatus: http.StatusMethodNotAllowed, wantCode: "method_not_allowed", wantAllow: "GET, PUT"},
+		{name: "method not allowed with parameter", method: http.MethodPost, path: "/alarms/wake", wantStatus: http.StatusMethodNotAllowed, wantCode: "method_not_allowed", wantAllow: "GET, PUT, DELETE"},
+
+		// Invalid bodies
+		{name: "malformed JSON", method: http.MethodPut, path: "/lights", body: `{"kelvin":`, wantStatus: http.StatusBadRequest, wantCode: "invalid_json"},
+		{name: "unknown field", method: http.MethodPut, path: "/lights", body: `{"kelvn": 2700}`, wantStatus: http.StatusBadRequest, wantCode: "invalid_json"},
+		{name: "missing body", method: http.MethodPut, path: "/lights", wantStatus: http.StatusBadRequest, wantCode: "invalid_json"},
+		{name: "scene body of the wrong type", method: http.MethodPost, path: "/scenes", body: `[]`, wantStatus: http.StatusBadRequest, wantCode: "invalid_request"},
+		{name: "alarm body of the wrong type", method: http.MethodPut, path: "/alarms/wake", body: `{"time": 700}`, wantStatus: http.StatusBadRequest, wantCode: "invalid_request"},
+
+		// Lights
+		{name: "scene and kelvin", method: http.MethodPut, path: "/lights", body: `{"scene": "low", "kelvin": 2700}`, wantStatus: http.StatusBadRequest, wantCode: "invalid_request"},
+		{name: "unknown scene", method: http.MethodPut, path: "/lights", body: `{"scene": "missing"}`, wantStatus: http.StatusNotFound, wantCode: "not_found"},
+		{name: "unknown zone", method: http.MethodPut, path: "/lights", body: `{"kelvin": 2700, "zones": ["attic"]}`, wantStatus: http.StatusBadRequest, wantCode: "invalid_request"},
+		{name: "unknown zone parameter", method: http.MethodPut, path: "/lights?zone=attic", body: `{"kelvin": 2700}`, wantStatus: http.StatusBadRequest, wantCode: "invalid_request"},
+		{name: "invalid transition", method: http.MethodPut, path: "/lights", body: `{"kelvin": 2700, "transition": "soon"}`, wantStatus: http.StatusBadRequest, wantCode: "invalid_request"},
+		{name: "brightness out of range", method: http.MethodPut, path: "/lights", body: `{"kelvin": 2700, "brightness": 2}`, wantStatus: http.StatusBadRequest, wantCode: "invalid_request"},
+		{name: "no effect to stop", method: http.MethodDelete, path: "/lights/effect", wantStatus: http.StatusConflict, wantCode: "invalid_state"},
+		{name: "no wakeup to snooze", method: http.MethodPost, path: "/lights/wakeup/snooze", wantStatus: http.StatusConflict, wantCode: "invalid_state"},
+		{name: "unknown wakeup curve", method: http.MethodPost, path: "/lights/wakeup", body: `{"curve": "moonrise"}`, wantStatus: http.StatusBadRequest, wantCode: "invalid_request"},
+
+		// Scenes
+		{name: "list scenes", method: http.MethodGet, path: "/scenes", wantStatus: http.StatusOK},
+		{name: "create scene", method: http.MethodPost, path: "/scenes", body: `{"id": "dusk", "kelvin": 2200, "brightness": 0.4}`, wantStatus: http.StatusCreated},
+		{name: "missing scene", method: http.MethodGet, path: "/scenes/missing", wantStatus: http.StatusNotFound, wantCode: "not_found"},
+		{name: "invalid scene", method: http.MethodPost, path: "/scenes", body: `{"id": "dusk", "kelvin": 2200, "brightness": 4}`, wantStatus: http.StatusBadRequest, wantCode: "invalid_request"},
+		{name: "invalid scene ID", method: http.MethodPost, path: "/scenes", body: `{"id": "Not A Scene!", "kelvin": 2200}`, wantStatus: http.StatusBadRequest, wantCode: "invalid_request"},
+		{name: "duplicate scene", method: http.MethodPost, path: "/scenes", body: `{"id": "low", "kelvin": 2200, "brightness": 0.4}`, wantStatus: http.StatusConflict, wantCode: "conflict"},
+		{name: "update missing scene", method: http.MethodPut, path: "/scenes/missing", body: `{"kelvin": 2200, "brightness": 0.4}`, wantStatus: http.StatusNotFound, wantCode: "not_found"},
+		{name: "delete missing scene", method: http.MethodDelete, path: "/scenes/missing", wantStatus: http.StatusNotFound, wantCode: "not_found"},
+		{name: "recall missing scene", method: http.MethodPost, path: "/scenes/missing/recall", wantStatus: http.StatusNotFound, wantCode: "not_found"},
+		{name: "recall in unknown zone", method: http.MethodPost, path: "/scenes/low/recall?zone=attic", wantStatus: http.StatusBadRequest, wantCode: "invalid_request"},
+
+		// Alarms
+		{name: "list alarms", method: http.MethodGet, path: "/alarms", wantStatus: http.StatusOK},
+		{name: "get alarm", method: http.MethodGet, path: "/alarms/wake", wantStatus: http.StatusOK},
+		{name: "create alarm", method: http.MethodPost, path: "/alarms", body: `{"name": "Weekdays", "time": "06:30", "days": ["mon", "tue"]}`, wantStatus: http.StatusCreated},
+		{name: "skip alarm", method: http.MethodPost, path: "/alarms/wake/skip", wantStatus: http.StatusOK},
+		{name: "delete alarm", method: http.MethodDelete, path: "/alarms/wake", wantStatus: http.StatusNoContent},
+		{name: "missing alarm", method: http.MethodGet, path: "/alarms/missing", wantStatus: http.StatusNotFound, wantCode: "not_found"},
+		{name: "skip missing alarm", method: http.MethodDelete, path: "/alarms/missing/skip", wantStatus: http.StatusNotFound, wantCode: "not_found"},
+		{name: "duplicate alarm", method: http.MethodPost, path: "/alarms", body: `{"id": "wake", "name": "Wake", "time": "07:00"}`, wantStatus: http.StatusConflict, wantCode: "conflict"},
+		{name: "alarm without a time", method: http.MethodPost, path: "/alarms", body: `{"name": "Late"}`, wantStatus: http.StatusBadRequest, wantCode: "invalid_request"},
+		{name: "invalid alarm time", method: http.MethodPost, path: "/alarms", body: `{"name": "Late", "time": "7am"}`, wantStatus: http.StatusBadRequest, wantCode: "invalid_request"},
+		{name: "alarm name without an ID", method: http.MethodPost, path: "/alarms", body: `{"name": "!!", "time": "07:00"}`, wantStatus: http.StatusBadRequest, wantCode: "invalid_request"},
+		{name: "alarm with unknown scene", method: http.MethodPost, path: "/alarms", body: `{"name": "Late", "time": "09:00", "scene": "missing"}`, wantStatus: http.StatusBadRequest, wantCode: "invalid_request"},
+		{name: "alarm with unknown curve", method: http.MethodPut, path: "/alarms/wake", body: `{"name": "Wake", "time": "07:00", "curve": "moonrise"}`, wantStatus: http.StatusBadRequest, wantCode: "invalid_request"},
+		{name: "alarms not configured", method: http.MethodGet, path: "/alarms", noAlarms: true, wantStatus: http.StatusServiceUnavailable, wantCode: "unavailable"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTestServer(t)
+			if tt.noAlarms {
+				srv.alarms = nil
+			}
+			req := httptest.NewRequest(tt.method, apiPrefix+tt.path, strings.NewReader(tt.body))
+			resp := httptest.NewRecorder()
+			rt := srv.apiRoutes()
+			rt.ServeHTTP(resp, req)
+
+			if resp.Code != tt.wantStatus {
+				t.Fatalf("got status %d, want %d: %s", resp.Code, tt.wantStatus, resp.Body)
+			}
+			if allow := resp.Header().Get("Allow"); allow != tt.wantAllow {
+				t.Errorf("got Allow %q, want %q", allow, tt.wantAllow)
+			}
+			checkSpecResponse(t, rt, req, tt.body, resp)
+			if tt.wantCode == "" {
+				return
+			}
+			if ct := resp.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("got Content-Type %q, want application/json", ct)
+			}
+			var body struct {
+				Error *APIError `json:"error"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Error == nil {
+				t.Fatalf("the failure isn't an API error: %v", err)
+			}
+			if body.Error.Code != tt.wantCode {
+				t.Errorf("got error code %q, want %q", body.Error.Code, tt.wantCode)
+			}
+			if body.Error.Message == "" {
+				t.Errorf("the error has no message")
+			}
+		})
+	}
+}
+
+// checkSpecResponse checks the response against the spec for the route the
+// request matched, if any.
+func checkSpecResponse(t *testing.T, rt *apiRouter, req *http.Request, body string, resp *httptest.ResponseRecorder) {
+	t.Helper()
+	segments := splitPath(strings.TrimPrefix(req.URL.Path, apiPrefix))
+	for _, route := range rt.routes {
+		params, ok := route.match(segments)
+		if !ok || route.method != req.Method {
+			continue
+		}
+		input := rt.spec.validationInput(route, &apiRequest{Request: req, Params: params, Body: []byte(body)})
+		if input == nil {
+			t.Fatalf("%s %s isn't in the spec", route.method, route.pattern)
+		}
+		respInput := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: input,
+			Status:                 resp.Code,
+			Header:                 resp.Header(),
+			Options:                &openapi3filter.Options{IncludeResponseStatus: true},
+		}
+		if err := openapi3filter.ValidateResponse(req.Context(), respInput.SetBodyBytes(resp.Body.Bytes())); err != nil {
+			t.Errorf("the response doesn't match the spec: %s", specErrorMessage(err))
+		}
+		return
+	}
+}
+
+func TestAPIRoutesMatchTheSpec(t *testing.T) {
+	rt := newTestServer(t).apiRoutes()
+	served := make(map[string]bool)
+	for _, route := range rt.routes {
+		path := apiPrefix + route.pattern
+		served[route.method+" "+path] = true
+		if rt.spec.route(route.method, path) == nil {
+			t.Errorf("%s %s is served, but isn't in the spec", route.method, path)
+		}
+	}
+	for path, item := range rt.spec.doc.Paths {
+		if !strings.HasPrefix(path, apiPrefix+"/") {
+			continue
+		}
+		for method := range item.Operations() {
+			if !served[method+" "+path] {
+				t.Errorf("%s %s is in the spec, but isn't served", method, path)
+			}
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/klaital/wannetiot/pkg/apiauth"
+	"github.com/klaital/wannetiot/pkg/config"
+)
+
+// newAuthenticator sets up API authentication from the config. Denied
+// requests are written to the audit log file as JSON, when one is set.
+func newAuthenticator(cfg *config.Config) (*apiauth.Authenticator, error) {
+	auth := &apiauth.Authenticator{
+		Required: requiredScope,
+		Audit:    cfg.Logger,
+	}
+	for _, spec := range cfg.APITokens {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+		token, err := apiauth.ParseToken(spec)
+		if err != nil {
+			return nil, err
+		}
+		auth.Tokens = append(auth.Tokens, token)
+	}
+	if cfg.APIClientCA != "" {
+		scope, err := apiauth.ParseScope(cfg.APIClientCertScope)
+		if err != nil {
+			return nil, fmt.Errorf("API_CLIENT_CERT_SCOPE: %w", err)
+		}
+		auth.ClientCertScope = scope
+	}
+	if cfg.APIAuditLog != "" {
+		f, err := os.OpenFile(cfg.APIAuditLog, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return nil, err
+		}
+		auth.Audit = logrus.New()
+		auth.Audit.SetOutput(f)
+		auth.Audit.SetFormatter(&logrus.JSONFormatter{})
+	}
+	return auth, nil
+}
+
+// requiredScope decides what a request needs to be allowed. Reading state
+// needs the read scope, and anything that changes it needs control. The
+// legacy light commands and pager change things whatever the method.
+func requiredScope(req *http.Request) apiauth.Scope {
+	// The dashboard's files are public, and it asks for a token to call the API
+	// with. The probes are public too, for monitoring that has no token.
+	if req.Method == http.MethodGet {
+		switch {
+		case req.URL.Path == "/", req.URL.Path == "/healthz", req.URL.Path == "/readyz",
+			strings.HasPrefix(req.URL.Path, dashboardPrefix):
+			return apiauth.ScopePublic
+		}
+	}
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return apiauth.ScopeControl
+	}
+	if strings.HasPrefix(req.URL.Path, "/lights/") && req.URL.Path != "/lights/state" {
+		return apiauth.ScopeControl
+	}
+	if req.URL.Path == "/pager" || strings.HasPrefix(req.URL.Path, "/pager/") {
+		return apiauth.ScopeControl
+	}
+	return apiauth.ScopeRead
+}
+
+// apiTLSConfig asks clients for a certificate, when a client CA is
+// configured. Clients without one can still use a bearer token.
+func apiTLSConfig(cfg *config.Config) (*tls.Config, error) {
+	if cfg.APIClientCA == "" {
+		return nil, nil
+	}
+	pem, err := os.ReadFile(cfg.APIClientCA)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", cfg.APIClientCA)
+	}
+	return &tls.Config{
+		ClientCAs:  pool,
+		ClientAuth: tls.VerifyClientCertIfGiven,
+		MinVersion: tls.VersionTLS12,
+	}, nil
+}
+
+// pagerCallbackAllowed checks that a page's acknowledgement goes back to the
+// host that sent it, or to one of the configured hosts, so that the pager
+// can't be used to make requests to anywhere else.
+func pagerCallbackAllowed(cfg *config.Config, req *http.Request, host string) bool {
+	if remote, _, err := net.SplitHostPort(req.RemoteAddr); err == nil && remote == host {
+		return true
+	}
+	for _, allowed := range cfg.PagerCallbackHosts {
+		if strings.EqualFold(strings.TrimSpace(allowed), host) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/klaital/wannetiot/pkg/apiauth"
+)
+
+func TestRequiredScope(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		want   apiauth.Scope
+	}{
+		{http.MethodGet, "/healthz", apiauth.ScopePublic},
+		{http.MethodGet, dashboardPrefix + "index.html", apiauth.ScopePublic},
+		{http.MethodGet, apiPrefix + "/lights", apiauth.ScopeRead},
+		{http.MethodHead, apiPrefix + "/status", apiauth.ScopeRead},
+		{http.MethodPut, apiPrefix + "/lights", apiauth.ScopeControl},
+		{http.MethodGet, "/lights/state", apiauth.ScopeRead},
+		{http.MethodGet, "/lights/on", apiauth.ScopeControl},
+		{http.MethodGet, "/zones", apiauth.ScopeRead},
+		// The legacy pager sends pages whatever the method
+		{http.MethodGet, "/pager", apiauth.ScopeControl},
+		{http.MethodHead, "/pager/ack", apiauth.ScopeControl},
+		{http.MethodPost, "/pager", apiauth.ScopeControl},
+		{http.MethodGet, apiPrefix + "/pager", apiauth.ScopeRead},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if got := requiredScope(req); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// dashboardPrefix is where the dashboard is served.
+const dashboardPrefix = "/dashboard/"
+
+// dashboardFiles is the dashboard's single page, built into the binary so
+// that the node has nothing to install alongside it. It drives the node
+// with /api/v1, and follows /api/v1/events to stay up to date.
+//
+//go:embed dashboard
+var dashboardFiles embed.FS
+
+func dashboardHandler() http.Handler {
+	files, err := fs.Sub(dashboardFiles, "dashboard")
+	if err != nil {
+		panic(err)
+	}
+	return http.StripPrefix(dashboardPrefix, http.FileServer(http.FS(files)))
+}
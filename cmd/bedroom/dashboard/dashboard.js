@@ -0,0 +1,446 @@
+// The bedroom node's dashboard. It drives the node with /api/v1, and follows
+// /api/v1/events to stay up to date. When the node needs a token, it is kept
+// in localStorage and sent with every request.
+"use strict";
+
+const API = "/api/v1";
+const DUTY_MAX = 1 << 24;
+const READINGS = ["temperature_f", "humidity", "pm25", "pm10"];
+const UNITS = { temperature_f: "°F", humidity: "%", pm25: " µg/m³", pm10: " µg/m³" };
+
+const $ = (sel, root = document) => root.querySelector(sel);
+
+const state = {
+  lights: null,
+  scenes: [],
+  history: [],
+  lastEventID: "",
+};
+
+class APIError extends Error {
+  constructor(status, message) {
+    super(message);
+    this.status = status;
+  }
+}
+
+async function api(method, path, body) {
+  const headers = {};
+  const token = localStorage.getItem("token");
+  if (token) {
+    headers["Authorization"] = "Bearer " + token;
+  }
+  if (body !== undefined) {
+    headers["Content-Type"] = "application/json";
+  }
+  const resp = await fetch(API + path, {
+    method,
+    headers,
+    body: body === undefined ? undefined : JSON.stringify(body),
+  });
+  if (resp.status === 401 || resp.status === 403) {
+    $("#login").hidden = false;
+  }
+  if (!resp.ok) {
+    let message = resp.statusText;
+    try {
+      message = (await resp.json()).error.message;
+    } catch (e) {
+      // not a JSON error
+    }
+    throw new APIError(resp.status, message);
+  }
+  if (resp.status === 204) {
+    return null;
+  }
+  return resp.json();
+}
+
+// run calls the API for a button, and reports any failure.
+async function run(fn) {
+  try {
+    await fn();
+  } catch (e) {
+    if (!(e instanceof APIError && (e.status === 401 || e.status === 403))) {
+      alert(e.message);
+    }
+  }
+}
+
+// Lights
+
+function outputLevel(output) {
+  return Math.max(output.R, output.G, output.W, output.B) / DUTY_MAX;
+}
+
+function renderLights(lights) {
+  state.lights = lights;
+  const level = outputLevel(lights.output);
+  let summary = lights.setting || "custom";
+  if (lights.output.Kelvin) {
+    summary += ` · ${Math.round(lights.output.Kelvin)}K`;
+  }
+  summary += ` · ${Math.round(level * 100)}%`;
+  if (lights.effect) {
+    summary += ` · ${lights.effect}`;
+  }
+  if (lights.sleep) {
+    summary += ` · sleep in ${lights.sleep.remaining}`;
+  }
+  $("#lights-summary").textContent = summary;
+
+  const slider = $("#brightness");
+  if (document.activeElement !== slider) {
+    slider.value = Math.round(level * 100);
+    $("#brightness-value").textContent = slider.value + "%";
+  }
+  for (const button of document.querySelectorAll("[data-scene]")) {
+    button.classList.toggle("active", button.dataset.scene === lights.setting);
+  }
+
+  const wakeup = lights.wakeup;
+  $("#wakeup").hidden = !wakeup;
+  if (wakeup) {
+    $("#wakeup-progress").value = wakeup.progress;
+    $("#wakeup-summary").textContent = wakeup.snoozed_until
+      ? `Snoozed until ${formatTime(wakeup.snoozed_until)}`
+      : `Waking up · ${Math.round(wakeup.progress * 100)}%`;
+  }
+}
+
+function renderScenes(scenes) {
+  state.scenes = scenes;
+  const list = $("#scene-list");
+  list.replaceChildren();
+  for (const scene of scenes) {
+    if (["full", "low", "off"].includes(scene.id)) {
+      continue;
+    }
+    const button = document.createElement("button");
+    button.className = "secondary";
+    button.textContent = scene.name || scene.id;
+    button.dataset.scene = scene.id;
+    button.addEventListener("click", () => recallScene(scene.id));
+    list.append(button);
+  }
+}
+
+async function recallScene(id) {
+  await run(async () => renderLights(await api("PUT", "/lights", { scene: id })));
+}
+
+async function setBrightness(percent) {
+  const kelvin = (state.lights && state.lights.output.Kelvin) || 2700;
+  await run(async () => renderLights(await api("PUT", "/lights", { kelvin, brightness: percent / 100 })));
+}
+
+// Sensors
+
+function formatReading(name, value) {
+  const digits = name === "temperature_f" ? 1 : 0;
+  return value.toFixed(digits) + UNITS[name];
+}
+
+function renderReading(name, value) {
+  if (value === undefined || value === null) {
+    return;
+  }
+  $(`[data-reading="${name}"] .value`).textContent = formatReading(name, value);
+}
+
+function renderSensors(sensors) {
+  if (sensors.atmo) {
+    renderReading("temperature_f", sensors.atmo.temperature_f);
+    renderReading("humidity", sensors.atmo.humidity);
+  }
+  if (sensors.dust) {
+    renderReading("pm25", sensors.dust.pm25);
+    renderReading("pm10", sensors.dust.pm10);
+  }
+}
+
+function renderHistory(history) {
+  state.history = history;
+  for (const name of READINGS) {
+    const values = history.map((s) => s[name]).filter((v) => v !== undefined);
+    const line = $(`[data-reading="${name}"] polyline`);
+    if (values.length < 2) {
+      line.setAttribute("points", "");
+      continue;
+    }
+    const min = Math.min(...values);
+    const range = Math.max(...values) - min || 1;
+    const points = values.map((v, i) => {
+      const x = (i / (values.length - 1)) * 100;
+      const y = 22 - ((v - min) / range) * 20;
+      return `${x.toFixed(1)},${y.toFixed(1)}`;
+    });
+    line.setAttribute("points", points.join(" "));
+  }
+}
+
+// Pages
+
+function renderPages(pages) {
+  const list = $("#page-list");
+  list.replaceChildren();
+  for (const page of pages) {
+    const item = document.createElement("li");
+    const text = document.createElement("span");
+    text.textContent = `${page.source} at ${formatTime(page.at)}`;
+    if (page.count > 1) {
+      text.textContent += ` (${page.count} times, last at ${formatTime(page.last_at)})`;
+    }
+    const button = document.createElement("button");
+    button.textContent = "Answer";
+    button.addEventListener("click", () => run(async () => {
+      await api("DELETE", `/pager/${page.id}`);
+      await loadPages();
+    }));
+    item.append(text, button);
+    list.append(item);
+  }
+  $("#pages").hidden = pages.length === 0;
+}
+
+async function loadPages() {
+  renderPages(await api("GET", "/pager"));
+}
+
+// Alarms
+
+const ALARM_FIELDS = ["id", "name", "time", "days", "date", "enabled", "skip_next", "curve", "scene",
+  "finale", "finale_max", "effect", "zones"];
+
+function renderAlarms(alarms) {
+  const list = $("#alarm-list");
+  list.replaceChildren();
+  for (const alarm of alarms) {
+    list.append(alarmForm(alarm));
+  }
+}
+
+function alarmForm(alarm) {
+  const form = $("#alarm-template").content.firstElementChild.cloneNode(true);
+  const scenes = form.elements.scene;
+  for (const scene of state.scenes) {
+    scenes.append(new Option(scene.name || scene.id, scene.id));
+  }
+
+  form.elements.name.value = alarm.name || "";
+  form.elements.time.value = alarm.time || "07:00";
+  form.elements.enabled.checked = alarm.id ? alarm.enabled : true;
+  scenes.value = alarm.scene || "";
+  const days = alarm.days || [];
+  for (const box of form.querySelectorAll("[name=days]")) {
+    box.checked = days.includes(box.value);
+  }
+  if (alarm.skip_next) {
+    $(".next", form).textContent = "Skipping the next wakeup";
+  } else if (alarm.next_due) {
+    $(".next", form).textContent = `Next: ${formatDateTime(alarm.next_due)}`;
+  }
+  $(".skip", form).textContent = alarm.skip_next ? "Don't skip" : "Skip next";
+  $(".skip", form).hidden = !alarm.id;
+
+  form.addEventListener("submit", (e) => {
+    e.preventDefault();
+    const update = {};
+    for (const field of ALARM_FIELDS) {
+      if (alarm[field] !== undefined) {
+        update[field] = alarm[field];
+      }
+    }
+    update.name = form.elements.name.value;
+    update.time = form.elements.time.value;
+    update.enabled = form.elements.enabled.checked;
+    update.scene = scenes.value || undefined;
+    update.days = [...form.querySelectorAll("[name=days]:checked")].map((box) => box.value);
+    run(async () => {
+      if (alarm.id) {
+        await api("PUT", `/alarms/${encodeURIComponent(alarm.id)}`, update);
+      } else {
+        await api("POST", "/alarms", update);
+      }
+      await loadAlarms();
+    });
+  });
+  $(".skip", form).addEventListener("click", () => run(async () => {
+    await api(alarm.skip_next ? "DELETE" : "POST", `/alarms/${encodeURIComponent(alarm.id)}/skip`);
+    await loadAlarms();
+  }));
+  $(".delete", form).addEventListener("click", () => {
+    if (!alarm.id) {
+      form.remove();
+      return;
+    }
+    if (confirm(`Delete ${alarm.name || alarm.id}?`)) {
+      run(async () => {
+        await api("DELETE", `/alarms/${encodeURIComponent(alarm.id)}`);
+        await loadAlarms();
+      });
+    }
+  });
+  return form;
+}
+
+async function loadAlarms() {
+  try {
+    renderAlarms(await api("GET", "/alarms"));
+  } catch (e) {
+    if (e.status === 503) {
+      $("#alarm-list").textContent = "Alarms are not configured on this node.";
+      $("#add-alarm").hidden = true;
+      return;
+    }
+    throw e;
+  }
+}
+
+// Formatting
+
+function formatTime(t) {
+  return new Date(t).toLocaleTimeString([], { hour: "numeric", minute: "2-digit" });
+}
+
+function formatDateTime(t) {
+  return new Date(t).toLocaleString([], { weekday: "short", hour: "numeric", minute: "2-digit" });
+}
+
+// Events
+
+async function loadAll() {
+  const [lights, scenes, sensors, history] = await Promise.all([
+    api("GET", "/lights"),
+    api("GET", "/scenes"),
+    api("GET", "/sensors"),
+    api("GET", "/sensors/history"),
+  ]);
+  renderScenes(scenes);
+  renderLights(lights);
+  renderSensors(sensors);
+  renderHistory(history);
+  await Promise.all([loadPages(), loadAlarms()]);
+}
+
+function handleEvent(type, event) {
+  switch (type) {
+    case "lights":
+      renderLights(event.data);
+      break;
+    case "wakeup":
+      api("GET", "/lights").then(renderLights, () => {});
+      if (event.data.state === "finished") {
+        loadAlarms().catch(() => {});
+      }
+      break;
+    case "pager":
+      loadPages().catch(() => {});
+      break;
+    case "sensor":
+      renderSensors(event.data);
+      break;
+    case "resync":
+      loadAll().catch(() => {});
+      break;
+  }
+}
+
+// parseEvents splits the text/event-stream format into events, and returns
+// whatever is left over for the next chunk.
+function parseEvents(buffer, onEvent) {
+  let end;
+  while ((end = buffer.indexOf("\n\n")) >= 0) {
+    const block = buffer.slice(0, end);
+    buffer = buffer.slice(end + 2);
+    let type = "message";
+    let data = "";
+    for (const line of block.split("\n")) {
+      if (line.startsWith("event: ")) {
+        type = line.slice(7);
+      } else if (line.startsWith("data: ")) {
+        data += line.slice(6);
+      } else if (line.startsWith("id: ")) {
+        state.lastEventID = line.slice(4);
+      }
+    }
+    if (data) {
+      onEvent(type, JSON.parse(data));
+    }
+  }
+  return buffer;
+}
+
+// follow reads the event stream, reconnecting from the last event it saw
+// whenever the stream ends. EventSource can't send the token, so the stream
+// is read with fetch instead.
+async function follow() {
+  let delay = 1000;
+  for (;;) {
+    try {
+      const headers = {};
+      const token = localStorage.getItem("token");
+      if (token) {
+        headers["Authorization"] = "Bearer " + token;
+      }
+      if (state.lastEventID) {
+        headers["Last-Event-ID"] = state.lastEventID;
+      }
+      const resp = await fetch(API + "/events", { headers });
+      if (resp.status === 401 || resp.status === 403) {
+        $("#login").hidden = false;
+        return;
+      }
+      if (!resp.ok) {
+        throw new Error(resp.statusText);
+      }
+      $("#connection").classList.add("connected");
+      $("#connection").title = "Connected";
+      delay = 1000;
+
+      const reader = resp.body.pipeThrough(new TextDecoderStream()).getReader();
+      let buffer = "";
+      for (;;) {
+        const { value, done } = await reader.read();
+        if (done) {
+          break;
+        }
+        buffer = parseEvents(buffer + value.replace(/\r\n/g, "\n"), handleEvent);
+      }
+    } catch (e) {
+      // reconnect below
+    }
+    $("#connection").classList.remove("connected");
+    $("#connection").title = "Disconnected";
+    await new Promise((resolve) => setTimeout(resolve, delay));
+    delay = Math.min(delay * 2, 30000);
+  }
+}
+
+// Setup
+
+for (const button of document.querySelectorAll("[data-scene]")) {
+  button.addEventListener("click", () => recallScene(button.dataset.scene));
+}
+$("#brightness").addEventListener("input", (e) => {
+  $("#brightness-value").textContent = e.target.value + "%";
+});
+$("#brightness").addEventListener("change", (e) => setBrightness(Number(e.target.value)));
+$("#snooze").addEventListener("click", () => run(async () => renderLights(await api("POST", "/lights/wakeup/snooze"))));
+$("#dismiss").addEventListener("click", () => run(async () => renderLights(await api("DELETE", "/lights/wakeup"))));
+$("#add-alarm").addEventListener("click", () => $("#alarm-list").append(alarmForm({})));
+$("#login-form").addEventListener("submit", (e) => {
+  e.preventDefault();
+  localStorage.setItem("token", $("#token").value);
+  location.reload();
+});
+
+// The history is only sampled every so often, so refresh it on a timer
+setInterval(() => api("GET", "/sensors/history").then(renderHistory, () => {}), 60000);
+document.addEventListener("visibilitychange", () => {
+  if (document.visibilityState === "visible") {
+    loadAll().catch(() => {});
+  }
+});
+
+loadAll().catch(() => {}).finally(follow);
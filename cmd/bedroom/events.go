@@ -0,0 +1,245 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/klaital/wannetiot/pkg/config"
+	"github.com/klaital/wannetiot/pkg/events"
+	"github.com/klaital/wannetiot/pkg/lights"
+)
+
+// eventBroker publishes the node's events to the /api/v1/events stream.
+// It is replaced with one sized from the config at startup.
+var eventBroker = events.NewBroker(256)
+
+// eventBuffer is how many events a stream can fall behind by before it is
+// dropped, to reconnect and catch up from its last event ID.
+const eventBuffer = 64
+
+// WakeupEvent reports a wakeup starting, progressing, being snoozed, or finishing.
+type WakeupEvent struct {
+	State        string     `json:"state"` // started, progress, snoozed or finished
+	Progress     float64    `json:"progress"`
+	SnoozedUntil *time.Time `json:"snoozed_until,omitempty"`
+}
+
+// PagerEvent reports the pager being pressed on a panel or the RF remote,
+// a page received over the API, the panels acknowledging a page, the count
+// of a page's repeats once its cooldown is over, or a page being closed
+// once it has been answered.
+type PagerEvent struct {
+	Action string `json:"action"` // pressed, received, acknowledged, repeated or closed
+	Source string `json:"source"` // such as panel1, rf or api
+	PageID int    `json:"page_id,omitempty"`
+	Count  int    `json:"count,omitempty"` // how many times the page was sent, with its repeats
+}
+
+// SensorEvent is a new sensor reading.
+type SensorEvent struct {
+	Sensor  string        `json:"sensor"` // atmo, dust or ambient
+	Atmo    *AtmoState    `json:"atmo,omitempty"`
+	Dust    *DustState    `json:"dust,omitempty"`
+	Ambient *AmbientState `json:"ambient,omitempty"`
+}
+
+// AlarmEvent reports a leak or fire detected by one of the nodes.
+type AlarmEvent struct {
+	Kind    string `json:"kind"` // leak or fire
+	Node    string `json:"node"`
+	Active  bool   `json:"active"`
+	Message string `json:"message,omitempty"`
+}
+
+// publishEvent sends an event to the stream's subscribers.
+func publishEvent(cfg *config.Config, typ string, data interface{}) {
+	if _, err := eventBroker.Publish(typ, data); err != nil {
+		cfg.Logger.WithError(err).WithField("type", typ).Error("Failed to publish event")
+	}
+}
+
+// lightsSnapshot is the part of the lights' state that is worth an event
+// when it changes.
+type lightsSnapshot struct {
+	setting string
+	effect  string
+	sleep   bool
+	outputs []lights.LightConfig
+}
+
+func snapshotLights(state LightsState) lightsSnapshot {
+	s := lightsSnapshot{setting: state.Setting, effect: state.Effect, sleep: state.Sleep != nil}
+	for _, z := range state.Zones {
+		s.outputs = append(s.outputs, z.Output)
+	}
+	return s
+}
+
+// watchLights publishes the lights' state whenever it changes, and the
+// progress of any wakeup. Checking on a ticker catches every change, from
+// the panels, the RF remote, the alarms and the API alike, and limits the
+// events sent during a fade.
+func watchLights(ctx context.Context, cfg *config.Config) {
+	ticker := time.NewTicker(cfg.EventsLightsInterval)
+	defer ticker.Stop()
+
+	last := snapshotLights(currentLightsState())
+	var wakeup *ProgressState
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		state := currentLightsState()
+		if s := snapshotLights(state); !reflect.DeepEqual(s, last) {
+			last = s
+			publishEvent(cfg, events.Lights, state)
+		}
+
+		switch w := state.Wakeup; {
+		case w != nil && wakeup == nil:
+			publishEvent(cfg, events.Wakeup, WakeupEvent{State: "started", Progress: w.Progress})
+		case w != nil && w.SnoozedUntil != nil && (wakeup.SnoozedUntil == nil || !w.SnoozedUntil.Equal(*wakeup.SnoozedUntil)):
+			publishEvent(cfg, events.Wakeup, WakeupEvent{State: "snoozed", Progress: w.Progress, SnoozedUntil: w.SnoozedUntil})
+		case w != nil && w.Progress-wakeup.Progress >= 0.01:
+			publishEvent(cfg, events.Wakeup, WakeupEvent{State: "progress", Progress: w.Progress})
+		case w == nil && wakeup != nil:
+			publishEvent(cfg, events.Wakeup, WakeupEvent{State: "finished", Progress: wakeup.Progress})
+		default:
+			// Keep the last progress published, so that slow wakeups still report each step
+			continue
+		}
+		wakeup = state.Wakeup
+	}
+}
+
+// eventTypes reads the event types to subscribe to, from ?type=lights,pager.
+func eventTypes(req *http.Request) ([]string, error) {
+	var types []string
+	for _, v := range req.URL.Query()["type"] {
+		for _, t := range strings.Split(v, ",") {
+			if t = strings.TrimSpace(t); t == "" {
+				continue
+			}
+			if !events.ValidType(t) {
+				return nil, apiErrorf(http.StatusBadRequest, "invalid_request", "unknown event type %q - valid options: %v", t, events.Types)
+			}
+			types = append(types, t)
+		}
+	}
+	return types, nil
+}
+
+// writeEvent sends an event in the text/event-stream format.
+func writeEvent(resp http.ResponseWriter, e events.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(resp, "id: %s\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+	return err
+}
+
+// serveEvents streams events as Server-Sent Events, until the client goes
+// away or the server shuts down. A client reconnecting with Last-Event-ID
+// first gets the events it missed, or a resync event if they are no longer
+// kept. Comments are sent as a heartbeat while it is quiet, so that proxies
+// and clients can tell the stream is still alive.
+func (srv *Server) serveEvents(resp http.ResponseWriter, req *apiRequest) error {
+	types, err := eventTypes(req.Request)
+	if err != nil {
+		return err
+	}
+	flusher, ok := resp.(http.Flusher)
+	if !ok {
+		return apiErrorf(http.StatusInternalServerError, "internal", "streaming is not supported")
+	}
+	lastID := req.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = req.URL.Query().Get("last_event_id")
+	}
+
+	sub, missed, complete := eventBroker.Subscribe(types, lastID, eventBuffer)
+	defer eventBroker.Unsubscribe(sub)
+
+	resp.Header().Set("Content-Type", "text/event-stream")
+	resp.Header().Set("Cache-Control", "no-cache")
+	resp.Header().Set("Connection", "keep-alive")
+	resp.Header().Set("X-Accel-Buffering", "no")
+	resp.WriteHeader(http.StatusOK)
+	fmt.Fprintf(resp, "retry: %d\n\n", (3 * time.Second).Milliseconds())
+	if !complete {
+		fmt.Fprint(resp, "event: resync\ndata: {}\n\n")
+	}
+	for _, e := range missed {
+		if err := writeEvent(resp, e); err != nil {
+			return nil
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(srv.app.EventsHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-req.Context().Done():
+			return nil
+		case <-srv.streamsDone:
+			return nil
+		case e, ok := <-sub.C:
+			if !ok {
+				// Too far behind, so end the stream for the client to reconnect and catch up
+				return nil
+			}
+			if err := writeEvent(resp, e); err != nil {
+				return nil
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(resp, ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// closeStreams ends the event streams, so the server can shut down.
+func (srv *Server) closeStreams() {
+	srv.closeStreamsOnce.Do(func() {
+		close(srv.streamsDone)
+	})
+}
+
+// APIEventRequest is the body of POST /api/v1/events, for other nodes to
+// report a leak or fire.
+type APIEventRequest struct {
+	Type string     `json:"type"`
+	Data AlarmEvent `json:"data"`
+}
+
+func (srv *Server) apiPublishEvent(req *apiRequest) (int, interface{}, error) {
+	var eventReq APIEventRequest
+	if err := req.decode(&eventReq, true); err != nil {
+		return 0, nil, err
+	}
+	if eventReq.Type != events.Alarm {
+		return 0, nil, apiErrorf(http.StatusBadRequest, "invalid_request", "only %s events can be published", events.Alarm)
+	}
+	if eventReq.Data.Kind != "leak" && eventReq.Data.Kind != "fire" {
+		return 0, nil, apiErrorf(http.StatusBadRequest, "invalid_request", "alarm kind must be leak or fire")
+	}
+	if eventReq.Data.Node == "" {
+		return 0, nil, apiErrorf(http.StatusBadRequest, "invalid_request", "alarm node is required")
+	}
+	e, err := eventBroker.Publish(events.Alarm, eventReq.Data)
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusAccepted, e, nil
+}
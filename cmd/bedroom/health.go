@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/klaital/wannetiot/pkg/health"
+)
+
+// version is set when building a release, with
+// -ldflags "-X main.version=v1.2.3".
+var version = "dev"
+
+// startedAt is when the node started, for its uptime.
+var startedAt = time.Now()
+
+// buildVersion reports the version the node was built as, falling back on
+// the module version for builds without one.
+func buildVersion() string {
+	if version != "dev" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return version
+}
+
+// NodeStatus is the body of GET /api/v1/status. Status is the worst of the
+// subsystems', and Ready is false while a critical one is down.
+type NodeStatus struct {
+	Node          string          `json:"node"`
+	Status        health.Status   `json:"status"`
+	Ready         bool            `json:"ready"`
+	Version       string          `json:"version"`
+	GoVersion     string          `json:"go_version"`
+	StartedAt     time.Time       `json:"started_at"`
+	Uptime        string          `json:"uptime"`
+	UptimeSeconds int64           `json:"uptime_seconds"`
+	Subsystems    []health.Report `json:"subsystems"`
+}
+
+func (srv *Server) apiGetStatus(req *apiRequest) (int, interface{}, error) {
+	reports, status, ready := health.Default.Check()
+	uptime := time.Since(startedAt)
+	return http.StatusOK, NodeStatus{
+		Node:          srv.app.NodeName,
+		Status:        status,
+		Ready:         ready,
+		Version:       buildVersion(),
+		GoVersion:     strings.TrimPrefix(runtime.Version(), "go"),
+		StartedAt:     startedAt,
+		Uptime:        uptime.Round(time.Second).String(),
+		UptimeSeconds: int64(uptime.Seconds()),
+		Subsystems:    reports,
+	}, nil
+}
+
+// serveHealthz answers as long as the node is serving requests at all.
+func serveHealthz(resp http.ResponseWriter, req *http.Request) {
+	resp.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(resp, "ok")
+}
+
+// serveReadyz fails while any critical subsystem is down, and lists them.
+func serveReadyz(resp http.ResponseWriter, req *http.Request) {
+	reports, _, ready := health.Default.Check()
+	resp.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if ready {
+		fmt.Fprintln(resp, "ok")
+		return
+	}
+	resp.WriteHeader(http.StatusServiceUnavailable)
+	for _, r := range reports {
+		if r.Critical && r.Status == health.StatusDown {
+			fmt.Fprintln(resp, strings.TrimSpace(fmt.Sprintf("%s: %s %s", r.Name, r.Status, r.Message)))
+		}
+	}
+}
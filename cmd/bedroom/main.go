@@ -2,21 +2,30 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"github.com/klaital/wannetiot/pkg/alarms"
 	"github.com/klaital/wannetiot/pkg/config"
 	"github.com/klaital/wannetiot/pkg/ctlpanel"
+	"github.com/klaital/wannetiot/pkg/events"
+	"github.com/klaital/wannetiot/pkg/health"
 	"github.com/klaital/wannetiot/pkg/latchedrf"
 	"github.com/klaital/wannetiot/pkg/lights"
 	"github.com/klaital/wannetiot/pkg/loggingresponsewriter"
+	"github.com/klaital/wannetiot/pkg/netpanel"
 	"github.com/klaital/wannetiot/pkg/util"
 	log "github.com/sirupsen/logrus"
 	"net/http"
 	"os"
 	"os/signal"
+	"periph.io/x/conn/v3/gpio"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type ControlState struct {
 	LightState    lights.LightConfig
+	Scenes        *lights.SceneStore
 	ControlPanel1 ctlpanel.ControlPanel
 	ControlPanel2 ctlpanel.ControlPanel
 	RadioReceiver *latchedrf.LatchedRadioReceiver
@@ -31,6 +40,14 @@ var globalState ControlState = ControlState{
 
 var influxBuffer []util.InfluxDataPoint
 
+// maxTelemetryBacklog is how many sensor readings are kept while InfluxDB
+// can't be reached.
+const maxTelemetryBacklog = 1000
+
+// latestAtmo keeps the most recent temperature and humidity reading, for the API.
+var latestAtmo *util.AtmoData
+var latestAtmoLock sync.Mutex
+
 func main() {
 	var err error
 	ctx, halt := context.WithCancel(context.Background())
@@ -49,64 +66,82 @@ func main() {
 
 	cfg.InitPins()
 	defer cfg.HaltPins()
+	eventBroker = events.NewBroker(cfg.EventsHistory)
+
+	// Map light levels through the perceptual brightness model
+	lights.ConfigureBrightness(lights.NewBrightnessModel(cfg))
+	lights.ConfigureColorProfile(lights.NewColorProfile(cfg))
+	if err = lights.SetDimLevel(cfg.DimLevel); err != nil {
+		logger.WithError(err).WithField("level", cfg.DimLevel).Error("Invalid dim level, using default")
+	}
+	lights.ConfigureZones(cfg)
+	lights.ConfigureAutoBrightness(lights.NewAutoBrightness(cfg))
+
+	// Restore the saved scenes, and the light state to start up in
+	globalState.Scenes, err = lights.OpenSceneStore(cfg.LightsStateFile)
+	if err != nil {
+		logger.WithError(err).WithField("file", cfg.LightsStateFile).Fatal("Failed to load light scenes")
+	}
+	if level, ok := globalState.Scenes.DimLevel(); ok {
+		if err = lights.SetDimLevel(level); err != nil {
+			logger.WithError(err).WithField("level", level).Error("Invalid saved dim level, ignoring")
+		}
+	}
+	if bootState, err := globalState.Scenes.BootState(cfg.LightsBootState); err != nil {
+		logger.WithError(err).WithField("boot", cfg.LightsBootState).Error("Unknown boot light state, starting with the lights off")
+	} else {
+		logger.WithField("lights", bootState).Info("Restoring light state")
+		setLights(cfg, bootState)
+	}
 
 	// Initialize the attached sensors
 	cfg.InitSensors()
+	defer cfg.HaltAmbientSensor()
+	if cfg.AmbientSensor != nil {
+		go pollAmbientLight(ctx, time.NewTicker(cfg.AmbientPollInterval), cfg)
+	}
 
 	// Initialize the RF receiver
-	//logger.Debug("Initializing RF Receiver")
-	//globalState.RadioReceiver, err = latchedrf.New(cfg.RadioLatchResetPin, cfg.RadioChannelAPin, cfg.RadioChannelBPin, cfg.RadioChannelCPin, cfg.RadioChannelDPin)
-	//if err != nil {
-	//	logger.WithError(err).WithFields(log.Fields{
-	//		"ResetPin": cfg.RadioLatchResetPin,
-	//		"A":        cfg.RadioChannelAPin,
-	//		"B":        cfg.RadioChannelBPin,
-	//		"C":        cfg.RadioChannelCPin,
-	//		"D":        cfg.RadioChannelDPin,
-	//	}).Fatal("Failed to instantiate LatchedRadioReceiver")
-	//}
-	//globalState.RadioReceiver.WaitTimeout = cfg.RadioWaitTimeout
-	//globalState.RadioReceiver.RegisterChannelAHandler(func() {
-	//	logger.WithField("channel", "A").Debug("RF signal received")
-	//	globalState.LightState = lights.LightSettingsFull()
-	//	if cfg.LedStripEnabled {
-	//		logger.WithField("lights", globalState.LightState).Debug("Driving new light settings")
-	//		lights.HaltWakeup()
-	//		lights.DriveLights(cfg, &globalState.LightState)
-	//	}
-	//})
-	//globalState.RadioReceiver.RegisterChannelBHandler(func() {
-	//	logger.WithField("channel", "B").Debug("RF signal received")
-	//	globalState.LightState = lights.LightSettingLow()
-	//	if cfg.LedStripEnabled {
-	//		logger.WithField("lights", globalState.LightState).Debug("Driving new light settings")
-	//		lights.HaltWakeup()
-	//		lights.DriveLights(cfg, &globalState.LightState)
-	//	}
-	//})
-	//globalState.RadioReceiver.RegisterChannelCHandler(func() {
-	//	logger.WithField("channel", "C").Debug("RF signal received")
-	//	globalState.LightState = lights.Off
-	//	if cfg.LedStripEnabled {
-	//		logger.WithField("lights", globalState.LightState).Debug("Driving new light settings")
-	//		lights.HaltWakeup()
-	//		lights.DriveLights(cfg, &globalState.LightState)
-	//	}
-	//})
-	//globalState.RadioReceiver.RegisterChannelDHandler(func() {
-	//	logger.WithField("channel", "D").Debug("RF Pager signal received")
-	//	// Handler that sends out pager notifications
-	//	if cfg.Panel1Enabled {
-	//		globalState.ControlPanel1.AcknowledgePager()
-	//	}
-	//	if cfg.Panel2Enabled {
-	//		globalState.ControlPanel2.AcknowledgePager()
-	//	}
-	//
-	//	// TODO: send out slack notifications
-	//})
+	if cfg.RadioEnabled {
+		logger.Debug("Initializing RF Receiver")
+		globalState.RadioReceiver, err = latchedrf.New(cfg.RadioLatchResetPin, cfg.RadioChannelAPin, cfg.RadioChannelBPin, cfg.RadioChannelCPin, cfg.RadioChannelDPin)
+		if err != nil {
+			logger.WithError(err).WithFields(log.Fields{
+				"ResetPin": cfg.RadioLatchResetPin,
+				"A":        cfg.RadioChannelAPin,
+				"B":        cfg.RadioChannelBPin,
+				"C":        cfg.RadioChannelCPin,
+				"D":        cfg.RadioChannelDPin,
+			}).Fatal("Failed to instantiate LatchedRadioReceiver")
+		}
+		globalState.RadioReceiver.WaitTimeout = cfg.RadioWaitTimeout
+		// Any light button also dismisses a wakeup in progress, including its alarm
+		globalState.RadioReceiver.RegisterChannelAHandler(func() {
+			logger.WithField("channel", "A").Debug("RF signal received")
+			lights.HaltWakeup()
+			recallRadioScene(cfg, 0)
+		})
+		globalState.RadioReceiver.RegisterChannelBHandler(func() {
+			logger.WithField("channel", "B").Debug("RF signal received")
+			lights.HaltWakeup()
+			recallRadioScene(cfg, 1)
+		})
+		globalState.RadioReceiver.RegisterChannelCHandler(func() {
+			logger.WithField("channel", "C").Debug("RF signal received")
+			lights.HaltWakeup()
+			recallRadioScene(cfg, 2)
+		})
+		globalState.RadioReceiver.RegisterChannelDHandler(func() {
+			logger.WithField("channel", "D").Debug("RF Pager signal received")
+			_, repeat := sendPage(cfg, "pressed", "rf")
+			// Handler that sends out pager notifications
+			acknowledgePager(cfg, "rf", repeat)
 
-	//globalState.RadioReceiver.Run(ctx)
+			// TODO: send out slack notifications
+		})
+
+		globalState.RadioReceiver.Run(ctx)
+	}
 
 	// Take initial readings from the sensors and record them in Influx
 	if cfg.AM2302Enabled {
@@ -139,6 +174,35 @@ func main() {
 	// Start polling the sensors
 	sensorTicker := time.NewTicker(cfg.PollInterval)
 	go pollSensors(ctx, sensorTicker, cfg)
+	if cfg.SDS011Enabled {
+		go pollDust(ctx, time.NewTicker(cfg.DustPollInterval), cfg)
+	}
+	if cfg.SensorHistory > 0 {
+		go recordSensorHistory(ctx, time.NewTicker(cfg.SensorHistoryInterval), cfg)
+	}
+	if cfg.LedStripEnabled && cfg.InfluxHost != "" && cfg.LightsTelemetryInterval > 0 {
+		go recordLightsPower(ctx, time.NewTicker(cfg.LightsTelemetryInterval), cfg)
+	}
+	if cfg.LedStripEnabled && cfg.EventsLightsInterval > 0 {
+		go watchLights(ctx, cfg)
+	}
+
+	// Start watching the control panels for input
+	if cfg.Panel1Enabled {
+		globalState.ControlPanel1 = newControlPanel(cfg, cfg.Panel1PagerPin, cfg.Panel1LightSwitchPin, cfg.Panel1ResetPin, cfg.Panel1LEDPin, cfg.Panel1SpeakerPin, cfg.Panel1DimmerChannel, logger.WithField("panel", 1))
+		go pollControlPanel(ctx, cfg, 1, &globalState.ControlPanel1)
+		if finaleOnPanel(cfg, 1) {
+			lights.RegisterFinaleSpeaker(globalState.ControlPanel1.PlayAlarm)
+		}
+	}
+	if cfg.Panel2Enabled {
+		globalState.ControlPanel2 = newControlPanel(cfg, cfg.Panel2PagerPin, cfg.Panel2LightSwitchPin, cfg.Panel2ResetPin, cfg.Panel2LEDPin, cfg.Panel2SpeakerPin, cfg.Panel2DimmerChannel, logger.WithField("panel", 2))
+		go pollControlPanel(ctx, cfg, 2, &globalState.ControlPanel2)
+		if finaleOnPanel(cfg, 2) {
+			lights.RegisterFinaleSpeaker(globalState.ControlPanel2.PlayAlarm)
+		}
+	}
+	startNetPanels(cfg)
 
 	//pagerNotice := make(chan uint8, 1)
 	//lightsNotice := make(chan uint8, 1)
@@ -172,13 +236,82 @@ func main() {
 	// Start a background thread to handle the gradual wakup light routine
 	go lights.StartWakeupRunner(ctx, cfg)
 
+	// Start a background thread to handle the wind-down sleep timer
+	go lights.StartSleepRunner(ctx, cfg)
+
+	// Start the wakeup alarms
+	alarmLocation, err := time.LoadLocation(cfg.AlarmsTimezone)
+	if err != nil {
+		logger.WithError(err).WithField("timezone", cfg.AlarmsTimezone).Fatal("Failed to load alarms timezone")
+	}
+	alarmScheduler, err := alarms.New(cfg.AlarmsFile, cfg.WakeupDuration, alarmLocation, func(a alarms.Alarm, due time.Time) {
+		opts := lights.WakeupOptions{
+			// An alarm set within the wakeup duration gets a shorter wakeup
+			Duration:  time.Until(due),
+			Curve:     a.Curve,
+			Finale:    a.Finale,
+			FinaleMax: a.FinaleMaxDuration(),
+			Zones:     a.Zones,
+		}
+		if a.Scene != "" {
+			target, err := globalState.Scenes.SceneSettings(a.Scene)
+			if err != nil {
+				logger.WithError(err).WithField("scene", a.Scene).Error("Unknown alarm scene, waking up to full")
+			} else {
+				opts.Target = &target
+			}
+		}
+		if a.Effect != "" {
+			effect, err := lights.ParseEffect(a.Effect)
+			if err != nil {
+				logger.WithError(err).WithField("effect", a.Effect).Error("Invalid alarm effect, ignoring")
+			} else {
+				opts.FinaleEffect = &effect
+			}
+		}
+		lights.DoWakeupWith(opts)
+	}, logger)
+	if err != nil {
+		logger.WithError(err).WithField("file", cfg.AlarmsFile).Fatal("Failed to load alarms")
+	}
+	go alarmScheduler.Run(ctx)
+
 	// Start a webserver to listen for remote control commands
+	auth, err := newAuthenticator(cfg)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to configure API authentication")
+	}
+	if !auth.Enabled() {
+		logger.Warn("No API tokens or client CA configured, the API is open to the network")
+	}
+	tlsConfig, err := apiTLSConfig(cfg)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to load the API client CA")
+	}
+	serveTLS := cfg.APITLSCert != "" && cfg.APITLSKey != ""
+	if tlsConfig != nil && !serveTLS {
+		logger.Warn("API_CLIENT_CA is set without API_TLS_CERT and API_TLS_KEY, so client certificates can't be used")
+	}
+	server := NewServer(cfg, alarmScheduler, globalState.Scenes)
+	accessLog := loggingresponsewriter.AccessLog(loggingresponsewriter.Options{
+		Logger:       cfg.Logger,
+		MaxBodyBytes: cfg.AccessLogBodyBytes,
+		Headers:      cfg.AccessLogHeaders,
+	})
 	webServer := &http.Server{
-		Addr:    ":8080",
-		Handler: loggingresponsewriter.RequestLoggerMiddleware(NewServer(cfg)),
+		Addr:      ":8080",
+		Handler:   accessLog(auth.Middleware(server)),
+		TLSConfig: tlsConfig,
 	}
+	webServer.RegisterOnShutdown(server.closeStreams)
 	go func() {
-		if err := webServer.ListenAndServe(); err != nil {
+		var err error
+		if serveTLS {
+			err = webServer.ListenAndServeTLS(cfg.APITLSCert, cfg.APITLSKey)
+		} else {
+			err = webServer.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			cfg.Logger.WithError(err).Fatal("Failed to initialize webserver")
 		}
 	}()
@@ -206,11 +339,323 @@ func main() {
 
 }
 
+// setLights makes the settings the current light state, fades the strip to
+// them, and saves them to be restored after a restart. Settings generated
+// from a scene fade over the scene's transition time.
+func setLights(cfg *config.Config, settings lights.LightConfig) {
+	globalState.LightState = settings
+	if cfg.LedStripEnabled {
+		fadeZones(cfg, nil, settings, nil)
+	}
+	saveLightState(cfg)
+}
+
+// setZoneLights fades the given zones or groups to the settings. Addressing
+// every zone makes the settings the current light state, as with setLights.
+// A nil transition uses the scene's, or the configured default.
+func setZoneLights(cfg *config.Config, zones []string, settings lights.LightConfig, transition *time.Duration) error {
+	zs, err := lights.SelectZones(zones...)
+	if err != nil {
+		return err
+	}
+	if len(zs) == len(lights.AllZones()) {
+		zs = nil
+		globalState.LightState = settings
+		defer saveLightState(cfg)
+	}
+	if cfg.LedStripEnabled {
+		fadeZones(cfg, zs, settings, transition)
+	}
+	return nil
+}
+
+// fadeZones fades the zones to the settings. Settings generated from a scene
+// fade over the scene's transition time, unless one is given, with each zone
+// taking the scene's override for it, if any.
+func fadeZones(cfg *config.Config, zs []*lights.Zone, settings lights.LightConfig, transition *time.Duration) {
+	d := cfg.FadeDuration
+	if transition != nil {
+		d = *transition
+	}
+	scene, err := globalState.Scenes.Scene(settings.Name)
+	if err != nil {
+		lights.FadeZoneLights(cfg, zs, settings, d)
+		return
+	}
+	if transition == nil {
+		d = scene.TransitionDuration(cfg.FadeDuration)
+	}
+	if len(scene.Zones) == 0 {
+		lights.FadeZoneLights(cfg, zs, settings, d)
+		return
+	}
+	if _, err = lights.FadeScene(cfg, scene, zs, d); err != nil {
+		cfg.Logger.WithError(err).WithField("scene", scene.ID).Error("Failed to fade to scene zones, using the scene's default")
+		lights.FadeZoneLights(cfg, zs, settings, d)
+	}
+}
+
+// recallScene switches the given zones or groups to the scene with the
+// given ID. No zones switches all of them.
+func recallScene(cfg *config.Config, id string, zones ...string) error {
+	settings, err := globalState.Scenes.SceneSettings(id)
+	if err != nil {
+		return err
+	}
+	return setZoneLights(cfg, zones, settings, nil)
+}
+
+// recallRadioScene switches the lights to the scene assigned to an RF remote button.
+func recallRadioScene(cfg *config.Config, button int) {
+	if button >= len(cfg.RadioScenes) {
+		cfg.Logger.WithField("button", button).Warn("No scene assigned to RF button")
+		return
+	}
+	if err := recallScene(cfg, cfg.RadioScenes[button]); err != nil {
+		cfg.Logger.WithError(err).WithField("scene", cfg.RadioScenes[button]).Error("Failed to recall RF button scene")
+	}
+}
+
+// saveLightState records the current light state to be restored after a restart.
+func saveLightState(cfg *config.Config) {
+	if globalState.Scenes == nil {
+		return
+	}
+	if err := globalState.Scenes.SetLastState(globalState.LightState); err != nil {
+		cfg.Logger.WithError(err).WithField("file", cfg.LightsStateFile).Error("Failed to save light state")
+	}
+}
+
+// playPagerEffect plays the configured pager effect on the lights, if any.
+// It is skipped during a wakeup or sleep timer, which it would cancel.
+func playPagerEffect(cfg *config.Config) {
+	if cfg.PagerEffect == "" || !cfg.LedStripEnabled {
+		return
+	}
+	wakeup, _ := lights.WakeupProgress()
+	sleep, _, _ := lights.SleepProgress()
+	if wakeup || sleep {
+		return
+	}
+	effect, err := lights.ParseEffect(cfg.PagerEffect)
+	if err == nil {
+		_, err = lights.PlayEffect(cfg, effect)
+	}
+	if err != nil {
+		cfg.Logger.WithError(err).WithField("effect", cfg.PagerEffect).Error("Failed to play pager effect")
+	}
+}
+
+// acknowledgePager lights up the enabled panels, wired and networked, to show
+// a page was received, or that it was a repeat of one already sent.
+func acknowledgePager(cfg *config.Config, source string, repeat bool) {
+	told := 0
+	if netPanels != nil {
+		command := netpanel.CommandPageAcknowledged
+		if repeat {
+			command = netpanel.CommandAlreadyPaged
+		}
+		told = netPanels.PushAll(netpanel.Command{Type: command})
+	}
+	if cfg.Panel1Enabled || cfg.Panel2Enabled {
+		if repeat {
+			signalPanels(cfg, (*ctlpanel.ControlPanel).AlreadyPaged)
+		} else {
+			signalPanels(cfg, (*ctlpanel.ControlPanel).AcknowledgePager)
+		}
+		told++
+	}
+	if told > 0 && !repeat {
+		publishEvent(cfg, events.Pager, PagerEvent{Action: "acknowledged", Source: source})
+	}
+}
+
+// panelSignal is held while the panels play a signal, so that a burst of
+// pages can't pile up goroutines waiting on their speakers.
+var panelSignal = make(chan struct{}, 1)
+
+// signalPanels plays a signal on each enabled panel in the background,
+// unless they are still playing the last one.
+func signalPanels(cfg *config.Config, signal func(p *ctlpanel.ControlPanel)) {
+	select {
+	case panelSignal <- struct{}{}:
+	default:
+		cfg.Logger.Debug("Panels are still signalling, skipping")
+		return
+	}
+	go func() {
+		defer func() { <-panelSignal }()
+		var wg sync.WaitGroup
+		for _, panel := range []struct {
+			enabled bool
+			panel   *ctlpanel.ControlPanel
+		}{
+			{cfg.Panel1Enabled, &globalState.ControlPanel1},
+			{cfg.Panel2Enabled, &globalState.ControlPanel2},
+		} {
+			if !panel.enabled {
+				continue
+			}
+			wg.Add(1)
+			go func(p *ctlpanel.ControlPanel) {
+				defer wg.Done()
+				signal(p)
+			}(panel.panel)
+		}
+		wg.Wait()
+	}()
+}
+
+// newControlPanel sets up a panel with the configured gesture timings.
+func newControlPanel(cfg *config.Config, pager, light gpio.PinIn, reset, led, speaker gpio.PinOut, dimmerChannel int, logger *log.Entry) ctlpanel.ControlPanel {
+	p := ctlpanel.New(pager, light, reset, led, speaker, dimmerChannel, cfg.ControlPanelsAdc, logger)
+	p.LongPress = cfg.PanelLongPress
+	p.DoubleTapWindow = cfg.PanelDoubleTap
+	p.SnoozeDuration = cfg.WakeupSnoozeDuration
+	p.SceneCycle = cfg.PanelScenes
+	p.Scenes = globalState.Scenes
+	return p
+}
+
+// finaleOnPanel reports whether the wakeup alarm should sound on the given panel's speaker.
+func finaleOnPanel(cfg *config.Config, panel int) bool {
+	for _, p := range cfg.WakeupFinalePanels {
+		if p == panel {
+			return true
+		}
+	}
+	return false
+}
+
+// pollControlPanel checks the panel's pager and light switch latches, and
+// updates the lights when the switch is touched.
+func pollControlPanel(ctx context.Context, cfg *config.Config, id int, panel *ctlpanel.ControlPanel) {
+	ticker := time.NewTicker(cfg.PanelPollInterval)
+	defer ticker.Stop()
+	// Gestures hold up the loop until the button is released, so allow for them
+	loop := health.NewLoop(time.Minute)
+	health.Register(fmt.Sprintf("panel%d", id), true, loop)
+	loop.Start()
+	defer loop.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			loop.Beat()
+			panel.HandlePager(func() bool {
+				_, repeat := sendPage(cfg, "pressed", fmt.Sprintf("panel%d", id))
+				return repeat
+			})
+			if panel.HandleTouchSwitch(&globalState.LightState) {
+				lights.HaltWakeup()
+				setLights(cfg, globalState.LightState)
+			} else {
+				// The sleep timer may have been started, which also changes the light state
+				saveLightState(cfg)
+			}
+		}
+	}
+}
+
+// pollAmbientLight reads the ambient light level for auto-brightness, and
+// records it in InfluxDB.
+func pollAmbientLight(ctx context.Context, ticker *time.Ticker, cfg *config.Config) {
+	defer ticker.Stop()
+	tracker := health.NewTracker(5 * cfg.AmbientPollInterval)
+	health.Register("ambient", false, tracker)
+	read := func(now time.Time) {
+		lux, err := cfg.AmbientSensor.ReadLux()
+		if err != nil {
+			cfg.Logger.WithError(err).Error("Failed to read ambient light sensor")
+			tracker.Failure(err)
+			return
+		}
+		tracker.Success()
+		lights.SetAmbientLux(lux)
+		publishEvent(cfg, events.Sensor, SensorEvent{
+			Sensor:  "ambient",
+			Ambient: &AmbientState{Lux: lux, Level: lights.AutoBrightnessLevel()},
+		})
+		if cfg.InfluxHost == "" {
+			return
+		}
+		p := util.AmbientData{
+			Node:  cfg.NodeName,
+			Lux:   lux,
+			Level: lights.AutoBrightnessLevel(),
+			Ts:    now,
+		}
+		if err = util.FlushInfluxBuffer([]util.InfluxDataPoint{p}, cfg.GetInfluxDB()); err != nil {
+			cfg.Logger.WithError(err).Error("Failed to write ambient light to influx")
+		}
+	}
+
+	read(time.Now())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			read(now)
+		}
+	}
+}
+
+// recordLightsPower records the strips' estimated power draw and usage in
+// InfluxDB, for each zone and in total.
+func recordLightsPower(ctx context.Context, ticker *time.Ticker, cfg *config.Config) {
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			report := lights.PowerUsage()
+			points := []util.InfluxDataPoint{util.LightsData{
+				Node:         cfg.NodeName,
+				Watts:        report.Watts,
+				KWhToday:     report.KWhToday,
+				OnHoursToday: report.OnHoursToday,
+				Ts:           now,
+			}}
+			for _, z := range report.Zones {
+				points = append(points, util.LightsData{
+					Node:         cfg.NodeName,
+					Zone:         z.Zone,
+					Watts:        z.Watts,
+					KWhToday:     z.KWhToday,
+					OnHoursToday: z.OnHoursToday,
+					DutyHours:    z.DutyHoursToday,
+					Ts:           now,
+				})
+			}
+			if err := util.FlushInfluxBuffer(points, cfg.GetInfluxDB()); err != nil {
+				cfg.Logger.WithError(err).Error("Failed to write lights power to influx")
+			}
+		}
+	}
+}
+
 func pollSensors(ctx context.Context, ticker *time.Ticker, cfg *config.Config) {
 	influxBuffer := make([]util.InfluxDataPoint, 0, cfg.InfluxBufferSize)
 	var temperature, humidity float64
 	var err error
 	var atmoPoint util.AtmoData
+
+	// The AM2302 often fails a read, so it's only down once it has failed for a while
+	tracker := health.NewTracker(10 * cfg.PollInterval)
+	if cfg.AM2302Enabled {
+		health.Register("am2302", false, tracker)
+	}
+	var backlog int64
+	if cfg.InfluxHost != "" {
+		health.Register("telemetry", false, health.ReporterFunc(func() health.Report {
+			r := util.InfluxHealth.Health()
+			r.Details = map[string]interface{}{"backlog": atomic.LoadInt64(&backlog)}
+			return r
+		}))
+	}
 	for {
 		select {
 		case <-ctx.Done():
@@ -221,22 +666,47 @@ func pollSensors(ctx context.Context, ticker *time.Ticker, cfg *config.Config) {
 
 			if err != nil {
 				//cfg.Logger.WithError(err).Error("Failed to read from AM2302 sensor")
+				tracker.Failure(err)
 			} else {
+				tracker.Success()
 				atmoPoint.T = temperature
 				atmoPoint.H = humidity
+				latestAtmoLock.Lock()
+				reading := atmoPoint
+				latestAtmo = &reading
+				latestAtmoLock.Unlock()
+				publishEvent(cfg, events.Sensor, SensorEvent{
+					Sensor: "atmo",
+					Atmo:   &AtmoState{TemperatureF: reading.T, Humidity: reading.H, At: reading.Ts},
+				})
 			}
 
-			// TODO: poll the SDS011 Air Quality sensor as well
+			latestDustLock.Lock()
+			if latestDust != nil {
+				atmoPoint.PM25 = latestDust.PM25
+				atmoPoint.PM10 = latestDust.PM10
+			}
+			latestDustLock.Unlock()
 
+			if cfg.InfluxHost == "" {
+				continue
+			}
 			influxBuffer = append(influxBuffer, atmoPoint)
 
-			// Flush the buffer when it's full
-			if len(influxBuffer) == cap(influxBuffer) {
+			// Flush the buffer when it's full. Points that fail to be written
+			// are kept to try again, up to a limit.
+			if len(influxBuffer) >= cfg.InfluxBufferSize {
 				err = util.FlushInfluxBuffer(influxBuffer, cfg.GetInfluxDB())
 				if err != nil {
 					cfg.Logger.WithError(err).Error("Failed to write points to influx")
+				} else {
+					influxBuffer = influxBuffer[:0]
 				}
 			}
+			if len(influxBuffer) > maxTelemetryBacklog {
+				influxBuffer = influxBuffer[len(influxBuffer)-maxTelemetryBacklog:]
+			}
+			atomic.StoreInt64(&backlog, int64(len(influxBuffer)))
 		}
 	}
 }
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/klaital/wannetiot/pkg/config"
+	"github.com/klaital/wannetiot/pkg/ctlpanel"
+	"github.com/klaital/wannetiot/pkg/health"
+	"github.com/klaital/wannetiot/pkg/lights"
+	"github.com/klaital/wannetiot/pkg/netpanel"
+)
+
+// netPanels keeps the network panels, when they are enabled.
+var netPanels *netpanel.Hub
+
+// startNetPanels sets up the hub for the network panels, and sounds the
+// wakeup alarm on the listed ones.
+func startNetPanels(cfg *config.Config) {
+	if !cfg.NetPanelsEnabled {
+		return
+	}
+	netPanels = netpanel.NewHub(cfg.NetPanelHeartbeat, cfg.NetPanelPollWait)
+	health.Register("netpanels", false, netPanels)
+	if len(cfg.WakeupFinaleNetPanels) > 0 {
+		lights.RegisterFinaleSpeaker(func(level int) {
+			netPanels.PushAll(netpanel.Command{Type: netpanel.CommandAlarm, Level: level}, cfg.WakeupFinaleNetPanels...)
+		})
+	}
+}
+
+// netPanelsEnabled fails the network panel routes while they are disabled.
+func netPanelsEnabled() error {
+	if netPanels == nil {
+		return apiErrorf(http.StatusNotFound, "not_found", "network panels are disabled")
+	}
+	return nil
+}
+
+func (srv *Server) apiListNetPanels(req *apiRequest) (int, interface{}, error) {
+	if err := netPanelsEnabled(); err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, netPanels.Panels(), nil
+}
+
+func (srv *Server) apiRegisterNetPanel(req *apiRequest) (int, interface{}, error) {
+	if err := netPanelsEnabled(); err != nil {
+		return 0, nil, err
+	}
+	var r netpanel.Registration
+	if err := req.decode(&r, true); err != nil {
+		return 0, nil, err
+	}
+	welcome, err := netPanels.Register(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	srv.Logger.WithFields(logrus.Fields{
+		"panel":    r.ID,
+		"firmware": r.Firmware,
+		"remote":   req.RemoteAddr,
+	}).Info("Network panel registered")
+	return http.StatusOK, welcome, nil
+}
+
+func (srv *Server) apiNetPanelHeartbeat(req *apiRequest) (int, interface{}, error) {
+	if err := netPanelsEnabled(); err != nil {
+		return 0, nil, err
+	}
+	var hb netpanel.Heartbeat
+	if err := req.decode(&hb, true); err != nil {
+		return 0, nil, err
+	}
+	commands, err := netPanels.Heartbeat(req.Params["id"], hb)
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, netpanel.Commands{Commands: commands}, nil
+}
+
+// apiNetPanelEvent acts on a press of a network panel's button, and returns
+// the panel's feedback along with any other commands waiting for it.
+func (srv *Server) apiNetPanelEvent(req *apiRequest) (int, interface{}, error) {
+	if err := netPanelsEnabled(); err != nil {
+		return 0, nil, err
+	}
+	var e netpanel.Event
+	if err := req.decode(&e, true); err != nil {
+		return 0, nil, err
+	}
+	id := req.Params["id"]
+	isNew, err := netPanels.Event(id, e)
+	if err != nil {
+		return 0, nil, err
+	}
+	if isNew {
+		srv.handleNetPanelEvent(id, e.Type)
+	}
+	return http.StatusOK, netpanel.Commands{Commands: netPanels.Take(id)}, nil
+}
+
+// apiPollNetPanel waits for commands for a network panel, for up to ?wait
+// seconds.
+func (srv *Server) apiPollNetPanel(req *apiRequest) (int, interface{}, error) {
+	if err := netPanelsEnabled(); err != nil {
+		return 0, nil, err
+	}
+	query := req.URL.Query()
+	var wait time.Duration
+	if s := query.Get("wait"); s != "" {
+		seconds, err := strconv.Atoi(s)
+		if err != nil || seconds < 0 {
+			return 0, nil, apiErrorf(http.StatusBadRequest, "invalid_request", "wait must be a number of seconds")
+		}
+		wait = time.Duration(seconds) * time.Second
+	}
+
+	// Let the server shut down without waiting for the polls
+	ctx, cancel := context.WithCancel(req.Context())
+	defer cancel()
+	go func() {
+		select {
+		case <-srv.streamsDone:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	commands, err := netPanels.Poll(ctx, req.Params["id"], query.Get("session"), wait)
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, netpanel.Commands{Commands: commands}, nil
+}
+
+// handleNetPanelEvent does what a wired panel does for the same press, and
+// queues the feedback for the panel to play.
+func (srv *Server) handleNetPanelEvent(id, event string) {
+	cfg := srv.app
+	logger := srv.Logger.WithFields(logrus.Fields{"panel": id, "event": event})
+	feedback := func(command string) {
+		if err := netPanels.Push(id, netpanel.Command{Type: command}); err != nil {
+			logger.WithError(err).Error("Failed to queue network panel feedback")
+		}
+	}
+
+	if event == netpanel.EventPager {
+		if _, repeat := sendPage(cfg, "pressed", "net:"+id); repeat {
+			feedback(netpanel.CommandAlreadyPaged)
+		} else {
+			feedback(netpanel.CommandPaged)
+		}
+		return
+	}
+
+	if running, _ := lights.WakeupProgress(); running {
+		switch event {
+		case netpanel.EventTap:
+			if err := lights.SnoozeWakeup(cfg.WakeupSnoozeDuration); err != nil {
+				logger.WithError(err).Info("Unable to snooze wakeup")
+				feedback(netpanel.CommandDenied)
+				return
+			}
+			logger.Info("Wakeup snoozed from network panel")
+			feedback(netpanel.CommandSnooze)
+		case netpanel.EventLongPress:
+			lights.HaltWakeup()
+			logger.Info("Wakeup dismissed from network panel, lights on")
+			globalState.LightState = lights.LightSettingsFull()
+			setLights(cfg, globalState.LightState)
+			feedback(netpanel.CommandDismissOn)
+		case netpanel.EventDoubleTap:
+			lights.HaltWakeup()
+			logger.Info("Wakeup dismissed from network panel, lights off")
+			globalState.LightState = lights.Off
+			setLights(cfg, globalState.LightState)
+			feedback(netpanel.CommandDismissOff)
+		}
+		return
+	}
+
+	if event == netpanel.EventLongPress {
+		// The sleep timer drives the strip itself, and finishes with the lights off
+		logger.Info("Sleep timer started from network panel")
+		lights.DoSleep()
+		globalState.LightState = lights.Off
+		saveLightState(cfg)
+		feedback(netpanel.CommandSleep)
+		return
+	}
+	globalState.LightState = ctlpanel.NextScene(srv.scenes, cfg.PanelScenes, globalState.LightState.Name, logger)
+	setLights(cfg, globalState.LightState)
+	feedback(netpanel.CommandScene)
+}
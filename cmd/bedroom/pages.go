@@ -0,0 +1,139 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/klaital/wannetiot/pkg/config"
+	"github.com/klaital/wannetiot/pkg/events"
+)
+
+// maxOpenPages limits how many unanswered pages are kept. The oldest are
+// forgotten first.
+const maxOpenPages = 20
+
+var errPageNotFound = errors.New("page not found")
+
+// Page is a page that hasn't been answered yet. Count includes the repeats
+// that came within the cooldown, the last of them at LastAt.
+type Page struct {
+	ID     int       `json:"id"`
+	Source string    `json:"source"` // such as panel1, rf or api
+	At     time.Time `json:"at"`
+	Count  int       `json:"count"`
+	LastAt time.Time `json:"last_at"`
+
+	// repeatsPending is set while the repeats wait for the end of the cooldown
+	repeatsPending bool
+}
+
+var openPages []Page
+var lastPageID int
+var pagesLock sync.Mutex
+
+// sendPage records a page until it is closed, publishes it as a pager event
+// with the given action, and plays the pager effect. A page within the
+// cooldown of the last one is only counted on it, and all of the repeats are
+// published together once the cooldown is over, so that a stuck button
+// can't flood the notifications. Returns the page, and whether it was a
+// repeat.
+func sendPage(cfg *config.Config, action, source string) (Page, bool) {
+	now := time.Now()
+	pagesLock.Lock()
+	if n := len(openPages); n > 0 && now.Sub(openPages[n-1].At) < cfg.PagerCooldown {
+		last := &openPages[n-1]
+		last.Count++
+		last.LastAt = now
+		if !last.repeatsPending {
+			last.repeatsPending = true
+			id := last.ID
+			time.AfterFunc(cfg.PagerCooldown-now.Sub(last.At), func() { publishRepeats(cfg, id) })
+		}
+		page := *last
+		pagesLock.Unlock()
+
+		cfg.Logger.WithFields(logrus.Fields{
+			"page":   page.ID,
+			"source": source,
+			"count":  page.Count,
+		}).Info("Repeat page within the cooldown")
+		return page, true
+	}
+	lastPageID++
+	page := Page{ID: lastPageID, Source: source, At: now, Count: 1, LastAt: now}
+	openPages = append(openPages, page)
+	if len(openPages) > maxOpenPages {
+		openPages = openPages[len(openPages)-maxOpenPages:]
+	}
+	pagesLock.Unlock()
+
+	publishEvent(cfg, events.Pager, PagerEvent{Action: action, Source: source, PageID: page.ID, Count: page.Count})
+	playPagerEffect(cfg)
+	return page, false
+}
+
+// publishRepeats publishes the count of a page's repeats, once its cooldown
+// is over. Pages closed in the meantime are left out.
+func publishRepeats(cfg *config.Config, id int) {
+	pagesLock.Lock()
+	var page *Page
+	for i := range openPages {
+		if openPages[i].ID == id {
+			openPages[i].repeatsPending = false
+			p := openPages[i]
+			page = &p
+			break
+		}
+	}
+	pagesLock.Unlock()
+	if page == nil {
+		return
+	}
+
+	publishEvent(cfg, events.Pager, PagerEvent{Action: "repeated", Source: page.Source, PageID: page.ID, Count: page.Count})
+}
+
+// closePage marks a page as answered.
+func closePage(cfg *config.Config, id int) error {
+	pagesLock.Lock()
+	var page *Page
+	for i := range openPages {
+		if openPages[i].ID == id {
+			p := openPages[i]
+			page = &p
+			openPages = append(openPages[:i], openPages[i+1:]...)
+			break
+		}
+	}
+	pagesLock.Unlock()
+	if page == nil {
+		return errPageNotFound
+	}
+
+	publishEvent(cfg, events.Pager, PagerEvent{Action: "closed", Source: page.Source, PageID: page.ID, Count: page.Count})
+	return nil
+}
+
+func (srv *Server) apiListPages(req *apiRequest) (int, interface{}, error) {
+	pagesLock.Lock()
+	defer pagesLock.Unlock()
+	pages := make([]Page, len(openPages))
+	copy(pages, openPages)
+	return http.StatusOK, pages, nil
+}
+
+func (srv *Server) apiClosePage(req *apiRequest) (int, interface{}, error) {
+	id, err := strconv.Atoi(req.Params["id"])
+	if err != nil {
+		return 0, nil, errPageNotFound
+	}
+	if err := closePage(srv.app, id); err != nil {
+		return 0, nil, err
+	}
+	return http.StatusNoContent, nil, nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/klaital/wannetiot/pkg/apiauth"
+	"github.com/klaital/wannetiot/pkg/config"
+	"github.com/klaital/wannetiot/pkg/loggingresponsewriter"
+	"github.com/klaital/wannetiot/pkg/ratelimit"
+)
+
+// The kinds of request that are limited separately.
+const (
+	limitRead    = "read"
+	limitControl = "control"
+	limitPager   = "pager"
+)
+
+func newRateLimits(cfg *config.Config) map[string]*ratelimit.Limiter {
+	return map[string]*ratelimit.Limiter{
+		limitRead:    ratelimit.NewLimiter(cfg.RateLimitRead),
+		limitControl: ratelimit.NewLimiter(cfg.RateLimitControl),
+		limitPager:   ratelimit.NewLimiter(cfg.RateLimitPager),
+	}
+}
+
+// rateLimitKind decides which limit a request counts against, the same way
+// as requiredScope. The dashboard's files aren't limited.
+func rateLimitKind(req *http.Request) string {
+	switch {
+	case req.URL.Path == "/pager" || strings.HasPrefix(req.URL.Path, "/pager/"),
+		req.Method == http.MethodPost && req.URL.Path == apiPrefix+"/pager":
+		return limitPager
+	case req.Method == http.MethodGet && (req.URL.Path == "/" || strings.HasPrefix(req.URL.Path, dashboardPrefix)):
+		return ""
+	}
+	if requiredScope(req) == apiauth.ScopeControl {
+		return limitControl
+	}
+	return limitRead
+}
+
+// rateLimitClient tells clients apart by the name of their token or
+// certificate, or else by their address.
+func rateLimitClient(req *http.Request) string {
+	if p, ok := apiauth.PrincipalFrom(req.Context()); ok && p.Name != "" {
+		return "principal:" + p.Name
+	}
+	if host, _, err := net.SplitHostPort(req.RemoteAddr); err == nil {
+		return "addr:" + host
+	}
+	return "addr:" + req.RemoteAddr
+}
+
+// allowRequest checks the request against the client's limit for its kind,
+// and turns it away with 429 Too Many Requests when it is over.
+func (srv *Server) allowRequest(resp http.ResponseWriter, req *http.Request) bool {
+	kind := rateLimitKind(req)
+	limiter := srv.limits[kind]
+	if limiter == nil {
+		return true
+	}
+	allowed, retryAfter := limiter.Allow(rateLimitClient(req))
+	if allowed {
+		return true
+	}
+
+	loggingresponsewriter.AddField(req.Context(), "rate_limit", kind)
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	resp.Header().Set("Retry-After", strconv.Itoa(seconds))
+	msg := fmt.Sprintf("too many %s requests, retry in %s", kind, time.Duration(seconds)*time.Second)
+	if req.URL.Path == apiPrefix || strings.HasPrefix(req.URL.Path, apiPrefix+"/") {
+		srv.api.writeError(resp, apiErrorf(http.StatusTooManyRequests, "rate_limited", "%s", msg))
+	} else {
+		http.Error(resp, msg, http.StatusTooManyRequests)
+	}
+	return false
+}
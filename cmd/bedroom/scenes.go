@@ -0,0 +1,41 @@
+package main
+
+import (
+	"net/http"
+)
+
+// serveScenes handles the unversioned scene routes, with the /api/v1 handlers:
+//
+//	GET    /scenes
+//	POST   /scenes
+//	GET    /scenes/{id}
+//	PUT    /scenes/{id}
+//	DELETE /scenes/{id}          built-in scenes revert to their defaults
+//	POST   /scenes/{id}/recall   switch the lights to the scene, or only the
+//	                             zones given as ?zone=name,group
+func (srv *Server) serveScenes(resp http.ResponseWriter, req *http.Request, path []string, body []byte) {
+	// Trailing slashes leave an empty final token
+	if len(path) > 0 && path[len(path)-1] == "" {
+		path = path[:len(path)-1]
+	}
+
+	var h apiHandler
+	switch {
+	case len(path) == 0 && req.Method == http.MethodGet:
+		h = srv.apiListScenes
+	case len(path) == 0 && req.Method == http.MethodPost:
+		h = srv.apiCreateScene
+	case len(path) == 1 && req.Method == http.MethodGet:
+		h = srv.apiGetScene
+	case len(path) == 1 && req.Method == http.MethodPut:
+		h = srv.apiUpdateScene
+	case len(path) == 1 && req.Method == http.MethodDelete:
+		h = srv.apiDeleteScene
+	case len(path) == 2 && path[1] == "recall" && req.Method == http.MethodPost:
+		h = srv.apiRecallScene
+	default:
+		http.Error(resp, "invalid scenes request", http.StatusNotFound)
+		return
+	}
+	srv.serveLegacy(resp, req, h, path, body)
+}
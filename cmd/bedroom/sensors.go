@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/klaital/wannetiot/pkg/config"
+	"github.com/klaital/wannetiot/pkg/events"
+	"github.com/klaital/wannetiot/pkg/health"
+	"github.com/klaital/wannetiot/pkg/lights"
+)
+
+// DustState is the latest particulate reading from the SDS011, in µg/m³.
+type DustState struct {
+	PM25 float64   `json:"pm25"`
+	PM10 float64   `json:"pm10"`
+	At   time.Time `json:"at"`
+}
+
+// latestDust keeps the most recent dust reading, for the API and to go
+// with the atmo readings in InfluxDB.
+var latestDust *DustState
+var latestDustLock sync.Mutex
+
+// pollDust wakes the dust sensor, reads it once its fan has run for a
+// while, and puts it back to sleep, to make its laser last.
+func pollDust(ctx context.Context, ticker *time.Ticker, cfg *config.Config) {
+	defer ticker.Stop()
+	tracker := health.NewTracker(3 * cfg.DustPollInterval)
+	health.Register("sds011", false, tracker)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := cfg.SDSSensor.Awake(); err != nil {
+			cfg.Logger.WithError(err).Error("Failed to awaken dust sensor")
+			tracker.Failure(err)
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			cfg.SDSSensor.Sleep()
+			return
+		case <-time.After(cfg.DustWarmup):
+		}
+		p, err := cfg.SDSSensor.Query()
+		if err := cfg.SDSSensor.Sleep(); err != nil {
+			cfg.Logger.WithError(err).Error("Failed to sleep dust sensor")
+		}
+		if err != nil {
+			cfg.Logger.WithError(err).Error("Failed to read dust sensor")
+			tracker.Failure(err)
+			continue
+		}
+		tracker.Success()
+		reading := DustState{PM25: p.PM25, PM10: p.PM10, At: p.Timestamp}
+		latestDustLock.Lock()
+		latestDust = &reading
+		latestDustLock.Unlock()
+		publishEvent(cfg, events.Sensor, SensorEvent{Sensor: "dust", Dust: &reading})
+	}
+}
+
+// currentSensors collects the latest reading from each sensor.
+func currentSensors() SensorsState {
+	state := SensorsState{Power: lights.PowerUsage()}
+	latestAtmoLock.Lock()
+	if latestAtmo != nil {
+		state.Atmo = &AtmoState{
+			TemperatureF: latestAtmo.T,
+			Humidity:     latestAtmo.H,
+			At:           latestAtmo.Ts,
+		}
+	}
+	latestAtmoLock.Unlock()
+	latestDustLock.Lock()
+	if latestDust != nil {
+		dust := *latestDust
+		state.Dust = &dust
+	}
+	latestDustLock.Unlock()
+	if lux, ok := lights.AmbientLux(); ok {
+		state.Ambient = &AmbientState{Lux: lux, Level: lights.AutoBrightnessLevel()}
+	}
+	return state
+}
+
+// SensorSample is one point in the recent history of the readings. Sensors
+// that have no reading yet are left out.
+type SensorSample struct {
+	At           time.Time `json:"at"`
+	TemperatureF *float64  `json:"temperature_f,omitempty"`
+	Humidity     *float64  `json:"humidity,omitempty"`
+	PM25         *float64  `json:"pm25,omitempty"`
+	PM10         *float64  `json:"pm10,omitempty"`
+	Lux          *float64  `json:"lux,omitempty"`
+}
+
+// sensorHistory keeps the last samples of the readings, oldest first, for
+// the dashboard to graph without needing InfluxDB.
+var sensorHistory []SensorSample
+var sensorHistoryLock sync.Mutex
+
+// recordSensorHistory samples the latest readings on every tick.
+func recordSensorHistory(ctx context.Context, ticker *time.Ticker, cfg *config.Config) {
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			state := currentSensors()
+			sample := SensorSample{At: now}
+			if state.Atmo != nil {
+				sample.TemperatureF = &state.Atmo.TemperatureF
+				sample.Humidity = &state.Atmo.Humidity
+			}
+			if state.Dust != nil {
+				sample.PM25 = &state.Dust.PM25
+				sample.PM10 = &state.Dust.PM10
+			}
+			if state.Ambient != nil {
+				sample.Lux = &state.Ambient.Lux
+			}
+			if state.Atmo == nil && state.Dust == nil && state.Ambient == nil {
+				continue
+			}
+
+			sensorHistoryLock.Lock()
+			sensorHistory = append(sensorHistory, sample)
+			if len(sensorHistory) > cfg.SensorHistory {
+				sensorHistory = sensorHistory[len(sensorHistory)-cfg.SensorHistory:]
+			}
+			sensorHistoryLock.Unlock()
+		}
+	}
+}
+
+func (srv *Server) apiGetSensorHistory(req *apiRequest) (int, interface{}, error) {
+	sensorHistoryLock.Lock()
+	defer sensorHistoryLock.Unlock()
+	history := make([]SensorSample, len(sensorHistory))
+	copy(history, sensorHistory)
+	return http.StatusOK, history, nil
+}
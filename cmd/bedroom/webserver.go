@@ -4,24 +4,52 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/caarlos0/env/v6"
 	"github.com/sirupsen/logrus"
 	"periph.io/x/conn/v3/gpio"
 
+	"github.com/klaital/wannetiot/pkg/alarms"
 	"github.com/klaital/wannetiot/pkg/config"
+	"github.com/klaital/wannetiot/pkg/ctlpanel"
 	"github.com/klaital/wannetiot/pkg/lights"
+	"github.com/klaital/wannetiot/pkg/loggingresponsewriter"
+	"github.com/klaital/wannetiot/pkg/ratelimit"
 )
 
 type Server struct {
 	Addr   string `env:"ADDR" envDefault:":8080"`
 	Logger *logrus.Logger
 	app    *config.Config
+	alarms *alarms.Scheduler
+	scenes *lights.SceneStore
+	api    *apiRouter
+	// dashboard serves the web UI's files
+	dashboard http.Handler
+
+	// streamsDone is closed to end the event streams when shutting down
+	streamsDone      chan struct{}
+	closeStreamsOnce sync.Once
+	// pagerAcks holds a slot for each pager callback in flight
+	pagerAcks chan struct{}
+	// limits are the rate limits for each kind of request
+	limits map[string]*ratelimit.Limiter
 }
 
-func NewServer(cfg *config.Config) *Server {
+// maxPagerAcks limits the pager callbacks in flight. Pages that come while
+// they are all busy aren't acknowledged.
+const maxPagerAcks = 4
+
+// pagerAckTimeout limits how long a pager callback may take.
+const pagerAckTimeout = 10 * time.Second
+
+func NewServer(cfg *config.Config, alarmScheduler *alarms.Scheduler, scenes *lights.SceneStore) *Server {
 	var srv Server
 	srv.Logger = logrus.New()
 	srv.Logger.SetLevel(logrus.DebugLevel)
@@ -34,10 +62,42 @@ func NewServer(cfg *config.Config) *Server {
 	}
 
 	srv.app = cfg
+	srv.alarms = alarmScheduler
+	srv.scenes = scenes
+	srv.api = srv.apiRoutes()
+	srv.dashboard = dashboardHandler()
+	srv.streamsDone = make(chan struct{})
+	srv.pagerAcks = make(chan struct{}, maxPagerAcks)
+	srv.limits = newRateLimits(cfg)
 
 	return &srv
 }
 
+// ackPager calls a page's callback in the background. The callbacks in
+// flight are limited, so that a flood of pages can't pile up goroutines.
+func (srv *Server) ackPager(callbackUrl string) {
+	select {
+	case srv.pagerAcks <- struct{}{}:
+	default:
+		srv.Logger.WithField("callback", callbackUrl).Warn("Too many pager callbacks in flight, skipping the ack")
+		return
+	}
+	go func() {
+		defer func() { <-srv.pagerAcks }()
+		client := http.Client{Timeout: pagerAckTimeout}
+		resp, err := client.Get(callbackUrl)
+		if err != nil {
+			srv.Logger.WithField("callback", callbackUrl).WithError(err).Error("Failed to ack pager request")
+			return
+		}
+		resp.Body.Close()
+		srv.Logger.WithFields(logrus.Fields{
+			"code":   resp.StatusCode,
+			"status": resp.Status,
+		}).Debug("Got pager ack callback response")
+	}()
+}
+
 //
 //func (s *Server) LoggingHandler() http.Handler {
 //
@@ -50,7 +110,10 @@ type PagerNotice struct {
 	} `json:"callback"`
 }
 type LightsRequest struct {
-	Dimmer     *float64 `json:"dimmer"` // value between 0.0 and 1.0, with 1.0 being full power.
+	Dimmer     *float64 `json:"dimmer"`     // value between 0.0 and 1.0, with 1.0 being full power.
+	Transition *string  `json:"transition"` // fade duration for subsequent light changes, such as "1.5s"
+	Kelvin     *float64 `json:"kelvin"`     // colour temperature to switch to, such as 2700
+	Brightness *float64 `json:"brightness"` // perceived brightness for the colour temperature, 0.0 - 1.0. Defaults to 1.0.
 	ColorPower *struct {
 		Red   string
 		Green string
@@ -59,28 +122,173 @@ type LightsRequest struct {
 	} `json:"colors"`
 }
 
+type SleepRequest struct {
+	Duration string `json:"duration"` // how long the lights take to fade out, such as "30m"
+	Warm     *bool  `json:"warm"`     // shift to a warm colour before fading out
+}
+
+// LightsState reports what the strip is doing.
+type LightsState struct {
+	Setting string             `json:"setting"` // the last light setting requested, such as "low"
+	Output  lights.LightConfig `json:"output"`  // the duty cycles currently being driven
+	Wakeup  *ProgressState     `json:"wakeup,omitempty"`
+	Sleep   *ProgressState     `json:"sleep,omitempty"`
+	Effect  string             `json:"effect,omitempty"` // the type of the effect playing, if any
+	Zones   []ZoneState        `json:"zones"`
+	Power   lights.PowerReport `json:"power"` // estimated power draw, and usage today
+	Ambient *AmbientState      `json:"ambient,omitempty"`
+}
+
+// AmbientState reports the ambient light level, and how auto-brightness is adapting the scenes to it.
+type AmbientState struct {
+	Lux   float64 `json:"lux"`
+	Level float64 `json:"level"` // fraction of their brightness the "low" and "full" scenes are shown at
+}
+
+// ZoneState describes one LED strip zone, and what it is driving.
+type ZoneState struct {
+	Name     string             `json:"name"`
+	Type     string             `json:"type"`
+	Groups   []string           `json:"groups,omitempty"`
+	Channels []string           `json:"channels"`
+	Output   lights.LightConfig `json:"output"`
+	// Frequency is the PWM frequency, and HardwarePWM whether every channel
+	// has a dedicated PWM generator.
+	Frequency   string `json:"frequency"`
+	HardwarePWM bool   `json:"hardware_pwm"`
+}
+
+// ProgressState reports how far through a wakeup or sleep timer the lights are.
+type ProgressState struct {
+	Progress     float64    `json:"progress"` // 0.0 - 1.0
+	Remaining    string     `json:"remaining,omitempty"`
+	SnoozedUntil *time.Time `json:"snoozed_until,omitempty"`
+}
+
+type WakeupRequest struct {
+	Curve    string `json:"curve"`    // name of the wakeup curve, such as "sunrise" or "linear"
+	Duration string `json:"duration"` // how long the wakeup takes, such as "30m"
+}
+
+// legacyRoute names the unversioned endpoint a request is for, for the
+// access log. The light commands are a fixed set, but the rest of the path
+// can hold IDs.
+func legacyRoute(pathTokens []string) string {
+	switch {
+	case pathTokens[1] == "lights" && len(pathTokens) > 2:
+		return "/lights/" + pathTokens[2]
+	case len(pathTokens) > 2:
+		return "/" + pathTokens[1] + "/*"
+	}
+	return "/" + pathTokens[1]
+}
+
+// zoneParam reads the zones or groups a light request is addressed to, from
+// the "zone" query parameter, such as ?zone=desk,bed. None means all zones.
+func zoneParam(req *http.Request) []string {
+	var zones []string
+	for _, v := range req.URL.Query()["zone"] {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				zones = append(zones, name)
+			}
+		}
+	}
+	return zones
+}
+
+// serveLegacy runs an /api/v1 handler for an unversioned route, whose path
+// is {id} and then a fixed action. Failures are sent as plain text, as the
+// unversioned API always has.
+func (srv *Server) serveLegacy(resp http.ResponseWriter, req *http.Request, h apiHandler, path []string, body []byte) {
+	params := make(map[string]string)
+	if len(path) > 0 {
+		params["id"] = path[0]
+	}
+	status, v, err := h(&apiRequest{Request: req, Params: params, Body: body})
+	if err != nil {
+		apiErr := toAPIError(err)
+		srv.Logger.WithError(err).WithFields(logrus.Fields{
+			"method": req.Method,
+			"path":   req.URL.Path,
+		}).Error("Request failed")
+		http.Error(resp, apiErr.Message, apiErr.Status)
+		return
+	}
+	if status == http.StatusNoContent || v == nil {
+		resp.WriteHeader(status)
+		return
+	}
+	srv.writeJSON(resp, status, v)
+}
+
 func (srv *Server) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	switch req.URL.Path {
+	case "/healthz":
+		serveHealthz(resp, req)
+		return
+	case "/readyz":
+		serveReadyz(resp, req)
+		return
+	}
+	if !srv.allowRequest(resp, req) {
+		return
+	}
+	if req.URL.Path == apiPrefix || strings.HasPrefix(req.URL.Path, apiPrefix+"/") {
+		srv.api.ServeHTTP(resp, req)
+		return
+	}
+	if srv.app.DashboardEnabled {
+		if req.URL.Path == "/" {
+			http.Redirect(resp, req, dashboardPrefix, http.StatusFound)
+			return
+		}
+		if strings.HasPrefix(req.URL.Path, dashboardPrefix) {
+			loggingresponsewriter.SetRoute(req.Context(), dashboardPrefix+"*")
+			srv.dashboard.ServeHTTP(resp, req)
+			return
+		}
+	}
 
 	// Set up router
-	pathTokens := strings.Split(req.RequestURI, "/")
+	pathTokens := strings.Split(req.URL.Path, "/")
 	if len(pathTokens) <= 1 {
 		srv.Logger.WithField("path", req.RequestURI).Error("invalid path")
 		http.Error(resp, "invalid path", http.StatusInternalServerError)
 		return
 	}
 
+	loggingresponsewriter.SetRoute(req.Context(), legacyRoute(pathTokens))
 	b, bodyReadErr := io.ReadAll(req.Body)
 
-	// Request logging
-	srv.Logger.WithFields(logrus.Fields{
-		"method": req.Method,
-		"path":   req.RequestURI,
-		"host":   req.Host,
-		"body":   string(b),
-	}).Debug("Request received")
-
 	switch pathTokens[1] {
+	case "zones":
+		if req.Method != http.MethodGet {
+			http.Error(resp, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		srv.writeJSON(resp, http.StatusOK, zoneStates())
+		return
+	case "scenes":
+		if bodyReadErr != nil {
+			resp.WriteHeader(400)
+			return
+		}
+		srv.serveScenes(resp, req, pathTokens[2:], b)
+		return
+	case "alarms":
+		if bodyReadErr != nil {
+			resp.WriteHeader(400)
+			return
+		}
+		srv.serveAlarms(resp, req, pathTokens[2:], b)
+		return
 	case "pager":
+		if req.Method != http.MethodPost {
+			resp.Header().Set("Allow", http.MethodPost)
+			http.Error(resp, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
 		if bodyReadErr != nil {
 			resp.WriteHeader(400)
 			return
@@ -94,51 +302,64 @@ func (srv *Server) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
 
 		// make an asynchronous call to Slack, then ping the control panel
 		// to signal receipt
-		go func() {
-			// TODO: actually make the slack api call
-			callbackUrl := fmt.Sprintf("http://%s:%d/pager/ack", p.Callback.Host, p.Callback.Port)
-			resp, err := http.Get(callbackUrl)
-			if err != nil {
-				srv.Logger.WithField("callback", callbackUrl).WithError(err).Error("Failed to ack pager request")
-			}
+		if p.Callback.Host != "" && !pagerCallbackAllowed(srv.app, req, p.Callback.Host) {
 			srv.Logger.WithFields(logrus.Fields{
-				"code":   resp.StatusCode,
-				"status": resp.Status,
-			}).Debug("Got pager ack callback response")
-		}()
+				"callback": p.Callback.Host,
+				"remote":   req.RemoteAddr,
+			}).Warn("Pager callback host not allowed, skipping the ack")
+		} else if p.Callback.Host != "" {
+			// TODO: actually make the slack api call
+			callbackUrl := fmt.Sprintf("http://%s/pager/ack", net.JoinHostPort(p.Callback.Host, strconv.Itoa(p.Callback.Port)))
+			srv.ackPager(callbackUrl)
+		}
 
+		sendPage(srv.app, "received", "api")
 		resp.WriteHeader(200)
 		return
 
 	case "lights":
-		//if len(pathTokens) < 3 {
-		//	srv.Logger.Error("no light state given")
-		//	http.Error(resp, "no light state given", http.StatusBadRequest)
-		//	return
-		//}
+		if len(pathTokens) < 3 {
+			srv.Logger.Error("no light state given")
+			http.Error(resp, "no light state given", http.StatusNotFound)
+			return
+		}
 		var lightState lights.LightConfig
+		var err error
+		// transition overrides the fade time for this request only
+		var transition *time.Duration
+		status := http.StatusOK
+		// Light commands can be addressed to some of the zones with ?zone=
+		zones := zoneParam(req)
+		if _, err = lights.SelectZones(zones...); err != nil {
+			http.Error(resp, err.Error(), http.StatusBadRequest)
+			return
+		}
 		switch pathTokens[2] {
 		case "toggle":
-			lights.HaltWakeup()
-			srv.Logger.Debug("Wakeup halted, toggling lights")
-			// TODO: lookup current light state.
-			// TODO: advance lights one level in cycle: on -> dim -> off -> on
-			resp.WriteHeader(200)
+			// Like a tap on a panel: snooze a wakeup, or else go to the next scene
+			if running, _ := lights.WakeupProgress(); running {
+				if err = lights.SnoozeWakeup(srv.app.WakeupSnoozeDuration); err != nil {
+					http.Error(resp, err.Error(), toAPIError(err).Status)
+					return
+				}
+				srv.Logger.Info("Wakeup snoozed, toggling lights")
+				resp.WriteHeader(http.StatusOK)
+				return
+			}
+			lightState = ctlpanel.NextScene(srv.scenes, srv.app.PanelScenes, globalState.LightState.Name, srv.Logger.WithField("op", "toggle"))
+			srv.Logger.WithField("scene", lightState.Name).Debug("Toggling lights to the next scene")
 		case "off":
 			lights.HaltWakeup()
 			srv.Logger.Debug("Wakeup halted, turning lights off")
-			lightState = lights.Off
-			resp.WriteHeader(200)
+			lightState, err = srv.scenes.SceneSettings("off")
 		case "on":
 			lights.HaltWakeup()
 			srv.Logger.Debug("Wakeup halted, turning lights on")
-			lightState = lights.LightSettingsFull()
-			resp.WriteHeader(200)
+			lightState, err = srv.scenes.SceneSettings("full")
 		case "dim":
 			lights.HaltWakeup()
 			srv.Logger.Debug("Wakeup halted, dimming lights")
-			lightState = lights.LightSettingLow()
-			resp.WriteHeader(200)
+			lightState, err = srv.scenes.SceneSettings("low")
 		case "configure":
 			var lightsReq LightsRequest
 			if bodyReadErr != nil {
@@ -146,15 +367,47 @@ func (srv *Server) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
 				resp.WriteHeader(400)
 				return
 			}
-			err := json.Unmarshal(b, &lightsReq)
+			err = json.Unmarshal(b, &lightsReq)
 			if err != nil {
 				srv.Logger.WithError(err).Error("Unable to unmarshal light configuration request")
 				resp.WriteHeader(400)
 				return
 			}
 
-			if lightsReq.Dimmer != nil {
-				lights.LightSettingLow()
+			if lightsReq.Transition != nil {
+				d, err := time.ParseDuration(*lightsReq.Transition)
+				if err != nil || d < 0 {
+					srv.Logger.WithField("raw", *lightsReq.Transition).WithError(err).Error("Invalid transition duration")
+					http.Error(resp, "invalid transition duration", http.StatusBadRequest)
+					return
+				}
+				transition = &d
+			}
+
+			if lightsReq.Kelvin != nil {
+				level := 1.0
+				if lightsReq.Brightness != nil {
+					level = *lightsReq.Brightness
+				}
+				lightState, err = lights.ColorTemperature(*lightsReq.Kelvin, level)
+				if err != nil {
+					srv.Logger.WithFields(logrus.Fields{
+						"kelvin":     *lightsReq.Kelvin,
+						"brightness": level,
+					}).WithError(err).Error("Failed to solve colour temperature")
+					http.Error(resp, err.Error(), http.StatusBadRequest)
+					return
+				}
+			} else if lightsReq.Dimmer != nil {
+				if err = lights.SetDimLevel(*lightsReq.Dimmer); err != nil {
+					srv.Logger.WithField("dimmer", *lightsReq.Dimmer).WithError(err).Error("Invalid dimmer level")
+					http.Error(resp, err.Error(), http.StatusBadRequest)
+					return
+				}
+				if err = srv.scenes.SetDimLevel(*lightsReq.Dimmer); err != nil {
+					srv.Logger.WithError(err).Error("Failed to save dimmer level")
+				}
+				lightState = lights.LightSettingLow()
 			} else if lightsReq.ColorPower != nil {
 				if lightState.R, err = gpio.ParseDuty(lightsReq.ColorPower.Red); err != nil {
 					srv.Logger.WithField("raw", lightsReq.ColorPower.Red).WithError(err).Error("Failed to parse requested Red duty cycle")
@@ -168,21 +421,200 @@ func (srv *Server) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
 				if lightState.B, err = gpio.ParseDuty(lightsReq.ColorPower.Blue); err != nil {
 					srv.Logger.WithField("raw", lightsReq.ColorPower.Red).WithError(err).Error("Failed to parse requested Blue duty cycle")
 				}
-				// TODO: write color settings to a config file to be loaded at startup
+				// Keep the colours as the "custom" scene
+				custom, saveErr := srv.scenes.UpdateScene("custom", lights.Scene{
+					Name: "Custom",
+					Color: &lights.ChannelFactors{
+						R: float64(lightState.R) / float64(gpio.DutyMax),
+						G: float64(lightState.G) / float64(gpio.DutyMax),
+						W: float64(lightState.W) / float64(gpio.DutyMax),
+						B: float64(lightState.B) / float64(gpio.DutyMax),
+					},
+					Brightness: 1,
+				})
+				if saveErr != nil {
+					srv.Logger.WithError(saveErr).Error("Failed to save custom colour scene")
+				} else {
+					lightState, _ = custom.Settings()
+				}
+				err = nil
 			}
 
 			srv.Logger.WithField("cfg", lightState).Debug("Configured lights")
-			resp.WriteHeader(204)
+			status = http.StatusNoContent
+		case "effect":
+			srv.serveEffect(resp, req, b, bodyReadErr, zones)
+			return
+		case "state":
+			srv.writeJSON(resp, http.StatusOK, currentLightsState())
+			return
+		case "sleep":
+			var sleepReq SleepRequest
+			if len(b) > 0 {
+				if err := json.Unmarshal(b, &sleepReq); err != nil {
+					srv.Logger.WithError(err).Error("Unable to unmarshal sleep request")
+					resp.WriteHeader(400)
+					return
+				}
+			}
+			opts := lights.SleepOptions{Warm: sleepReq.Warm, Zones: zones}
+			if sleepReq.Duration != "" {
+				d, err := time.ParseDuration(sleepReq.Duration)
+				if err != nil || d <= 0 {
+					http.Error(resp, "invalid sleep duration", http.StatusBadRequest)
+					return
+				}
+				opts.Duration = d
+			}
+			lights.HaltWakeup()
+			srv.Logger.Debug("Starting sleep timer")
+			lights.DoSleepWith(opts)
+			if len(zones) == 0 {
+				globalState.LightState = lights.Off
+				saveLightState(srv.app)
+			}
+			resp.WriteHeader(200)
+			return
+		case "dismiss":
+			// Stop the wakeup and its alarm, leaving the lights as they are
+			lights.HaltWakeup()
+			srv.Logger.Debug("Wakeup dismissed")
+			resp.WriteHeader(200)
+			return
+		case "snooze":
+			if err := lights.SnoozeWakeup(srv.app.WakeupSnoozeDuration); err != nil {
+				srv.Logger.WithError(err).Info("Unable to snooze wakeup")
+				http.Error(resp, err.Error(), http.StatusConflict)
+				return
+			}
+			resp.WriteHeader(200)
+			return
 		case "wakeup":
-			srv.Logger.Debug("Starting wakeup")
-			lights.DoWakeup()
+			var wakeupReq WakeupRequest
+			if len(b) > 0 {
+				if err := json.Unmarshal(b, &wakeupReq); err != nil {
+					srv.Logger.WithError(err).Error("Unable to unmarshal wakeup request")
+					resp.WriteHeader(400)
+					return
+				}
+			}
+			opts := lights.WakeupOptions{Curve: wakeupReq.Curve, Zones: zones}
+			if wakeupReq.Curve != "" {
+				if _, err := lights.LookupCurve(wakeupReq.Curve); err != nil {
+					http.Error(resp, err.Error(), http.StatusBadRequest)
+					return
+				}
+			}
+			if wakeupReq.Duration != "" {
+				d, err := time.ParseDuration(wakeupReq.Duration)
+				if err != nil || d <= 0 {
+					http.Error(resp, "invalid wakeup duration", http.StatusBadRequest)
+					return
+				}
+				opts.Duration = d
+			}
+			srv.Logger.WithField("curve", opts.Curve).Debug("Starting wakeup")
+			lights.DoWakeupWith(opts)
 			resp.WriteHeader(200)
+			return
 		default:
 			srv.Logger.WithField("state", pathTokens[2]).Error("invalid light state")
 			http.Error(resp, "invalid light state", http.StatusBadRequest)
 			return
 		}
-		globalState.LightState = lightState
-		lights.DriveLights(srv.app, &globalState.LightState)
+		// The status is only written once the lights are set, so that a
+		// failure isn't reported as success
+		if err != nil {
+			srv.Logger.WithError(err).Error("Failed to look up light scene")
+			http.Error(resp, err.Error(), toAPIError(err).Status)
+			return
+		}
+		if err = setZoneLights(srv.app, zones, lightState, transition); err != nil {
+			srv.Logger.WithError(err).Error("Failed to set zone lights")
+			http.Error(resp, err.Error(), toAPIError(err).Status)
+			return
+		}
+		resp.WriteHeader(status)
+	}
+}
+
+// currentLightsState reports the strip's output, and the progress of any wakeup or sleep timer.
+func currentLightsState() LightsState {
+	state := LightsState{
+		Setting: globalState.LightState.Name,
+		Output:  lights.CurrentOutput(),
+		Zones:   zoneStates(),
+		Power:   lights.PowerUsage(),
+	}
+	if running, progress := lights.WakeupProgress(); running {
+		state.Wakeup = &ProgressState{Progress: progress}
+		if snoozed, until := lights.WakeupSnoozed(); snoozed {
+			state.Wakeup.SnoozedUntil = &until
+		}
+	}
+	if playing, effect := lights.EffectPlaying(); playing {
+		state.Effect = effect
+	}
+	if lux, ok := lights.AmbientLux(); ok {
+		state.Ambient = &AmbientState{Lux: lux, Level: lights.AutoBrightnessLevel()}
+	}
+	if running, progress, remaining := lights.SleepProgress(); running {
+		state.Sleep = &ProgressState{
+			Progress:  progress,
+			Remaining: remaining.Round(time.Second).String(),
+		}
+	}
+	return state
+}
+
+// zoneStates lists every zone and its current output.
+func zoneStates() []ZoneState {
+	states := make([]ZoneState, 0)
+	for _, z := range lights.AllZones() {
+		states = append(states, ZoneState{
+			Name:        z.Name,
+			Type:        z.Type,
+			Groups:      z.Groups,
+			Channels:    z.Channels(),
+			Output:      z.Output(),
+			Frequency:   z.Frequency().String(),
+			HardwarePWM: z.HardwarePWM(),
+		})
+	}
+	return states
+}
+
+// serveEffect plays or stops a light effect:
+//
+//	POST   /lights/effect   play the effect in the body, then return to the current state
+//	DELETE /lights/effect   stop the effect and return to the previous state
+//
+// The effect plays on the zones in its body, or else those given as ?zone=.
+func (srv *Server) serveEffect(resp http.ResponseWriter, req *http.Request, body []byte, bodyReadErr error, zones []string) {
+	switch req.Method {
+	case http.MethodPost:
+		var effect lights.Effect
+		if bodyReadErr != nil || json.Unmarshal(body, &effect) != nil {
+			http.Error(resp, "invalid effect JSON", http.StatusBadRequest)
+			return
+		}
+		if len(effect.Zones) == 0 {
+			effect.Zones = zones
+		}
+		lights.HaltWakeup()
+		if _, err := lights.PlayEffect(srv.app, effect); err != nil {
+			srv.Logger.WithError(err).Error("Unable to play effect")
+			http.Error(resp, err.Error(), http.StatusBadRequest)
+			return
+		}
+		resp.WriteHeader(http.StatusAccepted)
+	case http.MethodDelete:
+		if err := lights.StopEffect(srv.app); err != nil {
+			http.Error(resp, err.Error(), http.StatusConflict)
+			return
+		}
+		resp.WriteHeader(http.StatusOK)
+	default:
+		http.Error(resp, "method not allowed", http.StatusMethodNotAllowed)
 	}
 }
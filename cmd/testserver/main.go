@@ -1,17 +1,47 @@
+// testserver simulates network control panels against a node, for
+// integration tests without the hardware. Each panel registers, sends
+// heartbeats and polls for commands as the ESP firmware does.
+//
+// Button presses are sent from a script, or when asked over the simulator's
+// own HTTP API:
+//
+//	GET    /panels                          the panels and the commands they received
+//	POST   /panels/{id}/events/{type}       press a button: pager, tap, double_tap or long_press
+//	PUT    /panels/{id}/dimmer/{percent}    turn the dimmer knob
+//	GET    /panels/{id}/commands            the commands the panel received
+//	DELETE /panels/{id}/commands            forget the commands received so far
 package main
 
 import (
+	"context"
 	"encoding/json"
-	"github.com/caarlos0/env/v6"
-	"github.com/sirupsen/logrus"
-	"io/ioutil"
+	"fmt"
 	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/caarlos0/env/v6"
+	"github.com/sirupsen/logrus"
+
+	"github.com/klaital/wannetiot/pkg/apiclient"
+	"github.com/klaital/wannetiot/pkg/netpanel"
 )
 
 type Server struct {
-	Addr   string `env:"ADDR" envDefault:":8080"`
+	Addr string `env:"ADDR" envDefault:":8090"`
+	// NodeURL is the node the panels register with, and Token its API token.
+	NodeURL string `env:"NODE_URL" envDefault:"http://localhost:8080"`
+	Token   string `env:"API_TOKEN"`
+	// Panels lists the IDs of the panels to impersonate.
+	Panels []string `env:"PANELS" envDefault:"sim1"`
+	// Script presses buttons after a delay from starting, as "2s sim1 pager".
+	Script []string `env:"SCRIPT"`
 	Logger *logrus.Logger
+
+	panels map[string]*simPanel
 }
 
 func NewServer() *Server {
@@ -26,42 +56,165 @@ func NewServer() *Server {
 		srv.Logger.WithError(err).Fatal("Failed to load env")
 	}
 
+	client, err := apiclient.NewClientWithResponses(srv.NodeURL, apiclient.WithRequestEditorFn(func(ctx context.Context, req *http.Request) error {
+		req.Header.Set("User-Agent", "testserver")
+		if srv.Token != "" {
+			req.Header.Set("Authorization", "Bearer "+srv.Token)
+		}
+		return nil
+	}))
+	if err != nil {
+		srv.Logger.WithError(err).Fatal("Failed to create the node client")
+	}
+	srv.panels = make(map[string]*simPanel)
+	for _, id := range srv.Panels {
+		srv.panels[id] = newSimPanel(id, client, srv.Logger)
+	}
 	return &srv
 }
 
-type Telemetry struct {
-	Dimmer int `json:"Dimmer"`
-	WiFi int `json:"WiFi"`
+// scriptStep is one button press from the script.
+type scriptStep struct {
+	After time.Duration
+	Panel string
+	Event string
+}
+
+func parseScript(script []string) ([]scriptStep, error) {
+	var steps []scriptStep
+	for _, line := range script {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf(`invalid script step %q: expected "<delay> <panel> <event>"`, line)
+		}
+		after, err := time.ParseDuration(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid script step %q: %w", line, err)
+		}
+		if !validEvent(fields[2]) {
+			return nil, fmt.Errorf("invalid script step %q: unknown event %s", line, fields[2])
+		}
+		steps = append(steps, scriptStep{After: after, Panel: fields[1], Event: fields[2]})
+	}
+	return steps, nil
+}
+
+// runScript presses the buttons at their times from starting.
+func (srv *Server) runScript(ctx context.Context, steps []scriptStep) {
+	start := time.Now()
+	for _, step := range steps {
+		if !sleep(ctx, time.Until(start.Add(step.After))) {
+			return
+		}
+		p, ok := srv.panels[step.Panel]
+		if !ok {
+			srv.Logger.WithField("panel", step.Panel).Error("Script names an unknown panel")
+			continue
+		}
+		if _, err := p.press(ctx, apiclient.NetPanelEventType(step.Event)); err != nil {
+			p.logger.WithError(err).WithField("event", step.Event).Error("Failed to send scripted event")
+		}
+	}
 }
 
 func (srv *Server) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
 	// Set up router
-	pathTokens := strings.Split(req.RequestURI, "/")
-	if len(pathTokens) <= 1 {
-		srv.Logger.WithField("path", req.RequestURI).Error("invalid path")
-		http.Error(resp, "invalid path", http.StatusInternalServerError)
+	pathTokens := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	if pathTokens[0] != "panels" {
+		srv.Logger.WithField("path", req.URL.Path).Error("invalid path")
+		http.Error(resp, "invalid path", http.StatusNotFound)
 		return
 	}
 
-	if pathTokens[1] == "dimmer" {
-		bodyBytes, err := ioutil.ReadAll(req.Body)
-		var telem Telemetry
-		defer req.Body.Close()
-		if err != nil {
-			srv.Logger.WithError(err).Error("Unable to read body")
-			http.Error(resp, "", http.StatusBadRequest)
+	if len(pathTokens) == 1 {
+		if req.Method != http.MethodGet {
+			http.Error(resp, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		states := make([]PanelState, 0, len(srv.Panels))
+		for _, id := range srv.Panels {
+			states = append(states, srv.panels[id].state())
+		}
+		writeJSON(resp, http.StatusOK, states)
+		return
+	}
+
+	p, ok := srv.panels[pathTokens[1]]
+	if !ok {
+		http.Error(resp, "unknown panel", http.StatusNotFound)
+		return
+	}
+	switch {
+	case len(pathTokens) == 4 && pathTokens[2] == "events" && req.Method == http.MethodPost:
+		if !validEvent(pathTokens[3]) {
+			http.Error(resp, "unknown event - valid options: "+strings.Join(netpanel.EventTypes, ", "), http.StatusBadRequest)
 			return
 		}
-		err = json.Unmarshal(bodyBytes, &telem)
+		commands, err := p.press(req.Context(), apiclient.NetPanelEventType(pathTokens[3]))
 		if err != nil {
-			srv.Logger.WithError(err).Error("Unable to deserialize body")
-			http.Error(resp, "", http.StatusBadRequest)
+			srv.Logger.WithError(err).Error("Failed to send event")
+			http.Error(resp, err.Error(), http.StatusBadGateway)
 			return
 		}
-		srv.Logger.WithField("telem", telem).Debug("Got telemetry from control panel")
+		writeJSON(resp, http.StatusOK, commands)
+	case len(pathTokens) == 4 && pathTokens[2] == "dimmer" && req.Method == http.MethodPut:
+		value, err := strconv.Atoi(pathTokens[3])
+		if err != nil || value < 0 || value > 100 {
+			http.Error(resp, "the dimmer is a percentage", http.StatusBadRequest)
+			return
+		}
+		p.setDimmer(value)
+		writeJSON(resp, http.StatusOK, p.state())
+	case len(pathTokens) == 3 && pathTokens[2] == "commands" && req.Method == http.MethodGet:
+		writeJSON(resp, http.StatusOK, p.state().Commands)
+	case len(pathTokens) == 3 && pathTokens[2] == "commands" && req.Method == http.MethodDelete:
+		p.clearCommands()
+		resp.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(resp, "invalid path", http.StatusNotFound)
+	}
+}
+
+func writeJSON(resp http.ResponseWriter, status int, v interface{}) {
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(status)
+	json.NewEncoder(resp).Encode(v)
+}
+
+func validEvent(event string) bool {
+	for _, t := range netpanel.EventTypes {
+		if t == event {
+			return true
+		}
 	}
+	return false
 }
+
 func main() {
 	srv := NewServer()
-	http.ListenAndServe(srv.Addr, srv)
+	steps, err := parseScript(srv.Script)
+	if err != nil {
+		srv.Logger.WithError(err).Fatal("Failed to load the script")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	for _, p := range srv.panels {
+		go p.run(ctx)
+	}
+	go srv.runScript(ctx, steps)
+
+	httpServer := &http.Server{Addr: srv.Addr, Handler: srv}
+	go func() {
+		<-ctx.Done()
+		httpServer.Close()
+	}()
+	srv.Logger.WithFields(logrus.Fields{
+		"addr":   srv.Addr,
+		"node":   srv.NodeURL,
+		"panels": srv.Panels,
+	}).Info("Simulating network panels")
+	if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		srv.Logger.WithError(err).Fatal("Failed to serve")
+	}
 }
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/klaital/wannetiot/pkg/apiclient"
+)
+
+// errRegisterAgain is returned when the node doesn't know the panel's
+// session, such as after it restarted.
+var errRegisterAgain = errors.New("the node asked the panel to register again")
+
+// requestTimeout limits each request to the node, on top of how long a poll
+// is held open.
+const requestTimeout = 10 * time.Second
+
+// retryDelay is how long to wait after a failed request.
+const retryDelay = 2 * time.Second
+
+// simPanel impersonates one network control panel, as its firmware would
+// behave: it registers, sends heartbeats, polls for commands, and sends an
+// event for each simulated button press.
+type simPanel struct {
+	id     string
+	client *apiclient.ClientWithResponses
+	logger *logrus.Entry
+
+	lock      sync.Mutex
+	session   string
+	seq       int64
+	started   time.Time
+	rssi      int
+	dimmer    int
+	heartbeat time.Duration
+	pollWait  time.Duration
+	events    int
+	commands  []apiclient.NetPanelCommand
+}
+
+// PanelState is what the simulator reports about a panel.
+type PanelState struct {
+	ID         string                      `json:"id"`
+	Registered bool                        `json:"registered"`
+	Session    string                      `json:"session,omitempty"`
+	RSSI       int                         `json:"rssi"`
+	Dimmer     int                         `json:"dimmer"`
+	Events     int                         `json:"events"`
+	Commands   []apiclient.NetPanelCommand `json:"commands"`
+}
+
+func newSimPanel(id string, client *apiclient.ClientWithResponses, logger *logrus.Logger) *simPanel {
+	return &simPanel{
+		id:       id,
+		client:   client,
+		logger:   logger.WithField("panel", id),
+		started:  time.Now(),
+		rssi:     -55,
+		dimmer:   50,
+		commands: []apiclient.NetPanelCommand{},
+	}
+}
+
+// run keeps the panel registered with the node, until the context is
+// cancelled.
+func (p *simPanel) run(ctx context.Context) {
+	for {
+		if err := p.register(ctx); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			p.logger.WithError(err).Warn("Failed to register, retrying")
+			if !sleep(ctx, retryDelay) {
+				return
+			}
+			continue
+		}
+
+		// Either loop ends the session when the node asks to register again
+		sessionCtx, cancel := context.WithCancel(ctx)
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			defer cancel()
+			p.sendHeartbeats(sessionCtx)
+		}()
+		go func() {
+			defer wg.Done()
+			defer cancel()
+			p.pollCommands(sessionCtx)
+		}()
+		wg.Wait()
+		if ctx.Err() != nil {
+			return
+		}
+		p.logger.Info("Registering again")
+	}
+}
+
+func (p *simPanel) register(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	name := "Simulated panel " + p.id
+	firmware := "testserver"
+	features := []string{"pager", "switch", "dimmer", "led", "speaker"}
+	resp, err := p.client.RegisterNetPanelWithResponse(ctx, apiclient.RegisterNetPanelJSONRequestBody{
+		Id:       p.id,
+		Name:     &name,
+		Firmware: &firmware,
+		Features: &features,
+	})
+	if err != nil {
+		return err
+	}
+	if resp.JSON200 == nil {
+		return responseError(resp.HTTPResponse, resp.Body)
+	}
+
+	p.lock.Lock()
+	p.session = resp.JSON200.Session
+	p.seq = 0
+	p.heartbeat = time.Duration(resp.JSON200.HeartbeatSeconds) * time.Second
+	p.pollWait = time.Duration(resp.JSON200.PollSeconds) * time.Second
+	p.lock.Unlock()
+	p.logger.WithFields(logrus.Fields{
+		"heartbeat": p.heartbeat,
+		"poll_wait": p.pollWait,
+	}).Info("Registered with the node")
+	p.received(resp.JSON200.Commands)
+	return nil
+}
+
+// sendHeartbeats reports the panel's readings every heartbeat, with the
+// Wi-Fi signal drifting as it would on a real panel.
+func (p *simPanel) sendHeartbeats(ctx context.Context) {
+	for {
+		p.lock.Lock()
+		interval := p.heartbeat
+		p.rssi += rand.Intn(5) - 2
+		if p.rssi > -30 {
+			p.rssi = -30
+		} else if p.rssi < -90 {
+			p.rssi = -90
+		}
+		uptime := int64(time.Since(p.started).Seconds())
+		hb := apiclient.NetPanelHeartbeatJSONRequestBody{
+			Session:       p.session,
+			Rssi:          p.rssi,
+			Dimmer:        p.dimmer,
+			UptimeSeconds: &uptime,
+		}
+		p.lock.Unlock()
+
+		reqCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+		resp, err := p.client.NetPanelHeartbeatWithResponse(reqCtx, p.id, hb)
+		cancel()
+		if err == nil && resp.JSON200 == nil {
+			err = responseError(resp.HTTPResponse, resp.Body)
+		}
+		switch {
+		case ctx.Err() != nil:
+			return
+		case errors.Is(err, errRegisterAgain):
+			return
+		case err != nil:
+			p.logger.WithError(err).Warn("Failed to send heartbeat")
+		default:
+			p.received(resp.JSON200.Commands)
+		}
+		if interval <= 0 {
+			interval = retryDelay
+		}
+		if !sleep(ctx, interval) {
+			return
+		}
+	}
+}
+
+// pollCommands keeps a poll for commands open with the node.
+func (p *simPanel) pollCommands(ctx context.Context) {
+	for {
+		p.lock.Lock()
+		session := p.session
+		wait := p.pollWait
+		p.lock.Unlock()
+
+		seconds := int(wait.Seconds())
+		reqCtx, cancel := context.WithTimeout(ctx, wait+requestTimeout)
+		resp, err := p.client.PollNetPanelWithResponse(reqCtx, p.id, &apiclient.PollNetPanelParams{
+			Session: session,
+			Wait:    &seconds,
+		})
+		cancel()
+		if err == nil && resp.JSON200 == nil {
+			err = responseError(resp.HTTPResponse, resp.Body)
+		}
+		switch {
+		case ctx.Err() != nil:
+			return
+		case errors.Is(err, errRegisterAgain):
+			return
+		case err != nil:
+			p.logger.WithError(err).Warn("Failed to poll for commands")
+			if !sleep(ctx, retryDelay) {
+				return
+			}
+		default:
+			p.received(resp.JSON200.Commands)
+		}
+	}
+}
+
+// press sends a button press to the node, and returns the feedback. A press
+// that fails to get through is sent again once with the same seq, as the
+// firmware does, and the node only acts on it once.
+func (p *simPanel) press(ctx context.Context, event apiclient.NetPanelEventType) ([]apiclient.NetPanelCommand, error) {
+	p.lock.Lock()
+	if p.session == "" {
+		p.lock.Unlock()
+		return nil, fmt.Errorf("panel %s isn't registered yet", p.id)
+	}
+	p.seq++
+	body := apiclient.NetPanelEventJSONRequestBody{Session: p.session, Seq: p.seq, Type: event}
+	p.events++
+	p.lock.Unlock()
+
+	var resp *apiclient.NetPanelEventResponse
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		reqCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+		resp, err = p.client.NetPanelEventWithResponse(reqCtx, p.id, body)
+		cancel()
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if resp.JSON200 == nil {
+		return nil, responseError(resp.HTTPResponse, resp.Body)
+	}
+	p.logger.WithField("event", event).Info("Sent event")
+	p.received(resp.JSON200.Commands)
+	return resp.JSON200.Commands, nil
+}
+
+// received records the commands from the node, and logs what a real panel
+// would play for them.
+func (p *simPanel) received(commands []apiclient.NetPanelCommand) {
+	if len(commands) == 0 {
+		return
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for _, c := range commands {
+		fields := logrus.Fields{"command": c.Type, "id": c.Id}
+		if c.Level != nil {
+			fields["level"] = *c.Level
+		}
+		p.logger.WithFields(fields).Info("Playing command")
+		p.commands = append(p.commands, c)
+	}
+}
+
+func (p *simPanel) setDimmer(value int) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.dimmer = value
+}
+
+// clearCommands forgets the commands received so far, between tests.
+func (p *simPanel) clearCommands() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.commands = []apiclient.NetPanelCommand{}
+}
+
+func (p *simPanel) state() PanelState {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return PanelState{
+		ID:         p.id,
+		Registered: p.session != "",
+		Session:    p.session,
+		RSSI:       p.rssi,
+		Dimmer:     p.dimmer,
+		Events:     p.events,
+		Commands:   append([]apiclient.NetPanelCommand{}, p.commands...),
+	}
+}
+
+// responseError describes an error response from the node. An unknown
+// session means the panel should register again.
+func responseError(resp *http.Response, body []byte) error {
+	if resp.StatusCode == http.StatusConflict {
+		return errRegisterAgain
+	}
+	return fmt.Errorf("the node returned %s: %s", resp.Status, body)
+}
+
+// sleep waits for d, and returns false if the context was cancelled first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
@@ -0,0 +1,376 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/klaital/wannetiot/pkg/apiclient"
+)
+
+// newFlags creates the flags for a command, which print its usage on -h.
+func newFlags(name, args string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: wannetctl %s %s\n", name, args)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// parseFlags parses a command's flags, allowing them after its arguments as
+// well as before, and returns the arguments.
+func parseFlags(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			if err == flag.ErrHelp {
+				return nil, err
+			}
+			return nil, errUsage
+		}
+		if flags.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+}
+
+// zonesFlag is a comma separated list of zones, such as desk,bed.
+type zonesFlag []string
+
+func (z *zonesFlag) String() string {
+	return strings.Join(*z, ",")
+}
+
+func (z *zonesFlag) Set(s string) error {
+	for _, zone := range strings.Split(s, ",") {
+		if zone = strings.TrimSpace(zone); zone != "" {
+			*z = append(*z, zone)
+		}
+	}
+	return nil
+}
+
+// param returns the zones as a query parameter, or nil for every zone.
+func (z zonesFlag) param() *apiclient.Zone {
+	if len(z) == 0 {
+		return nil
+	}
+	zones := apiclient.Zone(z)
+	return &zones
+}
+
+// lightScenes are the scenes the shortcut commands switch to.
+var lightScenes = map[string]string{
+	"on":  "full",
+	"off": "off",
+	"dim": "low",
+}
+
+func lightsCommand(ctx context.Context, c *cli, args []string) error {
+	flags := newFlags("lights", "[on|off|dim|scene <name>|fade <scene> <duration>] [-zone zones]")
+	var zones zonesFlag
+	flags.Var(&zones, "zone", "zones or zone groups to change, such as desk,bed")
+	args, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+
+	var update apiclient.LightsUpdate
+	switch {
+	case len(args) == 0:
+		var state apiclient.LightsState
+		resp, err := c.client.GetLights(ctx)
+		if err := decode(resp, err, &state); err != nil {
+			return err
+		}
+		return c.printLights(state)
+	case len(args) == 1 && lightScenes[args[0]] != "":
+		scene := lightScenes[args[0]]
+		update.Scene = &scene
+	case len(args) == 2 && args[0] == "scene":
+		update.Scene = &args[1]
+	case len(args) == 3 && args[0] == "fade":
+		if _, err := time.ParseDuration(args[2]); err != nil {
+			return fmt.Errorf("bad fade duration %q, such as 20m: %w", args[2], err)
+		}
+		update.Scene = &args[1]
+		update.Transition = &args[2]
+	default:
+		flags.Usage()
+		return errUsage
+	}
+
+	var state apiclient.LightsState
+	resp, err := c.client.PutLights(ctx, &apiclient.PutLightsParams{Zone: zones.param()}, update)
+	if err := decode(resp, err, &state); err != nil {
+		return err
+	}
+	return c.printLights(state)
+}
+
+func (c *cli) printLights(state apiclient.LightsState) error {
+	return c.print(state, func(w *tabwriter.Writer) {
+		row(w, "SETTING", state.Setting)
+		row(w, "OUTPUT", lightOutput(state.Output))
+		row(w, "POWER", fmt.Sprintf("%.1f W, %.2f kWh today", state.Power.Watts, state.Power.KwhToday))
+		if state.Effect != nil {
+			row(w, "EFFECT", state.Effect)
+		}
+		if state.Wakeup != nil {
+			row(w, "WAKEUP", progress(*state.Wakeup))
+		}
+		if state.Sleep != nil {
+			row(w, "SLEEP", progress(*state.Sleep))
+		}
+		if state.Ambient != nil {
+			row(w, "AMBIENT", fmt.Sprintf("%.0f lux", state.Ambient.Lux))
+		}
+		if len(state.Zones) > 1 {
+			row(w)
+			row(w, "ZONE", "TYPE", "OUTPUT")
+			for _, z := range state.Zones {
+				row(w, z.Name, z.Type, lightOutput(z.Output))
+			}
+		}
+	})
+}
+
+func lightOutput(l apiclient.LightConfig) string {
+	if l.Kelvin != nil && *l.Kelvin > 0 {
+		return fmt.Sprintf("%.0fK", *l.Kelvin)
+	}
+	var channels []string
+	for _, ch := range []struct {
+		name  string
+		value *int32
+	}{{"R", l.R}, {"G", l.G}, {"B", l.B}, {"W", l.W}} {
+		if ch.value != nil {
+			channels = append(channels, fmt.Sprintf("%s=%d", ch.name, *ch.value))
+		}
+	}
+	return strings.Join(channels, " ")
+}
+
+func progress(p apiclient.ProgressState) string {
+	s := fmt.Sprintf("%.0f%%", p.Progress*100)
+	if p.Remaining != nil {
+		s += ", " + *p.Remaining + " left"
+	}
+	if p.SnoozedUntil != nil {
+		s += ", snoozed until " + p.SnoozedUntil.Local().Format("15:04")
+	}
+	return s
+}
+
+func wakeupCommand(ctx context.Context, c *cli, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: wannetctl wakeup start|stop|alarms list|alarms add")
+		return errUsage
+	}
+	switch args[0] {
+	case "start":
+		flags := newFlags("wakeup start", "[-duration 30m] [-scene name] [-curve name] [-zone zones]")
+		var zones zonesFlag
+		flags.Var(&zones, "zone", "zones or zone groups to wake, such as desk,bed")
+		duration := flags.String("duration", "", "how long to fade up for, or the node's default")
+		scene := flags.String("scene", "", "scene to fade up to, or the node's default")
+		curve := flags.String("curve", "", "brightness curve, or the node's default")
+		if _, err := parseFlags(flags, args[1:]); err != nil {
+			return err
+		}
+		req := apiclient.WakeupRequest{
+			Duration: optional(*duration),
+			Scene:    optional(*scene),
+			Curve:    optional(*curve),
+		}
+		var state apiclient.LightsState
+		resp, err := c.client.StartWakeup(ctx, &apiclient.StartWakeupParams{Zone: zones.param()}, req)
+		if err := decode(resp, err, &state); err != nil {
+			return err
+		}
+		return c.printLights(state)
+	case "stop":
+		var state apiclient.LightsState
+		resp, err := c.client.DismissWakeup(ctx)
+		if err := decode(resp, err, &state); err != nil {
+			return err
+		}
+		return c.printLights(state)
+	case "alarms":
+		if len(args) > 1 && args[1] == "list" {
+			return c.listAlarms(ctx)
+		}
+		if len(args) > 1 && args[1] == "add" {
+			return c.addAlarm(ctx, args[2:])
+		}
+	}
+	fmt.Fprintln(os.Stderr, "Usage: wannetctl wakeup start|stop|alarms list|alarms add")
+	return errUsage
+}
+
+// optional returns nil for an empty flag, to leave it to the node.
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func (c *cli) listAlarms(ctx context.Context) error {
+	var alarms []apiclient.AlarmResponse
+	resp, err := c.client.ListAlarms(ctx)
+	if err := decode(resp, err, &alarms); err != nil {
+		return err
+	}
+	return c.print(alarms, func(w *tabwriter.Writer) {
+		row(w, "ID", "NAME", "TIME", "WHEN", "ENABLED", "NEXT")
+		for _, a := range alarms {
+			row(w, a.Id, a.Name, a.Time, alarmDays(a), a.Enabled, a.NextWakeup)
+		}
+	})
+}
+
+func alarmDays(a apiclient.AlarmResponse) string {
+	if a.Date != nil {
+		return *a.Date
+	}
+	if a.Days == nil || len(*a.Days) == 0 {
+		return "daily"
+	}
+	days := make([]string, len(*a.Days))
+	for i, d := range *a.Days {
+		days[i] = string(d)
+	}
+	return strings.Join(days, ",")
+}
+
+func (c *cli) addAlarm(ctx context.Context, args []string) error {
+	flags := newFlags("wakeup alarms add", "-name name -time HH:MM [-days mon,tue] [-date YYYY-MM-DD] [-scene name] [-zone zones]")
+	var zones zonesFlag
+	flags.Var(&zones, "zone", "zones or zone groups to wake, such as desk,bed")
+	name := flags.String("name", "", "the alarm's name")
+	at := flags.String("time", "", "local time the lights are fully up, as HH:MM")
+	days := flags.String("days", "", "days of the week, such as mon,tue, or every day")
+	date := flags.String("date", "", "date for a one-off alarm, as YYYY-MM-DD")
+	scene := flags.String("scene", "", "scene to fade up to, or the node's default")
+	disabled := flags.Bool("disabled", false, "add the alarm switched off")
+	if positional, err := parseFlags(flags, args); err != nil {
+		return err
+	} else if len(positional) > 0 || *name == "" || *at == "" {
+		flags.Usage()
+		return errUsage
+	}
+
+	enabled := !*disabled
+	alarm := apiclient.AlarmRequest{
+		Name:    *name,
+		Time:    *at,
+		Enabled: &enabled,
+		Date:    optional(*date),
+		Scene:   optional(*scene),
+	}
+	if *days != "" {
+		var list []string
+		for _, d := range strings.Split(*days, ",") {
+			list = append(list, strings.ToLower(strings.TrimSpace(d)))
+		}
+		alarm.Days = &list
+	}
+	if len(zones) > 0 {
+		list := []string(zones)
+		alarm.Zones = &list
+	}
+	var created apiclient.AlarmResponse
+	resp, err := c.client.CreateAlarm(ctx, alarm)
+	if err := decode(resp, err, &created); err != nil {
+		return err
+	}
+	return c.print(created, func(w *tabwriter.Writer) {
+		row(w, "ID", "NAME", "TIME", "WHEN", "ENABLED", "NEXT")
+		row(w, created.Id, created.Name, created.Time, alarmDays(created), created.Enabled, created.NextWakeup)
+	})
+}
+
+func sensorsCommand(ctx context.Context, c *cli, args []string) error {
+	if _, err := parseFlags(newFlags("sensors", ""), args); err != nil {
+		return err
+	}
+	var sensors apiclient.SensorsState
+	resp, err := c.client.GetSensors(ctx)
+	if err := decode(resp, err, &sensors); err != nil {
+		return err
+	}
+	return c.print(sensors, func(w *tabwriter.Writer) {
+		row(w, "SENSOR", "READING", "AT")
+		if a := sensors.Atmo; a != nil {
+			row(w, "temperature", fmt.Sprintf("%.1f°F", a.TemperatureF), a.At)
+			row(w, "humidity", fmt.Sprintf("%.1f%%", a.Humidity), a.At)
+		}
+		if d := sensors.Dust; d != nil {
+			row(w, "pm2.5", fmt.Sprintf("%.1f µg/m³", d.Pm25), d.At)
+			row(w, "pm10", fmt.Sprintf("%.1f µg/m³", d.Pm10), d.At)
+		}
+		if a := sensors.Ambient; a != nil {
+			row(w, "ambient", fmt.Sprintf("%.0f lux", a.Lux), nil)
+		}
+		row(w, "power", fmt.Sprintf("%.1f W", sensors.Power.Watts), nil)
+	})
+}
+
+func statusCommand(ctx context.Context, c *cli, args []string) error {
+	if _, err := parseFlags(newFlags("status", ""), args); err != nil {
+		return err
+	}
+	var status apiclient.NodeStatus
+	resp, err := c.client.GetStatus(ctx)
+	if err := decode(resp, err, &status); err != nil {
+		return err
+	}
+	err = c.print(status, func(w *tabwriter.Writer) {
+		row(w, "NODE", status.Node+" ("+c.address+")")
+		row(w, "STATUS", string(status.Status))
+		row(w, "READY", status.Ready)
+		row(w, "VERSION", status.Version+", go "+status.GoVersion)
+		row(w, "UPTIME", status.Uptime+", since "+cellString(status.StartedAt))
+		row(w)
+		row(w, "SUBSYSTEM", "STATUS", "CRITICAL", "LAST SUCCESS", "MESSAGE")
+		for _, s := range status.Subsystems {
+			msg := s.Message
+			if s.LastError != nil {
+				msg = s.LastError
+			}
+			row(w, s.Name, string(s.Status), s.Critical, s.LastSuccess, msg)
+		}
+	})
+	if err == nil && !status.Ready {
+		// Let scripts tell when the node isn't ready
+		return fmt.Errorf("%s is not ready", status.Node)
+	}
+	return err
+}
+
+func pageCommand(ctx context.Context, c *cli, args []string) error {
+	if _, err := parseFlags(newFlags("page", ""), args); err != nil {
+		return err
+	}
+	var result apiclient.StatusResponse
+	resp, err := c.client.Page(ctx)
+	if err := decode(resp, err, &result); err != nil {
+		return err
+	}
+	return c.print(result, func(w *tabwriter.Writer) {
+		switch result.Status {
+		case "already_paged":
+			row(w, "Already paged, added to the last page")
+		default:
+			row(w, "Paged")
+		}
+	})
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/klaital/wannetiot/pkg/apiclient"
+)
+
+// streamEvent is an event from the stream, with its data kept as it came.
+type streamEvent struct {
+	ID   string          `json:"id"`
+	Type string          `json:"type"`
+	Time time.Time       `json:"time"`
+	Data json.RawMessage `json:"data"`
+}
+
+// errStreamRefused is returned when retrying wouldn't help, such as without
+// the right token.
+var errStreamRefused = errors.New("the node refused the event stream")
+
+// reconnectDelay is how long to wait before following the events again,
+// after the stream ends.
+const reconnectDelay = 3 * time.Second
+
+func eventsCommand(ctx context.Context, c *cli, args []string) error {
+	flags := newFlags("events", "[-follow] [-type pager,sensor] [-wait 2s]")
+	follow := flags.Bool("follow", false, "keep following new events, until interrupted")
+	types := flags.String("type", "", "event types to show, such as pager,sensor, or every type")
+	wait := flags.Duration("wait", 2*time.Second, "without -follow, how long to wait for more events")
+	if positional, err := parseFlags(flags, args); err != nil {
+		return err
+	} else if len(positional) > 0 {
+		flags.Usage()
+		return errUsage
+	}
+
+	params := apiclient.StreamEventsParams{}
+	if *types != "" {
+		var list []apiclient.StreamEventsParamsType
+		for _, t := range strings.Split(*types, ",") {
+			list = append(list, apiclient.StreamEventsParamsType(strings.TrimSpace(t)))
+		}
+		params.Type = &list
+	}
+	// An unknown ID gets every event the node still has
+	lastID := "0"
+	if !*follow {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		// Allow for connecting, then wait for the events to stop coming
+		idle := time.AfterFunc(c.cfg.Timeout, cancel)
+		defer idle.Stop()
+		err := c.streamEvents(ctx, params, lastID, func() {
+			idle.Reset(*wait)
+		}, func(e streamEvent) {
+			idle.Reset(*wait)
+			c.printEvent(e)
+		})
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+
+	for {
+		err := c.streamEvents(ctx, params, lastID, func() {}, func(e streamEvent) {
+			lastID = e.ID
+			c.printEvent(e)
+		})
+		if ctx.Err() != nil {
+			return nil
+		}
+		if errors.Is(err, errStreamRefused) {
+			return err
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "wannetctl: %v, reconnecting\n", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+// streamEvents reads the event stream until it ends, passing each event on.
+// onOpen is called once the node has started the stream.
+func (c *cli) streamEvents(ctx context.Context, params apiclient.StreamEventsParams, lastID string, onOpen func(), onEvent func(e streamEvent)) error {
+	params.LastEventID = &lastID
+	resp, err := c.stream.StreamEvents(ctx, &params)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		err := decode(resp, nil, nil)
+		if resp.StatusCode < http.StatusInternalServerError && resp.StatusCode != http.StatusTooManyRequests {
+			return fmt.Errorf("%w: %v", errStreamRefused, err)
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	onOpen()
+
+	var eventType string
+	var data []string
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), maxResponse)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			// A blank line ends the event. Resyncs need nothing from us, as
+			// every event the node has follows anyway.
+			if len(data) > 0 && eventType != "resync" {
+				var e streamEvent
+				if err := json.Unmarshal([]byte(strings.Join(data, "\n")), &e); err != nil {
+					return fmt.Errorf("reading an event: %w", err)
+				}
+				onEvent(e)
+			}
+			eventType, data = "", nil
+		case strings.HasPrefix(line, ":"):
+			// A heartbeat
+		case strings.HasPrefix(line, "event:"):
+			eventType = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("the event stream ended")
+}
+
+// printEvent writes an event as one line, so the output can be piped on:
+// as JSON with -o json, or else with its time and type first.
+func (c *cli) printEvent(e streamEvent) {
+	if c.cfg.Output == "json" {
+		line, _ := json.Marshal(e)
+		fmt.Println(string(line))
+		return
+	}
+	fmt.Printf("%s  %-7s %s\n", e.Time.Local().Format("2006-01-02 15:04:05"), e.Type, e.Data)
+}
@@ -0,0 +1,254 @@
+// wannetctl controls and inspects the nodes over their HTTP API, for people
+// at a terminal as well as scripts and cron jobs.
+//
+// The node and token can be given as flags, or as environment variables,
+// which are also read from the wannetctl/env file in the user's config
+// directory, such as ~/.config/wannetctl/env:
+//
+//	WANNETCTL_NODE=bedroom
+//	WANNETCTL_NODES=bedroom=http://bedroom:8080,office=https://office:8443
+//	WANNETCTL_TOKENS=bedroom=...,office=...
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/caarlos0/env/v6"
+	"github.com/joho/godotenv"
+
+	"github.com/klaital/wannetiot/pkg/apiclient"
+)
+
+// version is set when building a release, with
+// -ldflags "-X main.version=v1.2.3".
+var version = "dev"
+
+// Config is where to find the node, and how to talk to it.
+type Config struct {
+	// Node is the name of one of Nodes, or the address of a node, such as
+	// "bedroom:8080" or "https://bedroom:8443".
+	Node string `env:"WANNETCTL_NODE" envDefault:"bedroom"`
+	// Nodes names the nodes' addresses, as "name=address".
+	Nodes []string `env:"WANNETCTL_NODES" envDefault:"bedroom=http://bedroom:8080"`
+	// Token is sent to any node without one of its own in Tokens, as
+	// "name=token". The -token flag is sent whatever the node.
+	Token  string   `env:"WANNETCTL_TOKEN"`
+	Tokens []string `env:"WANNETCTL_TOKENS"`
+	// For nodes that serve the API over TLS. CA verifies the node's certificate,
+	// and Cert and Key are a client certificate to use instead of a token.
+	CA   string `env:"WANNETCTL_CA"`
+	Cert string `env:"WANNETCTL_CERT"`
+	Key  string `env:"WANNETCTL_KEY"`
+	// Output is table or json.
+	Output  string        `env:"WANNETCTL_OUTPUT" envDefault:"table"`
+	Timeout time.Duration `env:"WANNETCTL_TIMEOUT" envDefault:"10s"`
+}
+
+// errUsage is returned for bad arguments, after printing the usage.
+var errUsage = errors.New("usage")
+
+const usage = `Usage: wannetctl [flags] <command> [args]
+
+Commands:
+  lights                          show the lights
+  lights on|off|dim               switch to the full, off or low scene
+  lights scene <name>             fade to a scene
+  lights fade <scene> <duration>  fade to a scene over the duration, such as 20m
+  wakeup start                    start a wakeup now
+  wakeup stop                     stop the wakeup and its alarm
+  wakeup alarms list              list the wakeup alarms
+  wakeup alarms add               add a wakeup alarm
+  sensors                         show the latest sensor readings
+  status                          show the node's health
+  page                            page whoever is in the bedroom
+  events [-follow]                show the recent events, or keep following them
+
+Run "wannetctl <command> -h" for the command's flags.
+
+Flags:
+`
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		if err == flag.ErrHelp {
+			return
+		}
+		if err == errUsage {
+			os.Exit(2)
+		}
+		fmt.Fprintln(os.Stderr, "wannetctl:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if dir, err := os.UserConfigDir(); err == nil {
+		// Variables that are already set win over the file
+		if err := godotenv.Load(filepath.Join(dir, "wannetctl", "env")); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("reading the config file: %w", err)
+		}
+	}
+	var cfg Config
+	if err := env.Parse(&cfg); err != nil {
+		return err
+	}
+
+	flags := flag.NewFlagSet("wannetctl", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+	flags.StringVar(&cfg.Node, "node", cfg.Node, "node name or address")
+	flags.StringVar(&cfg.Token, "token", cfg.Token, "API token")
+	flags.StringVar(&cfg.Output, "o", cfg.Output, "output format, table or json")
+	flags.DurationVar(&cfg.Timeout, "timeout", cfg.Timeout, "how long to wait for the node")
+	flags.StringVar(&cfg.CA, "ca", cfg.CA, "CA certificate to verify the node with")
+	flags.StringVar(&cfg.Cert, "cert", cfg.Cert, "client certificate")
+	flags.StringVar(&cfg.Key, "key", cfg.Key, "client certificate key")
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return err
+		}
+		return errUsage
+	}
+	flags.Visit(func(f *flag.Flag) {
+		// A token given on the command line is for this node, like -node is
+		if f.Name == "token" {
+			cfg.Tokens = nil
+		}
+	})
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errUsage
+	}
+	if cfg.Output != "table" && cfg.Output != "json" {
+		return fmt.Errorf("unknown output format %q - valid options: table, json", cfg.Output)
+	}
+
+	cmd, ok := commands[flags.Arg(0)]
+	if !ok {
+		flags.Usage()
+		return errUsage
+	}
+	c, err := newCLI(cfg)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	return cmd(ctx, c, flags.Args()[1:])
+}
+
+// commands are the top level commands, by name.
+var commands = map[string]func(ctx context.Context, c *cli, args []string) error{
+	"lights":  lightsCommand,
+	"wakeup":  wakeupCommand,
+	"sensors": sensorsCommand,
+	"status":  statusCommand,
+	"page":    pageCommand,
+	"events":  eventsCommand,
+}
+
+// cli holds the client for the node the commands talk to.
+type cli struct {
+	cfg     Config
+	node    string
+	address string
+	client  *apiclient.Client
+	// stream has no timeout, for following events
+	stream *apiclient.Client
+}
+
+func newCLI(cfg Config) (*cli, error) {
+	name, address := resolveNode(cfg)
+	token := cfg.Token
+	for _, spec := range cfg.Tokens {
+		if kv := strings.SplitN(spec, "=", 2); len(kv) == 2 && strings.TrimSpace(kv[0]) == name {
+			token = strings.TrimSpace(kv[1])
+		}
+	}
+	transport, err := newTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+	editor := func(ctx context.Context, req *http.Request) error {
+		req.Header.Set("User-Agent", "wannetctl/"+version)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		return nil
+	}
+
+	c := &cli{cfg: cfg, node: name, address: address}
+	c.client, err = apiclient.NewClient(address,
+		apiclient.WithHTTPClient(&http.Client{Transport: transport, Timeout: cfg.Timeout}),
+		apiclient.WithRequestEditorFn(editor))
+	if err != nil {
+		return nil, err
+	}
+	c.stream, err = apiclient.NewClient(address,
+		apiclient.WithHTTPClient(&http.Client{Transport: transport}),
+		apiclient.WithRequestEditorFn(editor))
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// resolveNode looks the node up by name, or else takes it as an address.
+// Addresses without a scheme use http, and without a port use 8080.
+func resolveNode(cfg Config) (name, address string) {
+	for _, spec := range cfg.Nodes {
+		if kv := strings.SplitN(spec, "=", 2); len(kv) == 2 && strings.TrimSpace(kv[0]) == cfg.Node {
+			return cfg.Node, strings.TrimSpace(kv[1])
+		}
+	}
+	address = cfg.Node
+	if !strings.Contains(address, "://") {
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			address = net.JoinHostPort(address, "8080")
+		}
+		address = "http://" + address
+	}
+	return cfg.Node, address
+}
+
+func newTransport(cfg Config) (http.RoundTripper, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.CA == "" && cfg.Cert == "" {
+		return transport, nil
+	}
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.CA != "" {
+		pem, err := os.ReadFile(cfg.CA)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", cfg.CA)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if cfg.Cert != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.Cert, cfg.Key)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/klaital/wannetiot/pkg/apiclient"
+)
+
+// maxResponse limits how much of a response is read.
+const maxResponse = 4 << 20
+
+// decode reads a response into out, or turns the node's error object into
+// an error.
+func decode(resp *http.Response, err error, out interface{}) error {
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponse))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		var failure apiclient.ErrorResponse
+		if json.Unmarshal(body, &failure) == nil && failure.Error.Message != "" {
+			msg := fmt.Sprintf("%s: %s (%s)", resp.Status, failure.Error.Message, failure.Error.Code)
+			if retry := resp.Header.Get("Retry-After"); retry != "" {
+				msg += ", retry after " + retry + "s"
+			}
+			return fmt.Errorf("%s", msg)
+		}
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("reading the node's response: %w", err)
+	}
+	return nil
+}
+
+// print writes v as indented JSON with -o json, or else as a table.
+func (c *cli) print(v interface{}, table func(w *tabwriter.Writer)) error {
+	if c.cfg.Output == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	table(w)
+	return w.Flush()
+}
+
+// row writes the cells as one line of a table.
+func row(w io.Writer, cells ...interface{}) {
+	strs := make([]string, len(cells))
+	for i, cell := range cells {
+		strs[i] = cellString(cell)
+	}
+	fmt.Fprintln(w, strings.Join(strs, "\t"))
+}
+
+func cellString(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "-"
+	case string:
+		if v == "" {
+			return "-"
+		}
+		return v
+	case *string:
+		if v == nil {
+			return "-"
+		}
+		return cellString(*v)
+	case time.Time:
+		if v.IsZero() {
+			return "-"
+		}
+		return v.Local().Format("2006-01-02 15:04:05")
+	case *time.Time:
+		if v == nil {
+			return "-"
+		}
+		return cellString(*v)
+	case float64:
+		return fmt.Sprintf("%.1f", v)
+	case *float64:
+		if v == nil {
+			return "-"
+		}
+		return cellString(*v)
+	case bool:
+		if v {
+			return "yes"
+		}
+		return "no"
+	case *bool:
+		if v == nil {
+			return "-"
+		}
+		return cellString(*v)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,173 @@
+// Package alarms schedules recurring and one-off wakeup alarms.
+// Each alarm names the local time at which the lights should reach full
+// brightness; the wakeup sequence is started one lead time earlier.
+package alarms
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Alarm is a named wakeup alarm.
+type Alarm struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Time is the local time of day, as "HH:MM", at which the lights should be at full brightness.
+	Time string `json:"time"`
+	// Days lists the days of the week the alarm repeats on, such as "mon". Empty means every day.
+	Days []string `json:"days,omitempty"`
+	// Date makes this a one-off alarm on the given day, as "YYYY-MM-DD". It is disabled once it has fired.
+	Date    string `json:"date,omitempty"`
+	Enabled bool   `json:"enabled"`
+	// SkipNext suppresses the next occurrence only. It is cleared when that occurrence passes.
+	SkipNext bool `json:"skip_next"`
+	// Curve selects the wakeup curve to use, such as "sunrise". Empty uses the configured default.
+	Curve string `json:"curve,omitempty"`
+	// Scene is the ID of the scene the lights wake up to. Empty uses full brightness.
+	Scene string `json:"scene,omitempty"`
+	// Finale sounds the audible alarm once the lights are up. Nil uses the configured default.
+	Finale *bool `json:"finale,omitempty"`
+	// FinaleMax is how long the audible alarm plays for without being dismissed, such as "5m".
+	FinaleMax string `json:"finale_max,omitempty"`
+	// Effect is a light effect spec played as the audible alarm starts, such as "flash,count=5".
+	Effect string `json:"effect,omitempty"`
+	// Zones lists the zones or groups to wake up. Empty wakes up all of them.
+	Zones []string `json:"zones,omitempty"`
+	// LastFired records the last occurrence that was handled, so that it is never handled twice.
+	LastFired time.Time `json:"last_fired,omitempty"`
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+var ErrInvalidAlarm = errors.New("invalid alarm")
+
+var timeOfDay = regexp.MustCompile(`^([01]?[0-9]|2[0-3]):([0-5][0-9])$`)
+
+// Validate checks the alarm's fields, normalizing the day names to lower case.
+func (a *Alarm) Validate() error {
+	if strings.TrimSpace(a.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidAlarm)
+	}
+	if !timeOfDay.MatchString(a.Time) {
+		return fmt.Errorf("%w: time must be HH:MM", ErrInvalidAlarm)
+	}
+	for i := range a.Days {
+		a.Days[i] = strings.ToLower(a.Days[i])
+		if len(a.Days[i]) > 3 {
+			a.Days[i] = a.Days[i][:3]
+		}
+		if _, ok := weekdays[a.Days[i]]; !ok {
+			return fmt.Errorf("%w: unknown day %q", ErrInvalidAlarm, a.Days[i])
+		}
+	}
+	if a.Date != "" {
+		if _, err := time.Parse("2006-01-02", a.Date); err != nil {
+			return fmt.Errorf("%w: date must be YYYY-MM-DD", ErrInvalidAlarm)
+		}
+	}
+	if a.FinaleMax != "" {
+		if d, err := time.ParseDuration(a.FinaleMax); err != nil || d <= 0 {
+			return fmt.Errorf("%w: finale_max must be a duration such as 5m", ErrInvalidAlarm)
+		}
+	}
+	return nil
+}
+
+// FinaleMaxDuration parses FinaleMax, returning 0 if it isn't set.
+func (a *Alarm) FinaleMaxDuration() time.Duration {
+	d, _ := time.ParseDuration(a.FinaleMax)
+	return d
+}
+
+// clock returns the alarm's hour and minute.
+func (a *Alarm) clock() (hour, minute int) {
+	m := timeOfDay.FindStringSubmatch(a.Time)
+	if m == nil {
+		return 0, 0
+	}
+	fmt.Sscanf(m[1], "%d", &hour)
+	fmt.Sscanf(m[2], "%d", &minute)
+	return hour, minute
+}
+
+// repeatsOn reports whether a recurring alarm is scheduled on the given day.
+func (a *Alarm) repeatsOn(day time.Weekday) bool {
+	if len(a.Days) == 0 {
+		return true
+	}
+	for _, d := range a.Days {
+		if weekdays[d] == day {
+			return true
+		}
+	}
+	return false
+}
+
+// NextOccurrence finds the first time strictly after `after` at which the
+// alarm goes off, in the given location. Each candidate is built from the
+// calendar date and wall clock time, so it stays at the same local time
+// across DST changes.
+// Returns false if the alarm will never go off again.
+func (a *Alarm) NextOccurrence(after time.Time, loc *time.Location) (time.Time, bool) {
+	hour, minute := a.clock()
+	after = after.In(loc)
+
+	if a.Date != "" {
+		d, err := time.ParseInLocation("2006-01-02", a.Date, loc)
+		if err != nil {
+			return time.Time{}, false
+		}
+		t := localTime(d.Year(), d.Month(), d.Day(), hour, minute, loc)
+		if !t.After(after) || a.firedOn(t) {
+			return time.Time{}, false
+		}
+		return t, true
+	}
+
+	// Look a little over a week ahead, so every weekday is considered
+	for i := 0; i <= 8; i++ {
+		t := localTime(after.Year(), after.Month(), after.Day()+i, hour, minute, loc)
+		if !t.After(after) || !a.repeatsOn(t.Weekday()) || a.firedOn(t) {
+			continue
+		}
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+// localTime builds the instant at the given wall clock time. A time that
+// doesn't exist because the clocks go forward that night is moved forward by
+// the length of the gap, so 02:30 becomes 03:30. A time that happens twice
+// when the clocks go back resolves to just one of them.
+func localTime(year int, month time.Month, day, hour, minute int, loc *time.Location) time.Time {
+	t := time.Date(year, month, day, hour, minute, 0, 0, loc)
+	wanted := time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute
+	actual := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	if actual < wanted {
+		t = t.Add(wanted - actual)
+	}
+	return t
+}
+
+// firedOn reports whether the occurrence at t has already been handled.
+func (a *Alarm) firedOn(t time.Time) bool {
+	return !a.LastFired.IsZero() && !t.After(a.LastFired)
+}
+
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slug generates an ID from an alarm's name.
+func slug(name string) string {
+	return strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
@@ -0,0 +1,169 @@
+package alarms
+
+import (
+	"testing"
+	"time"
+)
+
+func newYork(t *testing.T) *time.Location {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("no time zone data: %v", err)
+	}
+	return loc
+}
+
+func TestNextOccurrence(t *testing.T) {
+	ny := newYork(t)
+	at := func(year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, ny)
+	}
+	// Wednesday 2 June 2021
+	wed := at(2021, 6, 2, 12, 0)
+
+	tests := []struct {
+		name   string
+		alarm  Alarm
+		after  time.Time
+		want   time.Time
+		wantOK bool
+	}{
+		{
+			name:   "later today",
+			alarm:  Alarm{Time: "18:30"},
+			after:  wed,
+			want:   at(2021, 6, 2, 18, 30),
+			wantOK: true,
+		},
+		{
+			name:   "tomorrow",
+			alarm:  Alarm{Time: "07:00"},
+			after:  wed,
+			want:   at(2021, 6, 3, 7, 0),
+			wantOK: true,
+		},
+		{
+			name:   "strictly after",
+			alarm:  Alarm{Time: "12:00"},
+			after:  wed,
+			want:   at(2021, 6, 3, 12, 0),
+			wantOK: true,
+		},
+		{
+			name:   "weekdays from a Friday evening",
+			alarm:  Alarm{Time: "07:00", Days: []string{"mon", "tue", "wed", "thu", "fri"}},
+			after:  at(2021, 6, 4, 20, 0),
+			want:   at(2021, 6, 7, 7, 0),
+			wantOK: true,
+		},
+		{
+			name:   "one day a week, on that day after the time",
+			alarm:  Alarm{Time: "07:00", Days: []string{"wed"}},
+			after:  wed,
+			want:   at(2021, 6, 9, 7, 0),
+			wantOK: true,
+		},
+		{
+			name:   "already fired today",
+			alarm:  Alarm{Time: "18:30", LastFired: at(2021, 6, 2, 18, 30)},
+			after:  wed,
+			want:   at(2021, 6, 3, 18, 30),
+			wantOK: true,
+		},
+		{
+			name:   "one-off",
+			alarm:  Alarm{Time: "09:15", Date: "2021-06-10"},
+			after:  wed,
+			want:   at(2021, 6, 10, 9, 15),
+			wantOK: true,
+		},
+		{
+			name:  "one-off in the past",
+			alarm: Alarm{Time: "09:15", Date: "2021-06-01"},
+			after: wed,
+		},
+		{
+			name:  "one-off that has fired",
+			alarm: Alarm{Time: "09:15", Date: "2021-06-10", LastFired: at(2021, 6, 10, 9, 15)},
+			after: wed,
+		},
+		{
+			name:  "one-off doesn't recur after its time",
+			alarm: Alarm{Time: "09:15", Date: "2021-06-10"},
+			after: at(2021, 6, 10, 9, 15),
+		},
+		{
+			// The clocks go from 02:00 to 03:00, so 02:30 doesn't exist
+			name:   "in the spring forward gap",
+			alarm:  Alarm{Time: "02:30"},
+			after:  at(2021, 3, 13, 12, 0),
+			want:   time.Date(2021, 3, 14, 7, 30, 0, 0, time.UTC), // 03:30 EDT
+			wantOK: true,
+		},
+		{
+			name:   "after the spring forward",
+			alarm:  Alarm{Time: "07:00"},
+			after:  at(2021, 3, 13, 12, 0),
+			want:   time.Date(2021, 3, 14, 11, 0, 0, 0, time.UTC), // 07:00 EDT
+			wantOK: true,
+		},
+		{
+			// The clocks go from 02:00 back to 01:00, so 01:30 happens twice
+			name:   "in the fall back overlap",
+			alarm:  Alarm{Time: "01:30"},
+			after:  at(2021, 11, 6, 12, 0),
+			want:   time.Date(2021, 11, 7, 5, 30, 0, 0, time.UTC), // 01:30 EDT
+			wantOK: true,
+		},
+		{
+			name:   "not again in the repeated hour",
+			alarm:  Alarm{Time: "01:30"},
+			after:  time.Date(2021, 11, 7, 5, 45, 0, 0, time.UTC), // 01:45 EDT
+			want:   at(2021, 11, 8, 1, 30),
+			wantOK: true,
+		},
+		{
+			name:   "after the fall back",
+			alarm:  Alarm{Time: "07:00"},
+			after:  at(2021, 11, 6, 12, 0),
+			want:   time.Date(2021, 11, 7, 12, 0, 0, 0, time.UTC), // 07:00 EST
+			wantOK: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.alarm.NextOccurrence(tt.after, ny)
+			if ok != tt.wantOK {
+				t.Fatalf("got ok %v, want %v (at %v)", ok, tt.wantOK, got)
+			}
+			if ok && !got.Equal(tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want.In(ny))
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		alarm Alarm
+		valid bool
+	}{
+		{"minimal", Alarm{Name: "a", Time: "7:05"}, true},
+		{"full day names", Alarm{Name: "a", Time: "07:05", Days: []string{"Monday", "TUE"}}, true},
+		{"no name", Alarm{Time: "07:05"}, false},
+		{"no time", Alarm{Name: "a"}, false},
+		{"time out of range", Alarm{Name: "a", Time: "24:00"}, false},
+		{"unknown day", Alarm{Name: "a", Time: "07:05", Days: []string{"someday"}}, false},
+		{"bad date", Alarm{Name: "a", Time: "07:05", Date: "June 1st"}, false},
+		{"bad finale_max", Alarm{Name: "a", Time: "07:05", FinaleMax: "-5m"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.alarm.Validate()
+			if (err == nil) != tt.valid {
+				t.Errorf("got %v, want valid %v", err, tt.valid)
+			}
+		})
+	}
+}
@@ -0,0 +1,294 @@
+package alarms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/klaital/wannetiot/pkg/health"
+	"github.com/klaital/wannetiot/pkg/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// Trigger is called when it is time to start an alarm's wakeup sequence,
+// which should finish when the alarm is due. That is sooner than the lead
+// time for an alarm set or switched on just before it goes off.
+type Trigger func(a Alarm, due time.Time)
+
+// Scheduler keeps the set of alarms, persists them to a local JSON file, and
+// triggers each one a lead time before it is due.
+type Scheduler struct {
+	path    string
+	lead    time.Duration
+	loc     *time.Location
+	trigger Trigger
+	logger  *log.Entry
+
+	lock   sync.Mutex
+	alarms map[string]*Alarm
+	// changed wakes the Run loop to recalculate when the alarms are edited
+	changed chan struct{}
+}
+
+var ErrNotFound = errors.New("alarm not found")
+var ErrDuplicateID = errors.New("an alarm with that ID already exists")
+
+// New loads the alarms saved at path, if any. The wakeup for each alarm is
+// triggered `lead` before the alarm time, in the given location.
+func New(path string, lead time.Duration, loc *time.Location, trigger Trigger, logger *log.Entry) (*Scheduler, error) {
+	if logger == nil {
+		logger = log.NewEntry(log.New())
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+	s := &Scheduler{
+		path:    path,
+		lead:    lead,
+		loc:     loc,
+		trigger: trigger,
+		logger:  logger.WithField("op", "alarms.Scheduler"),
+		alarms:  make(map[string]*Alarm),
+		changed: make(chan struct{}, 1),
+	}
+
+	saved := make([]Alarm, 0)
+	if err := util.ReadJSON(path, &saved); err != nil {
+		return nil, err
+	}
+	for i := range saved {
+		a := saved[i]
+		if err := a.Validate(); err != nil {
+			s.logger.WithError(err).WithField("alarm", a.ID).Error("Skipping invalid saved alarm")
+			continue
+		}
+		s.alarms[a.ID] = &a
+	}
+	s.logger.WithField("count", len(s.alarms)).Debug("Loaded alarms")
+	return s, nil
+}
+
+// save writes the alarms to disk. The lock must be held.
+func (s *Scheduler) save() error {
+	if s.path == "" {
+		return nil
+	}
+	return util.WriteJSONAtomic(s.path, s.sorted())
+}
+
+// sorted lists the alarms ordered by ID. The lock must be held.
+func (s *Scheduler) sorted() []Alarm {
+	list := make([]Alarm, 0, len(s.alarms))
+	for _, a := range s.alarms {
+		c := *a
+		c.Days = append([]string(nil), a.Days...)
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// notify wakes the Run loop to recalculate the next alarm.
+func (s *Scheduler) notify() {
+	select {
+	case s.changed <- struct{}{}:
+	default:
+	}
+}
+
+// List returns a copy of all alarms.
+func (s *Scheduler) List() []Alarm {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.sorted()
+}
+
+// Get returns a copy of one alarm.
+func (s *Scheduler) Get(id string) (Alarm, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	a, ok := s.alarms[id]
+	if !ok {
+		return Alarm{}, ErrNotFound
+	}
+	return *a, nil
+}
+
+// Create adds a new alarm. If no ID is given, one is generated from the name,
+// which must have a letter or digit in it.
+func (s *Scheduler) Create(a Alarm) (Alarm, error) {
+	if err := a.Validate(); err != nil {
+		return Alarm{}, err
+	}
+	if a.ID == "" {
+		a.ID = slug(a.Name)
+		if a.ID == "" {
+			return Alarm{}, fmt.Errorf("%w: the name needs a letter or digit to make an ID from, or give an id", ErrInvalidAlarm)
+		}
+	}
+	a.LastFired = time.Time{}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, exists := s.alarms[a.ID]; exists {
+		return Alarm{}, ErrDuplicateID
+	}
+	s.alarms[a.ID] = &a
+	defer s.notify()
+	return a, s.save()
+}
+
+// Update replaces the alarm with the given ID. The ID cannot be changed.
+func (s *Scheduler) Update(id string, a Alarm) (Alarm, error) {
+	if err := a.Validate(); err != nil {
+		return Alarm{}, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	old, ok := s.alarms[id]
+	if !ok {
+		return Alarm{}, ErrNotFound
+	}
+	a.ID = id
+	a.LastFired = old.LastFired
+	s.alarms[id] = &a
+	defer s.notify()
+	return a, s.save()
+}
+
+// Delete removes an alarm.
+func (s *Scheduler) Delete(id string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.alarms[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.alarms, id)
+	defer s.notify()
+	return s.save()
+}
+
+// SkipNext sets or clears the flag that skips the alarm's next occurrence.
+func (s *Scheduler) SkipNext(id string, skip bool) (Alarm, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	a, ok := s.alarms[id]
+	if !ok {
+		return Alarm{}, ErrNotFound
+	}
+	a.SkipNext = skip
+	defer s.notify()
+	return *a, s.save()
+}
+
+// NextWakeup reports when the alarm's next wakeup sequence will start, and
+// when the alarm itself is due. An alarm due within the lead time starts
+// its wakeup now, rather than waiting for the next day.
+func (s *Scheduler) NextWakeup(a Alarm, now time.Time) (start, due time.Time, ok bool) {
+	if !a.Enabled {
+		return time.Time{}, time.Time{}, false
+	}
+	due, ok = a.NextOccurrence(now, s.loc)
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	start = due.Add(-s.lead)
+	if start.Before(now) {
+		start = now
+	}
+	return start, due, true
+}
+
+// Run triggers alarms as they come due, until the context is cancelled.
+// Rather than sleeping until the next alarm, it re-checks at least once a
+// minute against the wall clock, so that clock adjustments and DST
+// changes are picked up.
+func (s *Scheduler) Run(ctx context.Context) {
+	s.logger.Info("Starting background job: alarm scheduler")
+	// It wakes up at least once a minute, so it's stuck if it hasn't for a while
+	loop := health.NewLoop(3 * time.Minute)
+	health.Register("alarms", true, loop)
+	loop.Start()
+	defer loop.Stop()
+	lastCheck := time.Now()
+	for {
+		loop.Beat()
+		wait := time.Minute
+		s.lock.Lock()
+		for _, a := range s.alarms {
+			if start, _, ok := s.NextWakeup(*a, lastCheck); ok {
+				if d := time.Until(start); d < wait {
+					wait = d
+				}
+			}
+		}
+		s.lock.Unlock()
+		if wait < 0 {
+			wait = 0
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			s.logger.Info("Halting alarm scheduler")
+			return
+		case <-s.changed:
+			timer.Stop()
+		case <-timer.C:
+		}
+
+		now := time.Now()
+		s.fireDue(lastCheck, now)
+		lastCheck = now
+	}
+}
+
+// fireDue handles every alarm whose wakeup was due to start in (from, to].
+func (s *Scheduler) fireDue(from, to time.Time) {
+	type dueAlarm struct {
+		alarm Alarm
+		at    time.Time
+	}
+	s.lock.Lock()
+	due := make([]dueAlarm, 0)
+	dirty := false
+	for _, a := range s.alarms {
+		start, at, ok := s.NextWakeup(*a, from)
+		if !ok || start.After(to) {
+			continue
+		}
+		logger := s.logger.WithFields(log.Fields{
+			"alarm": a.ID,
+			"due":   at.Format(time.RFC3339),
+		})
+		a.LastFired = at
+		dirty = true
+		if a.Date != "" {
+			a.Enabled = false
+		}
+		if a.SkipNext {
+			logger.Info("Skipping alarm occurrence")
+			a.SkipNext = false
+			continue
+		}
+		logger.Info("Alarm due, starting wakeup")
+		due = append(due, dueAlarm{*a, at})
+	}
+	if dirty {
+		if err := s.save(); err != nil {
+			s.logger.WithError(err).Error("Failed to save alarms")
+		}
+	}
+	s.lock.Unlock()
+
+	for _, d := range due {
+		if s.trigger != nil {
+			s.trigger(d.alarm, d.at)
+		}
+	}
+}
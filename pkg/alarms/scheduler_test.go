@@ -0,0 +1,209 @@
+package alarms
+
+import (
+	"testing"
+	"time"
+)
+
+// fired records the alarms a scheduler triggers.
+type fired struct {
+	ids []string
+	due []time.Time
+}
+
+func (f *fired) trigger(a Alarm, due time.Time) {
+	f.ids = append(f.ids, a.ID)
+	f.due = append(f.due, due)
+}
+
+func newTestScheduler(t *testing.T, lead time.Duration, alarms ...Alarm) (*Scheduler, *fired) {
+	f := &fired{}
+	s, err := New("", lead, newYork(t), f.trigger, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range alarms {
+		if _, err = s.Create(a); err != nil {
+			t.Fatalf("creating %s: %v", a.ID, err)
+		}
+	}
+	return s, f
+}
+
+func TestNextWakeup(t *testing.T) {
+	ny := newYork(t)
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2021, 6, day, hour, minute, 0, 0, ny)
+	}
+	const lead = 30 * time.Minute
+
+	tests := []struct {
+		name      string
+		alarm     Alarm
+		now       time.Time
+		wantStart time.Time
+		wantDue   time.Time
+		wantOK    bool
+	}{
+		{
+			name:      "before the lead window",
+			alarm:     Alarm{Time: "07:00", Enabled: true},
+			now:       at(2, 6, 0),
+			wantStart: at(2, 6, 30),
+			wantDue:   at(2, 7, 0),
+			wantOK:    true,
+		},
+		{
+			// Set just before it goes off, it starts a shorter wakeup now
+			name:      "inside the lead window",
+			alarm:     Alarm{Time: "07:00", Enabled: true},
+			now:       at(2, 6, 45),
+			wantStart: at(2, 6, 45),
+			wantDue:   at(2, 7, 0),
+			wantOK:    true,
+		},
+		{
+			name:      "inside the lead window, already fired",
+			alarm:     Alarm{Time: "07:00", Enabled: true, LastFired: at(2, 7, 0)},
+			now:       at(2, 6, 45),
+			wantStart: at(3, 6, 30),
+			wantDue:   at(3, 7, 0),
+			wantOK:    true,
+		},
+		{
+			name:      "after it went off",
+			alarm:     Alarm{Time: "07:00", Enabled: true},
+			now:       at(2, 7, 0),
+			wantStart: at(3, 6, 30),
+			wantDue:   at(3, 7, 0),
+			wantOK:    true,
+		},
+		{
+			name:  "disabled",
+			alarm: Alarm{Time: "07:00"},
+			now:   at(2, 6, 0),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestScheduler(t, lead)
+			start, due, ok := s.NextWakeup(tt.alarm, tt.now)
+			if ok != tt.wantOK {
+				t.Fatalf("got ok %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if !start.Equal(tt.wantStart) || !due.Equal(tt.wantDue) {
+				t.Errorf("got a wakeup from %v, due %v; want from %v, due %v", start, due, tt.wantStart, tt.wantDue)
+			}
+		})
+	}
+}
+
+func TestFireDue(t *testing.T) {
+	ny := newYork(t)
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2021, 6, day, hour, minute, 0, 0, ny)
+	}
+	const lead = 30 * time.Minute
+
+	tests := []struct {
+		name     string
+		alarm    Alarm
+		from, to time.Time
+		// wantDue is when the triggered alarm is due, or zero if none is
+		wantDue     time.Time
+		wantEnabled bool
+		wantSkip    bool
+	}{
+		{
+			name:        "wakeup starts",
+			alarm:       Alarm{Time: "07:00", Enabled: true},
+			from:        at(2, 6, 29),
+			to:          at(2, 6, 30),
+			wantDue:     at(2, 7, 0),
+			wantEnabled: true,
+		},
+		{
+			name:        "not yet",
+			alarm:       Alarm{Time: "07:00", Enabled: true},
+			from:        at(2, 6, 28),
+			to:          at(2, 6, 29),
+			wantEnabled: true,
+		},
+		{
+			name:        "set inside the lead window",
+			alarm:       Alarm{Time: "07:00", Enabled: true},
+			from:        at(2, 6, 45),
+			to:          at(2, 6, 45).Add(time.Second),
+			wantDue:     at(2, 7, 0),
+			wantEnabled: true,
+		},
+		{
+			name:        "skipped",
+			alarm:       Alarm{Time: "07:00", Enabled: true, SkipNext: true},
+			from:        at(2, 6, 29),
+			to:          at(2, 6, 30),
+			wantEnabled: true,
+		},
+		{
+			name:    "one-off is switched off",
+			alarm:   Alarm{Time: "07:00", Date: "2021-06-02", Enabled: true},
+			from:    at(2, 6, 29),
+			to:      at(2, 6, 30),
+			wantDue: at(2, 7, 0),
+		},
+		{
+			name:        "weekday alarm on a Saturday",
+			alarm:       Alarm{Time: "07:00", Days: []string{"mon", "tue", "wed", "thu", "fri"}, Enabled: true},
+			from:        at(5, 6, 29),
+			to:          at(5, 6, 30),
+			wantEnabled: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.alarm.ID = "test"
+			tt.alarm.Name = "Test"
+			s, f := newTestScheduler(t, lead, tt.alarm)
+			s.fireDue(tt.from, tt.to)
+
+			if tt.wantDue.IsZero() {
+				if len(f.ids) > 0 {
+					t.Errorf("triggered the alarm, due %v", f.due[0])
+				}
+			} else if len(f.ids) != 1 || !f.due[0].Equal(tt.wantDue) {
+				t.Errorf("triggered %v due %v, want the alarm due %v", f.ids, f.due, tt.wantDue)
+			}
+			a, _ := s.Get("test")
+			if a.Enabled != tt.wantEnabled {
+				t.Errorf("enabled %v after, want %v", a.Enabled, tt.wantEnabled)
+			}
+			if a.SkipNext != tt.wantSkip {
+				t.Errorf("skip_next %v after, want %v", a.SkipNext, tt.wantSkip)
+			}
+
+			// Each occurrence is handled only once
+			s.fireDue(tt.to, tt.to.Add(time.Minute))
+			if len(f.ids) > 1 {
+				t.Errorf("triggered the alarm again")
+			}
+		})
+	}
+}
+
+func TestSkipNextSkipsOneOccurrence(t *testing.T) {
+	ny := newYork(t)
+	s, f := newTestScheduler(t, 30*time.Minute, Alarm{ID: "wake", Name: "Wake", Time: "07:00", Enabled: true})
+	if _, err := s.SkipNext("wake", true); err != nil {
+		t.Fatal(err)
+	}
+	for day := 2; day <= 3; day++ {
+		start := time.Date(2021, 6, day, 6, 30, 0, 0, ny)
+		s.fireDue(start.Add(-time.Minute), start)
+	}
+	if len(f.due) != 1 || f.due[0].Day() != 3 {
+		t.Errorf("triggered on %v, want only the second day", f.due)
+	}
+}
@@ -0,0 +1,37 @@
+package ambient
+
+import (
+	"math"
+
+	"github.com/klaital/wannetiot/pkg/mcp3008"
+)
+
+// Sensor measures the ambient light level.
+type Sensor interface {
+	ReadLux() (float64, error)
+}
+
+// LDR is a light dependent resistor, or a photodiode, in a voltage divider
+// on an ADC channel. Its reading is mapped onto lux by MaxLux, the light
+// level that gives a full scale reading, and Gamma, which straightens out
+// the sensor's response.
+type LDR struct {
+	ADC     *mcp3008.Mcp3008
+	Channel byte
+	MaxLux  float64
+	Gamma   float64
+}
+
+// ReadLux estimates the light level from the ADC reading.
+func (l *LDR) ReadLux() (float64, error) {
+	pct, err := l.ADC.ReadChannelAsPct(l.Channel)
+	if err != nil {
+		return 0, err
+	}
+	gamma := l.Gamma
+	if gamma <= 0 {
+		gamma = 1
+	}
+	level := math.Max(0, math.Min(1, pct/100))
+	return l.MaxLux * math.Pow(level, gamma), nil
+}
@@ -0,0 +1,225 @@
+package apiauth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/klaital/wannetiot/pkg/loggingresponsewriter"
+)
+
+// Scope is what an authenticated client is allowed to do.
+type Scope string
+
+const (
+	// ScopePublic marks requests that need no credentials at all.
+	ScopePublic Scope = ""
+	// ScopeRead allows reading state, such as the lights and sensors.
+	ScopeRead Scope = "read"
+	// ScopeControl allows changing state as well as reading it.
+	ScopeControl Scope = "control"
+)
+
+var ErrInvalidScope = errors.New("invalid scope")
+var ErrInvalidToken = errors.New("invalid token")
+var ErrNoCredentials = errors.New("no credentials given")
+
+// ParseScope reads a scope name, read or control.
+func ParseScope(s string) (Scope, error) {
+	switch Scope(s) {
+	case ScopeRead, ScopeControl:
+		return Scope(s), nil
+	}
+	return ScopePublic, fmt.Errorf("%w %q - valid options: read, control", ErrInvalidScope, s)
+}
+
+// Allows reports whether the scope covers the required one.
+func (s Scope) Allows(required Scope) bool {
+	switch required {
+	case ScopePublic:
+		return true
+	case ScopeRead:
+		return s == ScopeRead || s == ScopeControl
+	}
+	return s == required
+}
+
+// Token is a bearer token, stored as the SHA-256 of its value so that the
+// tokens themselves never need to be kept in the config.
+type Token struct {
+	Name  string
+	Scope Scope
+	hash  []byte
+}
+
+// HashToken hashes a token's value, giving the hex string to put in the config.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// ParseToken reads a token from the config, as "name:scope:sha256", such as
+// "phone:control:9f86d081884c7d65...".
+func ParseToken(spec string) (Token, error) {
+	parts := strings.Split(strings.TrimSpace(spec), ":")
+	if len(parts) != 3 || parts[0] == "" {
+		return Token{}, fmt.Errorf("%w: %q is not name:scope:sha256", ErrInvalidToken, spec)
+	}
+	scope, err := ParseScope(parts[1])
+	if err != nil {
+		return Token{}, fmt.Errorf("token %q: %w", parts[0], err)
+	}
+	hash, err := hex.DecodeString(parts[2])
+	if err != nil || len(hash) != sha256.Size {
+		return Token{}, fmt.Errorf("%w: token %q does not have a hex SHA-256 hash", ErrInvalidToken, parts[0])
+	}
+	return Token{Name: parts[0], Scope: scope, hash: hash}, nil
+}
+
+// Principal is who made a request, and what they may do.
+type Principal struct {
+	Name  string
+	Scope Scope
+	// Method is how they authenticated: "token" or "certificate".
+	Method string
+}
+
+type principalKey struct{}
+
+// PrincipalFrom returns the principal the middleware authenticated the request as.
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// Authenticator checks requests for a bearer token or a verified TLS client
+// certificate.
+type Authenticator struct {
+	Tokens []Token
+	// ClientCertScope is granted to clients that present a certificate
+	// verified by the server's client CA. Public ignores certificates.
+	ClientCertScope Scope
+	// Required reports the scope a request needs. Nil requires read for GET
+	// and HEAD requests, and control for anything else.
+	Required func(req *http.Request) Scope
+	// Audit records the requests that are denied.
+	Audit *logrus.Logger
+}
+
+// Enabled reports whether there are any credentials to check. Without them,
+// every request is let through.
+func (a *Authenticator) Enabled() bool {
+	return len(a.Tokens) > 0 || a.ClientCertScope != ScopePublic
+}
+
+func (a *Authenticator) required(req *http.Request) Scope {
+	if a.Required != nil {
+		return a.Required(req)
+	}
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		return ScopeRead
+	}
+	return ScopeControl
+}
+
+// Authenticate identifies who made the request. A bearer token takes
+// priority over a client certificate.
+func (a *Authenticator) Authenticate(req *http.Request) (Principal, error) {
+	if auth := req.Header.Get("Authorization"); auth != "" {
+		const prefix = "Bearer "
+		if len(auth) <= len(prefix) || !strings.EqualFold(auth[:len(prefix)], prefix) {
+			return Principal{}, fmt.Errorf("%w: expected a bearer token", ErrInvalidToken)
+		}
+		sum := sha256.Sum256([]byte(strings.TrimSpace(auth[len(prefix):])))
+		// Compare against every token, so the time taken doesn't reveal which matched
+		var match *Token
+		for i := range a.Tokens {
+			if subtle.ConstantTimeCompare(sum[:], a.Tokens[i].hash) == 1 {
+				match = &a.Tokens[i]
+			}
+		}
+		if match == nil {
+			return Principal{}, ErrInvalidToken
+		}
+		return Principal{Name: match.Name, Scope: match.Scope, Method: "token"}, nil
+	}
+	if a.ClientCertScope != ScopePublic && req.TLS != nil && len(req.TLS.VerifiedChains) > 0 {
+		cert := req.TLS.VerifiedChains[0][0]
+		return Principal{Name: cert.Subject.CommonName, Scope: a.ClientCertScope, Method: "certificate"}, nil
+	}
+	return Principal{}, ErrNoCredentials
+}
+
+// Middleware rejects requests without the credentials for the scope they
+// need, with 401 Unauthorized or 403 Forbidden, and records them in the
+// audit log.
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		required := a.required(req)
+		if !a.Enabled() || required == ScopePublic {
+			next.ServeHTTP(resp, req)
+			return
+		}
+		p, err := a.Authenticate(req)
+		if err != nil {
+			a.audit(req, required, nil, err.Error())
+			resp.Header().Set("WWW-Authenticate", `Bearer realm="wannetiot"`)
+			writeError(resp, http.StatusUnauthorized, "unauthorized", "authentication required: "+err.Error())
+			return
+		}
+		if !p.Scope.Allows(required) {
+			a.audit(req, required, &p, "insufficient scope")
+			writeError(resp, http.StatusForbidden, "forbidden", fmt.Sprintf("%s requires the %s scope", req.URL.Path, required))
+			return
+		}
+		loggingresponsewriter.AddField(req.Context(), "principal", p.Name)
+		next.ServeHTTP(resp, req.WithContext(context.WithValue(req.Context(), principalKey{}, p)))
+	})
+}
+
+// audit records a denied request.
+func (a *Authenticator) audit(req *http.Request, required Scope, p *Principal, reason string) {
+	if a.Audit == nil {
+		return
+	}
+	fields := logrus.Fields{
+		"remote":     req.RemoteAddr,
+		"method":     req.Method,
+		"path":       req.URL.Path,
+		"user_agent": req.UserAgent(),
+		"required":   string(required),
+		"reason":     reason,
+	}
+	if id := loggingresponsewriter.RequestID(req.Context()); id != "" {
+		fields["request_id"] = id
+	}
+	if p != nil {
+		fields["principal"] = p.Name
+		fields["scope"] = string(p.Scope)
+		fields["auth_method"] = p.Method
+	}
+	a.Audit.WithFields(fields).Warn("API request denied")
+}
+
+// writeError sends the error in the same form as the /api/v1 errors.
+func writeError(resp http.ResponseWriter, status int, code, message string) {
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(status)
+	body := struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}{}
+	body.Error.Code = code
+	body.Error.Message = message
+	json.NewEncoder(resp).Encode(body)
+}
@@ -0,0 +1,204 @@
+package apiauth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestAllows(t *testing.T) {
+	tests := []struct {
+		scope    Scope
+		required Scope
+		want     bool
+	}{
+		{ScopePublic, ScopePublic, true},
+		{ScopePublic, ScopeRead, false},
+		{ScopePublic, ScopeControl, false},
+		{ScopeRead, ScopePublic, true},
+		{ScopeRead, ScopeRead, true},
+		{ScopeRead, ScopeControl, false},
+		{ScopeControl, ScopePublic, true},
+		{ScopeControl, ScopeRead, true},
+		{ScopeControl, ScopeControl, true},
+	}
+	for _, tt := range tests {
+		if got := tt.scope.Allows(tt.required); got != tt.want {
+			t.Errorf("%q allows %q: got %v, want %v", tt.scope, tt.required, got, tt.want)
+		}
+	}
+}
+
+func TestParseToken(t *testing.T) {
+	hash := HashToken("hunter2")
+	tests := []struct {
+		spec    string
+		want    Token
+		wantErr error
+	}{
+		{spec: "phone:control:" + hash, want: Token{Name: "phone", Scope: ScopeControl}},
+		{spec: " grafana:read:" + hash + " ", want: Token{Name: "grafana", Scope: ScopeRead}},
+		{spec: "phone:admin:" + hash, wantErr: ErrInvalidScope},
+		{spec: "phone::" + hash, wantErr: ErrInvalidScope},
+		{spec: ":read:" + hash, wantErr: ErrInvalidToken},
+		{spec: "phone:read", wantErr: ErrInvalidToken},
+		{spec: "phone:read:hunter2", wantErr: ErrInvalidToken},
+		{spec: "phone:read:" + hash[:32], wantErr: ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseToken(tt.spec)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("got %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Name != tt.want.Name || got.Scope != tt.want.Scope {
+				t.Errorf("got %s:%s, want %s:%s", got.Name, got.Scope, tt.want.Name, tt.want.Scope)
+			}
+		})
+	}
+}
+
+func newTestAuthenticator(t *testing.T, certScope Scope) *Authenticator {
+	a := &Authenticator{ClientCertScope: certScope}
+	for _, spec := range []string{
+		"phone:control:" + HashToken("phone-secret"),
+		"grafana:read:" + HashToken("grafana-secret"),
+	} {
+		token, err := ParseToken(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a.Tokens = append(a.Tokens, token)
+	}
+	return a
+}
+
+// withCert makes the request as if it came with a verified client certificate.
+func withCert(req *http.Request, name string) *http.Request {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: name}}
+	req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+	return req
+}
+
+func TestAuthenticate(t *testing.T) {
+	tests := []struct {
+		name      string
+		certScope Scope
+		auth      string
+		cert      string
+		want      Principal
+		wantErr   error
+	}{
+		{name: "control token", auth: "Bearer phone-secret", want: Principal{"phone", ScopeControl, "token"}},
+		{name: "read token", auth: "Bearer grafana-secret", want: Principal{"grafana", ScopeRead, "token"}},
+		{name: "lower case scheme", auth: "bearer phone-secret", want: Principal{"phone", ScopeControl, "token"}},
+		{name: "unknown token", auth: "Bearer nope", wantErr: ErrInvalidToken},
+		{name: "basic auth", auth: "Basic cGhvbmU6c2VjcmV0", wantErr: ErrInvalidToken},
+		{name: "empty bearer", auth: "Bearer ", wantErr: ErrInvalidToken},
+		{name: "nothing", wantErr: ErrNoCredentials},
+		{name: "certificate", certScope: ScopeRead, cert: "office", want: Principal{"office", ScopeRead, "certificate"}},
+		{name: "certificates ignored", cert: "office", wantErr: ErrNoCredentials},
+		{name: "token over certificate", certScope: ScopeRead, cert: "office", auth: "Bearer phone-secret", want: Principal{"phone", ScopeControl, "token"}},
+		{name: "bad token with a certificate", certScope: ScopeRead, cert: "office", auth: "Bearer nope", wantErr: ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestAuthenticator(t, tt.certScope)
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/lights", nil)
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			if tt.cert != "" {
+				req = withCert(req, tt.cert)
+			}
+			got, err := a.Authenticate(req)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("got %v, %v, want %v", got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		auth   string
+		// public needs no credentials, and disabled has none configured
+		public     bool
+		disabled   bool
+		wantStatus int
+		// wantName is who the handler sees the request as
+		wantName  string
+		wantAudit bool
+	}{
+		{name: "read", method: http.MethodGet, auth: "Bearer grafana-secret", wantStatus: http.StatusOK, wantName: "grafana"},
+		{name: "control", method: http.MethodPost, auth: "Bearer phone-secret", wantStatus: http.StatusOK, wantName: "phone"},
+		{name: "control reading", method: http.MethodHead, auth: "Bearer phone-secret", wantStatus: http.StatusOK, wantName: "phone"},
+		{name: "read only", method: http.MethodPost, auth: "Bearer grafana-secret", wantStatus: http.StatusForbidden, wantAudit: true},
+		{name: "no token", method: http.MethodGet, wantStatus: http.StatusUnauthorized, wantAudit: true},
+		{name: "wrong token", method: http.MethodGet, auth: "Bearer nope", wantStatus: http.StatusUnauthorized, wantAudit: true},
+		{name: "public", method: http.MethodPost, public: true, wantStatus: http.StatusOK},
+		{name: "disabled", method: http.MethodPost, disabled: true, wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestAuthenticator(t, ScopePublic)
+			if tt.disabled {
+				a.Tokens = nil
+			}
+			if tt.public {
+				a.Required = func(*http.Request) Scope { return ScopePublic }
+			}
+			audit, hook := test.NewNullLogger()
+			a.Audit = audit
+
+			var seen string
+			handler := a.Middleware(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				if p, ok := PrincipalFrom(req.Context()); ok {
+					seen = p.Name
+				}
+			}))
+			req := httptest.NewRequest(tt.method, "/api/v1/lights", nil)
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("got status %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if seen != tt.wantName {
+				t.Errorf("the handler saw %q, want %q", seen, tt.wantName)
+			}
+			if got := rec.Header().Get("WWW-Authenticate"); (got != "") != (tt.wantStatus == http.StatusUnauthorized) {
+				t.Errorf("WWW-Authenticate is %q", got)
+			}
+			if audited := len(hook.AllEntries()) > 0; audited != tt.wantAudit {
+				t.Errorf("audited %v, want %v", audited, tt.wantAudit)
+			}
+		})
+	}
+}
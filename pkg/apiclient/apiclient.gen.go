@@ -0,0 +1,5868 @@
+// Package apiclient provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/oapi-codegen/oapi-codegen/v2 version v2.5.1 DO NOT EDIT.
+package apiclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/deepmap/oapi-codegen/pkg/runtime"
+)
+
+const (
+	BearerAuthScopes = "bearerAuth.Scopes"
+)
+
+// Defines values for AlarmDays.
+const (
+	AlarmDaysFri AlarmDays = "fri"
+	AlarmDaysMon AlarmDays = "mon"
+	AlarmDaysSat AlarmDays = "sat"
+	AlarmDaysSun AlarmDays = "sun"
+	AlarmDaysThu AlarmDays = "thu"
+	AlarmDaysTue AlarmDays = "tue"
+	AlarmDaysWed AlarmDays = "wed"
+)
+
+// Defines values for AlarmEventKind.
+const (
+	Fire AlarmEventKind = "fire"
+	Leak AlarmEventKind = "leak"
+)
+
+// Defines values for AlarmResponseDays.
+const (
+	AlarmResponseDaysFri AlarmResponseDays = "fri"
+	AlarmResponseDaysMon AlarmResponseDays = "mon"
+	AlarmResponseDaysSat AlarmResponseDays = "sat"
+	AlarmResponseDaysSun AlarmResponseDays = "sun"
+	AlarmResponseDaysThu AlarmResponseDays = "thu"
+	AlarmResponseDaysTue AlarmResponseDays = "tue"
+	AlarmResponseDaysWed AlarmResponseDays = "wed"
+)
+
+// Defines values for EffectType.
+const (
+	Breathe EffectType = "breathe"
+	Candle  EffectType = "candle"
+	Cycle   EffectType = "cycle"
+	Flash   EffectType = "flash"
+	Pulse   EffectType = "pulse"
+)
+
+// Defines values for EventType.
+const (
+	EventTypeAlarm  EventType = "alarm"
+	EventTypeLights EventType = "lights"
+	EventTypePager  EventType = "pager"
+	EventTypeSensor EventType = "sensor"
+	EventTypeWakeup EventType = "wakeup"
+)
+
+// Defines values for HealthStatus.
+const (
+	Degraded HealthStatus = "degraded"
+	Down     HealthStatus = "down"
+	Ok       HealthStatus = "ok"
+)
+
+// Defines values for NetPanelEventType.
+const (
+	NetPanelEventTypeDoubleTap NetPanelEventType = "double_tap"
+	NetPanelEventTypeLongPress NetPanelEventType = "long_press"
+	NetPanelEventTypePager     NetPanelEventType = "pager"
+	NetPanelEventTypeTap       NetPanelEventType = "tap"
+)
+
+// Defines values for PublishEventRequestType.
+const (
+	PublishEventRequestTypeAlarm PublishEventRequestType = "alarm"
+)
+
+// Defines values for StreamEventsParamsType.
+const (
+	StreamEventsParamsTypeAlarm  StreamEventsParamsType = "alarm"
+	StreamEventsParamsTypeLights StreamEventsParamsType = "lights"
+	StreamEventsParamsTypePager  StreamEventsParamsType = "pager"
+	StreamEventsParamsTypeSensor StreamEventsParamsType = "sensor"
+	StreamEventsParamsTypeWakeup StreamEventsParamsType = "wakeup"
+)
+
+// Alarm defines model for Alarm.
+type Alarm struct {
+	Curve *string `json:"curve,omitempty"`
+
+	// Date Makes this a one-off alarm on the day, as "YYYY-MM-DD".
+	Date *string      `json:"date,omitempty"`
+	Days *[]AlarmDays `json:"days,omitempty"`
+
+	// Effect A light effect spec, such as "flash,count=5".
+	Effect    *string `json:"effect,omitempty"`
+	Enabled   bool    `json:"enabled"`
+	Finale    *bool   `json:"finale,omitempty"`
+	FinaleMax *string `json:"finale_max,omitempty"`
+
+	// Id Generated from the name when creating an alarm without one. A name
+	// without any letters or digits needs an id.
+	Id        string     `json:"id"`
+	LastFired *time.Time `json:"last_fired,omitempty"`
+	Name      string     `json:"name"`
+	Scene     *string    `json:"scene,omitempty"`
+	SkipNext  *bool      `json:"skip_next,omitempty"`
+
+	// Time The local time of day the lights are fully up, as "HH:MM".
+	Time  string    `json:"time"`
+	Zones *[]string `json:"zones,omitempty"`
+}
+
+// AlarmDays defines model for Alarm.Days.
+type AlarmDays string
+
+// AlarmEvent defines model for AlarmEvent.
+type AlarmEvent struct {
+	Active  bool           `json:"active"`
+	Kind    AlarmEventKind `json:"kind"`
+	Message *string        `json:"message,omitempty"`
+	Node    string         `json:"node"`
+}
+
+// AlarmEventKind defines model for AlarmEvent.Kind.
+type AlarmEventKind string
+
+// AlarmRequest An alarm to create or save. It is disabled unless enabled is set. The
+// id is generated from the name when creating an alarm without one, and
+// taken from the path when saving one.
+type AlarmRequest struct {
+	Curve *string `json:"curve,omitempty"`
+
+	// Date Makes this a one-off alarm on the day, as "YYYY-MM-DD".
+	Date *string `json:"date,omitempty"`
+
+	// Days The days of the week, such as "mon" or "monday".
+	Days *[]string `json:"days,omitempty"`
+
+	// Effect A light effect spec, such as "flash,count=5".
+	Effect    *string `json:"effect,omitempty"`
+	Enabled   *bool   `json:"enabled,omitempty"`
+	Finale    *bool   `json:"finale,omitempty"`
+	FinaleMax *string `json:"finale_max,omitempty"`
+	Id        *string `json:"id,omitempty"`
+	Name      string  `json:"name"`
+	Scene     *string `json:"scene,omitempty"`
+	SkipNext  *bool   `json:"skip_next,omitempty"`
+
+	// Time The local time of day the lights are fully up, as "HH:MM".
+	Time  string    `json:"time"`
+	Zones *[]string `json:"zones,omitempty"`
+}
+
+// AlarmResponse defines model for AlarmResponse.
+type AlarmResponse struct {
+	Curve *string `json:"curve,omitempty"`
+
+	// Date Makes this a one-off alarm on the day, as "YYYY-MM-DD".
+	Date *string              `json:"date,omitempty"`
+	Days *[]AlarmResponseDays `json:"days,omitempty"`
+
+	// Effect A light effect spec, such as "flash,count=5".
+	Effect    *string `json:"effect,omitempty"`
+	Enabled   bool    `json:"enabled"`
+	Finale    *bool   `json:"finale,omitempty"`
+	FinaleMax *string `json:"finale_max,omitempty"`
+
+	// Id Generated from the name when creating an alarm without one. A name
+	// without any letters or digits needs an id.
+	Id         string     `json:"id"`
+	LastFired  *time.Time `json:"last_fired,omitempty"`
+	Name       string     `json:"name"`
+	NextDue    *time.Time `json:"next_due,omitempty"`
+	NextWakeup *time.Time `json:"next_wakeup,omitempty"`
+	Scene      *string    `json:"scene,omitempty"`
+	SkipNext   *bool      `json:"skip_next,omitempty"`
+
+	// Time The local time of day the lights are fully up, as "HH:MM".
+	Time  string    `json:"time"`
+	Zones *[]string `json:"zones,omitempty"`
+}
+
+// AlarmResponseDays defines model for AlarmResponse.Days.
+type AlarmResponseDays string
+
+// AmbientState defines model for AmbientState.
+type AmbientState struct {
+	// Level The fraction of their brightness the "low" and "full" scenes are shown at.
+	Level float64 `json:"level"`
+	Lux   float64 `json:"lux"`
+}
+
+// AtmoState defines model for AtmoState.
+type AtmoState struct {
+	At           time.Time `json:"at"`
+	Humidity     float64   `json:"humidity"`
+	TemperatureF float64   `json:"temperature_f"`
+}
+
+// ChannelFactors A value for each channel, each in the range 0.0 - 1.0.
+type ChannelFactors struct {
+	B *float64 `json:"B,omitempty"`
+	G *float64 `json:"G,omitempty"`
+	R *float64 `json:"R,omitempty"`
+	W *float64 `json:"W,omitempty"`
+}
+
+// DustState defines model for DustState.
+type DustState struct {
+	At time.Time `json:"at"`
+
+	// Pm10 PM10, in µg/m³.
+	Pm10 float64 `json:"pm10"`
+
+	// Pm25 PM2.5, in µg/m³.
+	Pm25 float64 `json:"pm25"`
+}
+
+// Effect defines model for Effect.
+type Effect struct {
+	Brightness *float64 `json:"brightness,omitempty"`
+
+	// Color A value for each channel, each in the range 0.0 - 1.0.
+	Color    *ChannelFactors `json:"color,omitempty"`
+	Count    *int            `json:"count,omitempty"`
+	Duration *string         `json:"duration,omitempty"`
+	Kelvin   *float64        `json:"kelvin,omitempty"`
+	Period   *string         `json:"period,omitempty"`
+	Type     EffectType      `json:"type"`
+	Zones    *[]string       `json:"zones,omitempty"`
+}
+
+// EffectType defines model for Effect.Type.
+type EffectType string
+
+// Error defines model for Error.
+type Error struct {
+	// Code A machine-readable code, such as not_found, conflict,
+	// invalid_state, invalid_request, invalid_json or internal.
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ErrorResponse defines model for ErrorResponse.
+type ErrorResponse struct {
+	Error Error `json:"error"`
+}
+
+// Event defines model for Event.
+type Event struct {
+	// Data A LightsState, WakeupEvent, PagerEvent, SensorEvent or AlarmEvent,
+	// depending on the type.
+	Data interface{} `json:"data"`
+	Id   string      `json:"id"`
+	Time time.Time   `json:"time"`
+	Type EventType   `json:"type"`
+}
+
+// EventType defines model for Event.Type.
+type EventType string
+
+// HealthReport defines model for HealthReport.
+type HealthReport struct {
+	Critical  bool                    `json:"critical"`
+	Details   *map[string]interface{} `json:"details,omitempty"`
+	Errors    *int                    `json:"errors,omitempty"`
+	LastError *string                 `json:"last_error,omitempty"`
+
+	// LastSuccess The last successful reading or write, or the last time a loop went round.
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+	Message     *string    `json:"message,omitempty"`
+
+	// Name Such as gpio, wakeup, alarms, rf, panel1, am2302 or telemetry.
+	Name   string       `json:"name"`
+	Status HealthStatus `json:"status"`
+}
+
+// HealthStatus defines model for HealthStatus.
+type HealthStatus string
+
+// LightConfig PWM duty cycles, where 16777216 is fully on.
+type LightConfig struct {
+	B *int32 `json:"B,omitempty"`
+	G *int32 `json:"G,omitempty"`
+
+	// Kelvin The colour temperature solved for, or 0 for a custom mix.
+	Kelvin *float64 `json:"Kelvin,omitempty"`
+	Name   *string  `json:"Name,omitempty"`
+	R      *int32   `json:"R,omitempty"`
+	W      *int32   `json:"W,omitempty"`
+}
+
+// LightsState defines model for LightsState.
+type LightsState struct {
+	Ambient *AmbientState `json:"ambient,omitempty"`
+
+	// Effect The type of the effect playing, if any.
+	Effect *string `json:"effect,omitempty"`
+
+	// Output PWM duty cycles, where 16777216 is fully on.
+	Output LightConfig `json:"output"`
+	Power  PowerReport `json:"power"`
+
+	// Setting The last light setting requested, such as "low".
+	Setting string         `json:"setting"`
+	Sleep   *ProgressState `json:"sleep,omitempty"`
+	Wakeup  *ProgressState `json:"wakeup,omitempty"`
+	Zones   []ZoneState    `json:"zones"`
+}
+
+// LightsUpdate Give one of scene, kelvin or color.
+type LightsUpdate struct {
+	// Brightness Applies to kelvin and color, 0.0 - 1.0. Defaults to 1.0.
+	Brightness *float64 `json:"brightness,omitempty"`
+
+	// Color A value for each channel, each in the range 0.0 - 1.0.
+	Color  *ChannelFactors `json:"color,omitempty"`
+	Kelvin *float64        `json:"kelvin,omitempty"`
+	Scene  *string         `json:"scene,omitempty"`
+
+	// Transition How long to fade for this change only, such as "2s".
+	Transition *string   `json:"transition,omitempty"`
+	Zones      *[]string `json:"zones,omitempty"`
+}
+
+// NetPanelCommand defines model for NetPanelCommand.
+type NetPanelCommand struct {
+	At time.Time `json:"at"`
+	Id int64     `json:"id"`
+
+	// Level For an alarm, how far it has escalated, from 1 up.
+	Level *int `json:"level,omitempty"`
+
+	// Type The feedback to play: paged, already_paged, page_acknowledged,
+	// scene, sleep, snooze, dismiss_on, dismiss_off, denied or alarm.
+	Type string `json:"type"`
+}
+
+// NetPanelCommands defines model for NetPanelCommands.
+type NetPanelCommands struct {
+	Commands []NetPanelCommand `json:"commands"`
+}
+
+// NetPanelEvent defines model for NetPanelEvent.
+type NetPanelEvent struct {
+	// Seq Counts up from 1 with each new event, and starts again on registering.
+	Seq     int64             `json:"seq"`
+	Session string            `json:"session"`
+	Type    NetPanelEventType `json:"type"`
+}
+
+// NetPanelEventType defines model for NetPanelEvent.Type.
+type NetPanelEventType string
+
+// NetPanelHeartbeat defines model for NetPanelHeartbeat.
+type NetPanelHeartbeat struct {
+	// Dimmer The dimmer knob's reading, as a percentage.
+	Dimmer int `json:"dimmer"`
+
+	// Rssi The Wi-Fi signal strength, in dBm.
+	Rssi          int    `json:"rssi"`
+	Session       string `json:"session"`
+	UptimeSeconds *int64 `json:"uptime_seconds,omitempty"`
+}
+
+// NetPanelRegistration defines model for NetPanelRegistration.
+type NetPanelRegistration struct {
+	// Features What the panel has, such as pager, switch, dimmer, led and speaker.
+	Features *[]string `json:"features,omitempty"`
+	Firmware *string   `json:"firmware,omitempty"`
+	Id       string    `json:"id"`
+	Name     *string   `json:"name,omitempty"`
+}
+
+// NetPanelStatus defines model for NetPanelStatus.
+type NetPanelStatus struct {
+	Dimmer   int       `json:"dimmer"`
+	Features *[]string `json:"features,omitempty"`
+	Firmware *string   `json:"firmware,omitempty"`
+	Id       string    `json:"id"`
+	LastSeen time.Time `json:"last_seen"`
+	Name     *string   `json:"name,omitempty"`
+	Online   bool      `json:"online"`
+
+	// Pending How many commands are waiting to be delivered.
+	Pending       int       `json:"pending"`
+	RegisteredAt  time.Time `json:"registered_at"`
+	Rssi          int       `json:"rssi"`
+	UptimeSeconds int64     `json:"uptime_seconds"`
+}
+
+// NetPanelWelcome defines model for NetPanelWelcome.
+type NetPanelWelcome struct {
+	Commands []NetPanelCommand `json:"commands"`
+
+	// HeartbeatSeconds How often to send a heartbeat. Panels that miss 3 are offline.
+	HeartbeatSeconds int `json:"heartbeat_seconds"`
+
+	// PollSeconds The longest a poll for commands is held open.
+	PollSeconds int    `json:"poll_seconds"`
+	Session     string `json:"session"`
+}
+
+// NodeStatus defines model for NodeStatus.
+type NodeStatus struct {
+	GoVersion string `json:"go_version"`
+	Node      string `json:"node"`
+
+	// Ready False while a critical subsystem is down.
+	Ready         bool           `json:"ready"`
+	StartedAt     time.Time      `json:"started_at"`
+	Status        HealthStatus   `json:"status"`
+	Subsystems    []HealthReport `json:"subsystems"`
+	Uptime        string         `json:"uptime"`
+	UptimeSeconds int64          `json:"uptime_seconds"`
+	Version       string         `json:"version"`
+}
+
+// Page defines model for Page.
+type Page struct {
+	At time.Time `json:"at"`
+
+	// Count How many times it was paged, with the repeats within the cooldown.
+	Count  int       `json:"count"`
+	Id     int       `json:"id"`
+	LastAt time.Time `json:"last_at"`
+
+	// Source Where the page came from, such as panel1, rf, api or net:<panel id>.
+	Source string `json:"source"`
+}
+
+// PanelState defines model for PanelState.
+type PanelState struct {
+	// Dimmer The dimmer knob's last reading, as a percentage.
+	Dimmer     int       `json:"dimmer"`
+	Enabled    bool      `json:"enabled"`
+	Id         int       `json:"id"`
+	SceneCycle *[]string `json:"scene_cycle,omitempty"`
+}
+
+// PowerReport defines model for PowerReport.
+type PowerReport struct {
+	KwhToday     float64     `json:"kwh_today"`
+	OnHoursToday float64     `json:"on_hours_today"`
+	Watts        float64     `json:"watts"`
+	Zones        []ZonePower `json:"zones"`
+}
+
+// ProgressState defines model for ProgressState.
+type ProgressState struct {
+	Progress     float64    `json:"progress"`
+	Remaining    *string    `json:"remaining,omitempty"`
+	SnoozedUntil *time.Time `json:"snoozed_until,omitempty"`
+}
+
+// PublishEventRequest defines model for PublishEventRequest.
+type PublishEventRequest struct {
+	Data AlarmEvent              `json:"data"`
+	Type PublishEventRequestType `json:"type"`
+}
+
+// PublishEventRequestType defines model for PublishEventRequest.Type.
+type PublishEventRequestType string
+
+// Scene defines model for Scene.
+type Scene struct {
+	Brightness float64 `json:"brightness"`
+
+	// Color A value for each channel, each in the range 0.0 - 1.0.
+	Color      *ChannelFactors `json:"color,omitempty"`
+	Id         string          `json:"id"`
+	Kelvin     *float64        `json:"kelvin,omitempty"`
+	Name       string          `json:"name"`
+	Transition *string         `json:"transition,omitempty"`
+
+	// Zones Overrides for some zones, keyed by zone or group name.
+	Zones *map[string]SceneZone `json:"zones,omitempty"`
+}
+
+// SceneRequest A scene to create or save. The id is generated from the name when
+// creating a scene without one, and taken from the path when saving one.
+// The name defaults to the id.
+type SceneRequest struct {
+	Brightness *float64 `json:"brightness,omitempty"`
+
+	// Color A value for each channel, each in the range 0.0 - 1.0.
+	Color      *ChannelFactors `json:"color,omitempty"`
+	Id         *string         `json:"id,omitempty"`
+	Kelvin     *float64        `json:"kelvin,omitempty"`
+	Name       *string         `json:"name,omitempty"`
+	Transition *string         `json:"transition,omitempty"`
+
+	// Zones Overrides for some zones, keyed by zone or group name.
+	Zones *map[string]SceneZone `json:"zones,omitempty"`
+}
+
+// SceneZone defines model for SceneZone.
+type SceneZone struct {
+	Brightness *float64 `json:"brightness,omitempty"`
+
+	// Color A value for each channel, each in the range 0.0 - 1.0.
+	Color  *ChannelFactors `json:"color,omitempty"`
+	Kelvin *float64        `json:"kelvin,omitempty"`
+}
+
+// SensorSample defines model for SensorSample.
+type SensorSample struct {
+	At           time.Time `json:"at"`
+	Humidity     *float64  `json:"humidity,omitempty"`
+	Lux          *float64  `json:"lux,omitempty"`
+	Pm10         *float64  `json:"pm10,omitempty"`
+	Pm25         *float64  `json:"pm25,omitempty"`
+	TemperatureF *float64  `json:"temperature_f,omitempty"`
+}
+
+// SensorsState defines model for SensorsState.
+type SensorsState struct {
+	Ambient *AmbientState `json:"ambient,omitempty"`
+	Atmo    *AtmoState    `json:"atmo,omitempty"`
+	Dust    *DustState    `json:"dust,omitempty"`
+	Power   PowerReport   `json:"power"`
+}
+
+// SleepRequest defines model for SleepRequest.
+type SleepRequest struct {
+	// Duration How long the lights take to fade out, such as "30m".
+	Duration *string `json:"duration,omitempty"`
+
+	// Warm Shift to a warm colour before fading out.
+	Warm  *bool     `json:"warm,omitempty"`
+	Zones *[]string `json:"zones,omitempty"`
+}
+
+// StatusResponse defines model for StatusResponse.
+type StatusResponse struct {
+	Status string `json:"status"`
+}
+
+// WakeupRequest defines model for WakeupRequest.
+type WakeupRequest struct {
+	// Curve The wakeup curve, such as "sunrise" or "linear".
+	Curve *string `json:"curve,omitempty"`
+
+	// Duration How long the wakeup takes, such as "30m".
+	Duration *string `json:"duration,omitempty"`
+
+	// Scene The scene to wake up to. Only /api/v1 accepts it.
+	Scene *string   `json:"scene,omitempty"`
+	Zones *[]string `json:"zones,omitempty"`
+}
+
+// ZonePower defines model for ZonePower.
+type ZonePower struct {
+	DutyHoursToday map[string]float64 `json:"duty_hours_today"`
+	KwhToday       float64            `json:"kwh_today"`
+	OnHoursToday   float64            `json:"on_hours_today"`
+	Watts          float64            `json:"watts"`
+	Zone           string             `json:"zone"`
+}
+
+// ZoneState defines model for ZoneState.
+type ZoneState struct {
+	Channels    []string  `json:"channels"`
+	Frequency   string    `json:"frequency"`
+	Groups      *[]string `json:"groups,omitempty"`
+	HardwarePwm bool      `json:"hardware_pwm"`
+	Name        string    `json:"name"`
+
+	// Output PWM duty cycles, where 16777216 is fully on.
+	Output LightConfig `json:"output"`
+	Type   string      `json:"type"`
+}
+
+// ID defines model for ID.
+type ID = string
+
+// Zone defines model for Zone.
+type Zone = []string
+
+// AlarmListResult defines model for AlarmListResult.
+type AlarmListResult = []AlarmResponse
+
+// AlarmResult defines model for AlarmResult.
+type AlarmResult = AlarmResponse
+
+// Failure defines model for Failure.
+type Failure = ErrorResponse
+
+// LightsResult defines model for LightsResult.
+type LightsResult = LightsState
+
+// NetPanelCommandsResult defines model for NetPanelCommandsResult.
+type NetPanelCommandsResult = NetPanelCommands
+
+// SceneListResult defines model for SceneListResult.
+type SceneListResult = []Scene
+
+// SceneResult defines model for SceneResult.
+type SceneResult = Scene
+
+// AlarmBody An alarm to create or save. It is disabled unless enabled is set. The
+// id is generated from the name when creating an alarm without one, and
+// taken from the path when saving one.
+type AlarmBody = AlarmRequest
+
+// SceneBody A scene to create or save. The id is generated from the name when
+// creating a scene without one, and taken from the path when saving one.
+// The name defaults to the id.
+type SceneBody = SceneRequest
+
+// StreamEventsParams defines parameters for StreamEvents.
+type StreamEventsParams struct {
+	// Type Only send these event types, such as "lights,pager".
+	Type        *[]StreamEventsParamsType `form:"type,omitempty" json:"type,omitempty"`
+	LastEventId *string                   `form:"last_event_id,omitempty" json:"last_event_id,omitempty"`
+	LastEventID *string                   `json:"Last-Event-ID,omitempty"`
+}
+
+// StreamEventsParamsType defines parameters for StreamEvents.
+type StreamEventsParamsType string
+
+// PutLightsParams defines parameters for PutLights.
+type PutLightsParams struct {
+	// Zone The zones or zone groups to address. None means every zone.
+	Zone *Zone `form:"zone,omitempty" json:"zone,omitempty"`
+}
+
+// PlayEffectParams defines parameters for PlayEffect.
+type PlayEffectParams struct {
+	// Zone The zones or zone groups to address. None means every zone.
+	Zone *Zone `form:"zone,omitempty" json:"zone,omitempty"`
+}
+
+// StartSleepParams defines parameters for StartSleep.
+type StartSleepParams struct {
+	// Zone The zones or zone groups to address. None means every zone.
+	Zone *Zone `form:"zone,omitempty" json:"zone,omitempty"`
+}
+
+// StartWakeupParams defines parameters for StartWakeup.
+type StartWakeupParams struct {
+	// Zone The zones or zone groups to address. None means every zone.
+	Zone *Zone `form:"zone,omitempty" json:"zone,omitempty"`
+}
+
+// PollNetPanelParams defines parameters for PollNetPanel.
+type PollNetPanelParams struct {
+	Session string `form:"session" json:"session"`
+
+	// Wait Seconds to wait for a command, up to the node's poll_seconds.
+	Wait *int `form:"wait,omitempty" json:"wait,omitempty"`
+}
+
+// RecallSceneParams defines parameters for RecallScene.
+type RecallSceneParams struct {
+	// Zone The zones or zone groups to address. None means every zone.
+	Zone *Zone `form:"zone,omitempty" json:"zone,omitempty"`
+}
+
+// CreateAlarmJSONRequestBody defines body for CreateAlarm for application/json ContentType.
+type CreateAlarmJSONRequestBody = AlarmRequest
+
+// UpdateAlarmJSONRequestBody defines body for UpdateAlarm for application/json ContentType.
+type UpdateAlarmJSONRequestBody = AlarmRequest
+
+// PublishEventJSONRequestBody defines body for PublishEvent for application/json ContentType.
+type PublishEventJSONRequestBody = PublishEventRequest
+
+// PutLightsJSONRequestBody defines body for PutLights for application/json ContentType.
+type PutLightsJSONRequestBody = LightsUpdate
+
+// PlayEffectJSONRequestBody defines body for PlayEffect for application/json ContentType.
+type PlayEffectJSONRequestBody = Effect
+
+// StartSleepJSONRequestBody defines body for StartSleep for application/json ContentType.
+type StartSleepJSONRequestBody = SleepRequest
+
+// StartWakeupJSONRequestBody defines body for StartWakeup for application/json ContentType.
+type StartWakeupJSONRequestBody = WakeupRequest
+
+// RegisterNetPanelJSONRequestBody defines body for RegisterNetPanel for application/json ContentType.
+type RegisterNetPanelJSONRequestBody = NetPanelRegistration
+
+// NetPanelEventJSONRequestBody defines body for NetPanelEvent for application/json ContentType.
+type NetPanelEventJSONRequestBody = NetPanelEvent
+
+// NetPanelHeartbeatJSONRequestBody defines body for NetPanelHeartbeat for application/json ContentType.
+type NetPanelHeartbeatJSONRequestBody = NetPanelHeartbeat
+
+// CreateSceneJSONRequestBody defines body for CreateScene for application/json ContentType.
+type CreateSceneJSONRequestBody = SceneRequest
+
+// UpdateSceneJSONRequestBody defines body for UpdateScene for application/json ContentType.
+type UpdateSceneJSONRequestBody = SceneRequest
+
+// RequestEditorFn  is the function signature for the RequestEditor callback function
+type RequestEditorFn func(ctx context.Context, req *http.Request) error
+
+// Doer performs HTTP requests.
+//
+// The standard http.Client implements this interface.
+type HttpRequestDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Client which conforms to the OpenAPI3 specification for this service.
+type Client struct {
+	// The endpoint of the server conforming to this interface, with scheme,
+	// https://api.deepmap.com for example. This can contain a path relative
+	// to the server, such as https://api.deepmap.com/dev-test, and all the
+	// paths in the swagger spec will be appended to the server.
+	Server string
+
+	// Doer for performing requests, typically a *http.Client with any
+	// customized settings, such as certificate chains.
+	Client HttpRequestDoer
+
+	// A list of callbacks for modifying requests which are generated before sending over
+	// the network.
+	RequestEditors []RequestEditorFn
+}
+
+// ClientOption allows setting custom parameters during construction
+type ClientOption func(*Client) error
+
+// Creates a new Client, with reasonable defaults
+func NewClient(server string, opts ...ClientOption) (*Client, error) {
+	// create a client with sane default values
+	client := Client{
+		Server: server,
+	}
+	// mutate client and add all optional params
+	for _, o := range opts {
+		if err := o(&client); err != nil {
+			return nil, err
+		}
+	}
+	// ensure the server URL always has a trailing slash
+	if !strings.HasSuffix(client.Server, "/") {
+		client.Server += "/"
+	}
+	// create httpClient, if not already present
+	if client.Client == nil {
+		client.Client = &http.Client{}
+	}
+	return &client, nil
+}
+
+// WithHTTPClient allows overriding the default Doer, which is
+// automatically created using http.Client. This is useful for tests.
+func WithHTTPClient(doer HttpRequestDoer) ClientOption {
+	return func(c *Client) error {
+		c.Client = doer
+		return nil
+	}
+}
+
+// WithRequestEditorFn allows setting up a callback function, which will be
+// called right before sending the request. This can be used to mutate the request.
+func WithRequestEditorFn(fn RequestEditorFn) ClientOption {
+	return func(c *Client) error {
+		c.RequestEditors = append(c.RequestEditors, fn)
+		return nil
+	}
+}
+
+// The interface specification for the client above.
+type ClientInterface interface {
+	// ListAlarms request
+	ListAlarms(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// CreateAlarmWithBody request with any body
+	CreateAlarmWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	CreateAlarm(ctx context.Context, body CreateAlarmJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// DeleteAlarm request
+	DeleteAlarm(ctx context.Context, id ID, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetAlarm request
+	GetAlarm(ctx context.Context, id ID, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// UpdateAlarmWithBody request with any body
+	UpdateAlarmWithBody(ctx context.Context, id ID, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	UpdateAlarm(ctx context.Context, id ID, body UpdateAlarmJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// UnskipAlarm request
+	UnskipAlarm(ctx context.Context, id ID, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// SkipAlarm request
+	SkipAlarm(ctx context.Context, id ID, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// StreamEvents request
+	StreamEvents(ctx context.Context, params *StreamEventsParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// PublishEventWithBody request with any body
+	PublishEventWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	PublishEvent(ctx context.Context, body PublishEventJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetLights request
+	GetLights(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// PutLightsWithBody request with any body
+	PutLightsWithBody(ctx context.Context, params *PutLightsParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	PutLights(ctx context.Context, params *PutLightsParams, body PutLightsJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// StopEffect request
+	StopEffect(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// PlayEffectWithBody request with any body
+	PlayEffectWithBody(ctx context.Context, params *PlayEffectParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	PlayEffect(ctx context.Context, params *PlayEffectParams, body PlayEffectJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// CancelSleep request
+	CancelSleep(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// StartSleepWithBody request with any body
+	StartSleepWithBody(ctx context.Context, params *StartSleepParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	StartSleep(ctx context.Context, params *StartSleepParams, body StartSleepJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// DismissWakeup request
+	DismissWakeup(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// StartWakeupWithBody request with any body
+	StartWakeupWithBody(ctx context.Context, params *StartWakeupParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	StartWakeup(ctx context.Context, params *StartWakeupParams, body StartWakeupJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// SnoozeWakeup request
+	SnoozeWakeup(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// ListZones request
+	ListZones(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// ListNetPanels request
+	ListNetPanels(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// RegisterNetPanelWithBody request with any body
+	RegisterNetPanelWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	RegisterNetPanel(ctx context.Context, body RegisterNetPanelJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// PollNetPanel request
+	PollNetPanel(ctx context.Context, id ID, params *PollNetPanelParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// NetPanelEventWithBody request with any body
+	NetPanelEventWithBody(ctx context.Context, id ID, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	NetPanelEvent(ctx context.Context, id ID, body NetPanelEventJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// NetPanelHeartbeatWithBody request with any body
+	NetPanelHeartbeatWithBody(ctx context.Context, id ID, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	NetPanelHeartbeat(ctx context.Context, id ID, body NetPanelHeartbeatJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetSpec request
+	GetSpec(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// ListPages request
+	ListPages(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// Page request
+	Page(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// ClosePage request
+	ClosePage(ctx context.Context, id int, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// ListPanels request
+	ListPanels(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// ListScenes request
+	ListScenes(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// CreateSceneWithBody request with any body
+	CreateSceneWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	CreateScene(ctx context.Context, body CreateSceneJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// DeleteScene request
+	DeleteScene(ctx context.Context, id ID, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetScene request
+	GetScene(ctx context.Context, id ID, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// UpdateSceneWithBody request with any body
+	UpdateSceneWithBody(ctx context.Context, id ID, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	UpdateScene(ctx context.Context, id ID, body UpdateSceneJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// RecallScene request
+	RecallScene(ctx context.Context, id ID, params *RecallSceneParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetSensors request
+	GetSensors(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetSensorHistory request
+	GetSensorHistory(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetStatus request
+	GetStatus(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// Healthz request
+	Healthz(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// Readyz request
+	Readyz(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+}
+
+func (c *Client) ListAlarms(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewListAlarmsRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) CreateAlarmWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewCreateAlarmRequestWithBody(c.Server, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) CreateAlarm(ctx context.Context, body CreateAlarmJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewCreateAlarmRequest(c.Server, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) DeleteAlarm(ctx context.Context, id ID, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewDeleteAlarmRequest(c.Server, id)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetAlarm(ctx context.Context, id ID, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetAlarmRequest(c.Server, id)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) UpdateAlarmWithBody(ctx context.Context, id ID, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewUpdateAlarmRequestWithBody(c.Server, id, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) UpdateAlarm(ctx context.Context, id ID, body UpdateAlarmJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewUpdateAlarmRequest(c.Server, id, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) UnskipAlarm(ctx context.Context, id ID, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewUnskipAlarmRequest(c.Server, id)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) SkipAlarm(ctx context.Context, id ID, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewSkipAlarmRequest(c.Server, id)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) StreamEvents(ctx context.Context, params *StreamEventsParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewStreamEventsRequest(c.Server, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) PublishEventWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPublishEventRequestWithBody(c.Server, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) PublishEvent(ctx context.Context, body PublishEventJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPublishEventRequest(c.Server, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetLights(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetLightsRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) PutLightsWithBody(ctx context.Context, params *PutLightsParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPutLightsRequestWithBody(c.Server, params, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) PutLights(ctx context.Context, params *PutLightsParams, body PutLightsJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPutLightsRequest(c.Server, params, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) StopEffect(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewStopEffectRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) PlayEffectWithBody(ctx context.Context, params *PlayEffectParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPlayEffectRequestWithBody(c.Server, params, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) PlayEffect(ctx context.Context, params *PlayEffectParams, body PlayEffectJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPlayEffectRequest(c.Server, params, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) CancelSleep(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewCancelSleepRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) StartSleepWithBody(ctx context.Context, params *StartSleepParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewStartSleepRequestWithBody(c.Server, params, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) StartSleep(ctx context.Context, params *StartSleepParams, body StartSleepJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewStartSleepRequest(c.Server, params, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) DismissWakeup(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewDismissWakeupRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) StartWakeupWithBody(ctx context.Context, params *StartWakeupParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewStartWakeupRequestWithBody(c.Server, params, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) StartWakeup(ctx context.Context, params *StartWakeupParams, body StartWakeupJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewStartWakeupRequest(c.Server, params, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) SnoozeWakeup(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewSnoozeWakeupRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) ListZones(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewListZonesRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) ListNetPanels(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewListNetPanelsRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) RegisterNetPanelWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewRegisterNetPanelRequestWithBody(c.Server, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) RegisterNetPanel(ctx context.Context, body RegisterNetPanelJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewRegisterNetPanelRequest(c.Server, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) PollNetPanel(ctx context.Context, id ID, params *PollNetPanelParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPollNetPanelRequest(c.Server, id, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) NetPanelEventWithBody(ctx context.Context, id ID, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewNetPanelEventRequestWithBody(c.Server, id, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) NetPanelEvent(ctx context.Context, id ID, body NetPanelEventJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewNetPanelEventRequest(c.Server, id, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) NetPanelHeartbeatWithBody(ctx context.Context, id ID, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewNetPanelHeartbeatRequestWithBody(c.Server, id, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) NetPanelHeartbeat(ctx context.Context, id ID, body NetPanelHeartbeatJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewNetPanelHeartbeatRequest(c.Server, id, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetSpec(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetSpecRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) ListPages(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewListPagesRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) Page(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPageRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) ClosePage(ctx context.Context, id int, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewClosePageRequest(c.Server, id)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) ListPanels(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewListPanelsRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) ListScenes(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewListScenesRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) CreateSceneWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewCreateSceneRequestWithBody(c.Server, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) CreateScene(ctx context.Context, body CreateSceneJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewCreateSceneRequest(c.Server, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) DeleteScene(ctx context.Context, id ID, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewDeleteSceneRequest(c.Server, id)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetScene(ctx context.Context, id ID, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetSceneRequest(c.Server, id)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) UpdateSceneWithBody(ctx context.Context, id ID, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewUpdateSceneRequestWithBody(c.Server, id, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) UpdateScene(ctx context.Context, id ID, body UpdateSceneJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewUpdateSceneRequest(c.Server, id, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) RecallScene(ctx context.Context, id ID, params *RecallSceneParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewRecallSceneRequest(c.Server, id, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetSensors(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetSensorsRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetSensorHistory(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetSensorHistoryRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetStatus(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetStatusRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) Healthz(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewHealthzRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) Readyz(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewReadyzRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+// NewListAlarmsRequest generates requests for ListAlarms
+func NewListAlarmsRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/alarms")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewCreateAlarmRequest calls the generic CreateAlarm builder with application/json body
+func NewCreateAlarmRequest(server string, body CreateAlarmJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewCreateAlarmRequestWithBody(server, "application/json", bodyReader)
+}
+
+// NewCreateAlarmRequestWithBody generates requests for CreateAlarm with any type of body
+func NewCreateAlarmRequestWithBody(server string, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/alarms")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
+// NewDeleteAlarmRequest generates requests for DeleteAlarm
+func NewDeleteAlarmRequest(server string, id ID) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "id", runtime.ParamLocationPath, id)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/alarms/%s", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("DELETE", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetAlarmRequest generates requests for GetAlarm
+func NewGetAlarmRequest(server string, id ID) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "id", runtime.ParamLocationPath, id)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/alarms/%s", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewUpdateAlarmRequest calls the generic UpdateAlarm builder with application/json body
+func NewUpdateAlarmRequest(server string, id ID, body UpdateAlarmJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewUpdateAlarmRequestWithBody(server, id, "application/json", bodyReader)
+}
+
+// NewUpdateAlarmRequestWithBody generates requests for UpdateAlarm with any type of body
+func NewUpdateAlarmRequestWithBody(server string, id ID, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "id", runtime.ParamLocationPath, id)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/alarms/%s", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("PUT", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
+// NewUnskipAlarmRequest generates requests for UnskipAlarm
+func NewUnskipAlarmRequest(server string, id ID) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "id", runtime.ParamLocationPath, id)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/alarms/%s/skip", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("DELETE", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewSkipAlarmRequest generates requests for SkipAlarm
+func NewSkipAlarmRequest(server string, id ID) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "id", runtime.ParamLocationPath, id)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/alarms/%s/skip", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewStreamEventsRequest generates requests for StreamEvents
+func NewStreamEventsRequest(server string, params *StreamEventsParams) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/events")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.Type != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", false, "type", runtime.ParamLocationQuery, *params.Type); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.LastEventId != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "last_event_id", runtime.ParamLocationQuery, *params.LastEventId); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+
+		if params.LastEventID != nil {
+			var headerParam0 string
+
+			headerParam0, err = runtime.StyleParamWithLocation("simple", false, "Last-Event-ID", runtime.ParamLocationHeader, *params.LastEventID)
+			if err != nil {
+				return nil, err
+			}
+
+			req.Header.Set("Last-Event-ID", headerParam0)
+		}
+
+	}
+
+	return req, nil
+}
+
+// NewPublishEventRequest calls the generic PublishEvent builder with application/json body
+func NewPublishEventRequest(server string, body PublishEventJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewPublishEventRequestWithBody(server, "application/json", bodyReader)
+}
+
+// NewPublishEventRequestWithBody generates requests for PublishEvent with any type of body
+func NewPublishEventRequestWithBody(server string, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/events")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
+// NewGetLightsRequest generates requests for GetLights
+func NewGetLightsRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/lights")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewPutLightsRequest calls the generic PutLights builder with application/json body
+func NewPutLightsRequest(server string, params *PutLightsParams, body PutLightsJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewPutLightsRequestWithBody(server, params, "application/json", bodyReader)
+}
+
+// NewPutLightsRequestWithBody generates requests for PutLights with any type of body
+func NewPutLightsRequestWithBody(server string, params *PutLightsParams, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/lights")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.Zone != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", false, "zone", runtime.ParamLocationQuery, *params.Zone); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("PUT", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
+// NewStopEffectRequest generates requests for StopEffect
+func NewStopEffectRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/lights/effect")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("DELETE", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewPlayEffectRequest calls the generic PlayEffect builder with application/json body
+func NewPlayEffectRequest(server string, params *PlayEffectParams, body PlayEffectJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewPlayEffectRequestWithBody(server, params, "application/json", bodyReader)
+}
+
+// NewPlayEffectRequestWithBody generates requests for PlayEffect with any type of body
+func NewPlayEffectRequestWithBody(server string, params *PlayEffectParams, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/lights/effect")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.Zone != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", false, "zone", runtime.ParamLocationQuery, *params.Zone); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
+// NewCancelSleepRequest generates requests for CancelSleep
+func NewCancelSleepRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/lights/sleep")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("DELETE", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewStartSleepRequest calls the generic StartSleep builder with application/json body
+func NewStartSleepRequest(server string, params *StartSleepParams, body StartSleepJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewStartSleepRequestWithBody(server, params, "application/json", bodyReader)
+}
+
+// NewStartSleepRequestWithBody generates requests for StartSleep with any type of body
+func NewStartSleepRequestWithBody(server string, params *StartSleepParams, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/lights/sleep")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.Zone != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", false, "zone", runtime.ParamLocationQuery, *params.Zone); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
+// NewDismissWakeupRequest generates requests for DismissWakeup
+func NewDismissWakeupRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/lights/wakeup")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("DELETE", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewStartWakeupRequest calls the generic StartWakeup builder with application/json body
+func NewStartWakeupRequest(server string, params *StartWakeupParams, body StartWakeupJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewStartWakeupRequestWithBody(server, params, "application/json", bodyReader)
+}
+
+// NewStartWakeupRequestWithBody generates requests for StartWakeup with any type of body
+func NewStartWakeupRequestWithBody(server string, params *StartWakeupParams, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/lights/wakeup")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.Zone != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", false, "zone", runtime.ParamLocationQuery, *params.Zone); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
+// NewSnoozeWakeupRequest generates requests for SnoozeWakeup
+func NewSnoozeWakeupRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/lights/wakeup/snooze")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewListZonesRequest generates requests for ListZones
+func NewListZonesRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/lights/zones")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewListNetPanelsRequest generates requests for ListNetPanels
+func NewListNetPanelsRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/netpanels")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewRegisterNetPanelRequest calls the generic RegisterNetPanel builder with application/json body
+func NewRegisterNetPanelRequest(server string, body RegisterNetPanelJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewRegisterNetPanelRequestWithBody(server, "application/json", bodyReader)
+}
+
+// NewRegisterNetPanelRequestWithBody generates requests for RegisterNetPanel with any type of body
+func NewRegisterNetPanelRequestWithBody(server string, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/netpanels")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
+// NewPollNetPanelRequest generates requests for PollNetPanel
+func NewPollNetPanelRequest(server string, id ID, params *PollNetPanelParams) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "id", runtime.ParamLocationPath, id)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/netpanels/%s/commands", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "session", runtime.ParamLocationQuery, params.Session); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+		if params.Wait != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "wait", runtime.ParamLocationQuery, *params.Wait); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewNetPanelEventRequest calls the generic NetPanelEvent builder with application/json body
+func NewNetPanelEventRequest(server string, id ID, body NetPanelEventJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewNetPanelEventRequestWithBody(server, id, "application/json", bodyReader)
+}
+
+// NewNetPanelEventRequestWithBody generates requests for NetPanelEvent with any type of body
+func NewNetPanelEventRequestWithBody(server string, id ID, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "id", runtime.ParamLocationPath, id)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/netpanels/%s/events", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
+// NewNetPanelHeartbeatRequest calls the generic NetPanelHeartbeat builder with application/json body
+func NewNetPanelHeartbeatRequest(server string, id ID, body NetPanelHeartbeatJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewNetPanelHeartbeatRequestWithBody(server, id, "application/json", bodyReader)
+}
+
+// NewNetPanelHeartbeatRequestWithBody generates requests for NetPanelHeartbeat with any type of body
+func NewNetPanelHeartbeatRequestWithBody(server string, id ID, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "id", runtime.ParamLocationPath, id)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/netpanels/%s/heartbeat", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
+// NewGetSpecRequest generates requests for GetSpec
+func NewGetSpecRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/openapi.yaml")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewListPagesRequest generates requests for ListPages
+func NewListPagesRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/pager")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewPageRequest generates requests for Page
+func NewPageRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/pager")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewClosePageRequest generates requests for ClosePage
+func NewClosePageRequest(server string, id int) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "id", runtime.ParamLocationPath, id)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/pager/%s", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("DELETE", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewListPanelsRequest generates requests for ListPanels
+func NewListPanelsRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/panels")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewListScenesRequest generates requests for ListScenes
+func NewListScenesRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/scenes")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewCreateSceneRequest calls the generic CreateScene builder with application/json body
+func NewCreateSceneRequest(server string, body CreateSceneJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewCreateSceneRequestWithBody(server, "application/json", bodyReader)
+}
+
+// NewCreateSceneRequestWithBody generates requests for CreateScene with any type of body
+func NewCreateSceneRequestWithBody(server string, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/scenes")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
+// NewDeleteSceneRequest generates requests for DeleteScene
+func NewDeleteSceneRequest(server string, id ID) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "id", runtime.ParamLocationPath, id)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/scenes/%s", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("DELETE", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetSceneRequest generates requests for GetScene
+func NewGetSceneRequest(server string, id ID) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "id", runtime.ParamLocationPath, id)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/scenes/%s", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewUpdateSceneRequest calls the generic UpdateScene builder with application/json body
+func NewUpdateSceneRequest(server string, id ID, body UpdateSceneJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewUpdateSceneRequestWithBody(server, id, "application/json", bodyReader)
+}
+
+// NewUpdateSceneRequestWithBody generates requests for UpdateScene with any type of body
+func NewUpdateSceneRequestWithBody(server string, id ID, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "id", runtime.ParamLocationPath, id)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/scenes/%s", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("PUT", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
+// NewRecallSceneRequest generates requests for RecallScene
+func NewRecallSceneRequest(server string, id ID, params *RecallSceneParams) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "id", runtime.ParamLocationPath, id)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/scenes/%s/recall", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.Zone != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", false, "zone", runtime.ParamLocationQuery, *params.Zone); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetSensorsRequest generates requests for GetSensors
+func NewGetSensorsRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/sensors")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetSensorHistoryRequest generates requests for GetSensorHistory
+func NewGetSensorHistoryRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/sensors/history")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetStatusRequest generates requests for GetStatus
+func NewGetStatusRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/api/v1/status")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewHealthzRequest generates requests for Healthz
+func NewHealthzRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/healthz")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewReadyzRequest generates requests for Readyz
+func NewReadyzRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/readyz")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func (c *Client) applyEditors(ctx context.Context, req *http.Request, additionalEditors []RequestEditorFn) error {
+	for _, r := range c.RequestEditors {
+		if err := r(ctx, req); err != nil {
+			return err
+		}
+	}
+	for _, r := range additionalEditors {
+		if err := r(ctx, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ClientWithResponses builds on ClientInterface to offer response payloads
+type ClientWithResponses struct {
+	ClientInterface
+}
+
+// NewClientWithResponses creates a new ClientWithResponses, which wraps
+// Client with return type handling
+func NewClientWithResponses(server string, opts ...ClientOption) (*ClientWithResponses, error) {
+	client, err := NewClient(server, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &ClientWithResponses{client}, nil
+}
+
+// WithBaseURL overrides the baseURL.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) error {
+		newBaseURL, err := url.Parse(baseURL)
+		if err != nil {
+			return err
+		}
+		c.Server = newBaseURL.String()
+		return nil
+	}
+}
+
+// ClientWithResponsesInterface is the interface specification for the client with responses above.
+type ClientWithResponsesInterface interface {
+	// ListAlarmsWithResponse request
+	ListAlarmsWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*ListAlarmsResponse, error)
+
+	// CreateAlarmWithBodyWithResponse request with any body
+	CreateAlarmWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*CreateAlarmResponse, error)
+
+	CreateAlarmWithResponse(ctx context.Context, body CreateAlarmJSONRequestBody, reqEditors ...RequestEditorFn) (*CreateAlarmResponse, error)
+
+	// DeleteAlarmWithResponse request
+	DeleteAlarmWithResponse(ctx context.Context, id ID, reqEditors ...RequestEditorFn) (*DeleteAlarmResponse, error)
+
+	// GetAlarmWithResponse request
+	GetAlarmWithResponse(ctx context.Context, id ID, reqEditors ...RequestEditorFn) (*GetAlarmResponse, error)
+
+	// UpdateAlarmWithBodyWithResponse request with any body
+	UpdateAlarmWithBodyWithResponse(ctx context.Context, id ID, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*UpdateAlarmResponse, error)
+
+	UpdateAlarmWithResponse(ctx context.Context, id ID, body UpdateAlarmJSONRequestBody, reqEditors ...RequestEditorFn) (*UpdateAlarmResponse, error)
+
+	// UnskipAlarmWithResponse request
+	UnskipAlarmWithResponse(ctx context.Context, id ID, reqEditors ...RequestEditorFn) (*UnskipAlarmResponse, error)
+
+	// SkipAlarmWithResponse request
+	SkipAlarmWithResponse(ctx context.Context, id ID, reqEditors ...RequestEditorFn) (*SkipAlarmResponse, error)
+
+	// StreamEventsWithResponse request
+	StreamEventsWithResponse(ctx context.Context, params *StreamEventsParams, reqEditors ...RequestEditorFn) (*StreamEventsResponse, error)
+
+	// PublishEventWithBodyWithResponse request with any body
+	PublishEventWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PublishEventResponse, error)
+
+	PublishEventWithResponse(ctx context.Context, body PublishEventJSONRequestBody, reqEditors ...RequestEditorFn) (*PublishEventResponse, error)
+
+	// GetLightsWithResponse request
+	GetLightsWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetLightsResponse, error)
+
+	// PutLightsWithBodyWithResponse request with any body
+	PutLightsWithBodyWithResponse(ctx context.Context, params *PutLightsParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PutLightsResponse, error)
+
+	PutLightsWithResponse(ctx context.Context, params *PutLightsParams, body PutLightsJSONRequestBody, reqEditors ...RequestEditorFn) (*PutLightsResponse, error)
+
+	// StopEffectWithResponse request
+	StopEffectWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*StopEffectResponse, error)
+
+	// PlayEffectWithBodyWithResponse request with any body
+	PlayEffectWithBodyWithResponse(ctx context.Context, params *PlayEffectParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PlayEffectResponse, error)
+
+	PlayEffectWithResponse(ctx context.Context, params *PlayEffectParams, body PlayEffectJSONRequestBody, reqEditors ...RequestEditorFn) (*PlayEffectResponse, error)
+
+	// CancelSleepWithResponse request
+	CancelSleepWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*CancelSleepResponse, error)
+
+	// StartSleepWithBodyWithResponse request with any body
+	StartSleepWithBodyWithResponse(ctx context.Context, params *StartSleepParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*StartSleepResponse, error)
+
+	StartSleepWithResponse(ctx context.Context, params *StartSleepParams, body StartSleepJSONRequestBody, reqEditors ...RequestEditorFn) (*StartSleepResponse, error)
+
+	// DismissWakeupWithResponse request
+	DismissWakeupWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*DismissWakeupResponse, error)
+
+	// StartWakeupWithBodyWithResponse request with any body
+	StartWakeupWithBodyWithResponse(ctx context.Context, params *StartWakeupParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*StartWakeupResponse, error)
+
+	StartWakeupWithResponse(ctx context.Context, params *StartWakeupParams, body StartWakeupJSONRequestBody, reqEditors ...RequestEditorFn) (*StartWakeupResponse, error)
+
+	// SnoozeWakeupWithResponse request
+	SnoozeWakeupWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*SnoozeWakeupResponse, error)
+
+	// ListZonesWithResponse request
+	ListZonesWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*ListZonesResponse, error)
+
+	// ListNetPanelsWithResponse request
+	ListNetPanelsWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*ListNetPanelsResponse, error)
+
+	// RegisterNetPanelWithBodyWithResponse request with any body
+	RegisterNetPanelWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*RegisterNetPanelResponse, error)
+
+	RegisterNetPanelWithResponse(ctx context.Context, body RegisterNetPanelJSONRequestBody, reqEditors ...RequestEditorFn) (*RegisterNetPanelResponse, error)
+
+	// PollNetPanelWithResponse request
+	PollNetPanelWithResponse(ctx context.Context, id ID, params *PollNetPanelParams, reqEditors ...RequestEditorFn) (*PollNetPanelResponse, error)
+
+	// NetPanelEventWithBodyWithResponse request with any body
+	NetPanelEventWithBodyWithResponse(ctx context.Context, id ID, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*NetPanelEventResponse, error)
+
+	NetPanelEventWithResponse(ctx context.Context, id ID, body NetPanelEventJSONRequestBody, reqEditors ...RequestEditorFn) (*NetPanelEventResponse, error)
+
+	// NetPanelHeartbeatWithBodyWithResponse request with any body
+	NetPanelHeartbeatWithBodyWithResponse(ctx context.Context, id ID, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*NetPanelHeartbeatResponse, error)
+
+	NetPanelHeartbeatWithResponse(ctx context.Context, id ID, body NetPanelHeartbeatJSONRequestBody, reqEditors ...RequestEditorFn) (*NetPanelHeartbeatResponse, error)
+
+	// GetSpecWithResponse request
+	GetSpecWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetSpecResponse, error)
+
+	// ListPagesWithResponse request
+	ListPagesWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*ListPagesResponse, error)
+
+	// PageWithResponse request
+	PageWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*PageResponse, error)
+
+	// ClosePageWithResponse request
+	ClosePageWithResponse(ctx context.Context, id int, reqEditors ...RequestEditorFn) (*ClosePageResponse, error)
+
+	// ListPanelsWithResponse request
+	ListPanelsWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*ListPanelsResponse, error)
+
+	// ListScenesWithResponse request
+	ListScenesWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*ListScenesResponse, error)
+
+	// CreateSceneWithBodyWithResponse request with any body
+	CreateSceneWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*CreateSceneResponse, error)
+
+	CreateSceneWithResponse(ctx context.Context, body CreateSceneJSONRequestBody, reqEditors ...RequestEditorFn) (*CreateSceneResponse, error)
+
+	// DeleteSceneWithResponse request
+	DeleteSceneWithResponse(ctx context.Context, id ID, reqEditors ...RequestEditorFn) (*DeleteSceneResponse, error)
+
+	// GetSceneWithResponse request
+	GetSceneWithResponse(ctx context.Context, id ID, reqEditors ...RequestEditorFn) (*GetSceneResponse, error)
+
+	// UpdateSceneWithBodyWithResponse request with any body
+	UpdateSceneWithBodyWithResponse(ctx context.Context, id ID, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*UpdateSceneResponse, error)
+
+	UpdateSceneWithResponse(ctx context.Context, id ID, body UpdateSceneJSONRequestBody, reqEditors ...RequestEditorFn) (*UpdateSceneResponse, error)
+
+	// RecallSceneWithResponse request
+	RecallSceneWithResponse(ctx context.Context, id ID, params *RecallSceneParams, reqEditors ...RequestEditorFn) (*RecallSceneResponse, error)
+
+	// GetSensorsWithResponse request
+	GetSensorsWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetSensorsResponse, error)
+
+	// GetSensorHistoryWithResponse request
+	GetSensorHistoryWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetSensorHistoryResponse, error)
+
+	// GetStatusWithResponse request
+	GetStatusWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetStatusResponse, error)
+
+	// HealthzWithResponse request
+	HealthzWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*HealthzResponse, error)
+
+	// ReadyzWithResponse request
+	ReadyzWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*ReadyzResponse, error)
+}
+
+type ListAlarmsResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *AlarmListResult
+	JSONDefault  *Failure
+}
+
+// Status returns HTTPResponse.Status
+func (r ListAlarmsResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ListAlarmsResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type CreateAlarmResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON201      *AlarmResult
+	JSONDefault  *Failure
+}
+
+// Status returns HTTPResponse.Status
+func (r CreateAlarmResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r CreateAlarmResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type DeleteAlarmResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSONDefault  *Failure
+}
+
+// Status returns HTTPResponse.Status
+func (r DeleteAlarmResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r DeleteAlarmResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetAlarmResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *AlarmResult
+	JSONDefault  *Failure
+}
+
+// Status returns HTTPResponse.Status
+func (r GetAlarmResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetAlarmResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type UpdateAlarmResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *AlarmResult
+	JSONDefault  *Failure
+}
+
+// Status returns HTTPResponse.Status
+func (r UpdateAlarmResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r UpdateAlarmResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type UnskipAlarmResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *AlarmResult
+	JSONDefault  *Failure
+}
+
+// Status returns HTTPResponse.Status
+func (r UnskipAlarmResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r UnskipAlarmResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type SkipAlarmResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *AlarmResult
+	JSONDefault  *Failure
+}
+
+// Status returns HTTPResponse.Status
+func (r SkipAlarmResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r SkipAlarmResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type StreamEventsResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSONDefault  *Failure
+}
+
+// Status returns HTTPResponse.Status
+func (r StreamEventsResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r StreamEventsResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type PublishEventResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON202      *Event
+	JSONDefault  *Failure
+}
+
+// Status returns HTTPResponse.Status
+func (r PublishEventResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r PublishEventResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetLightsResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *LightsResult
+	JSONDefault  *Failure
+}
+
+// Status returns HTTPResponse.Status
+func (r GetLightsResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetLightsResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type PutLightsResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *LightsResult
+	JSONDefault  *Failure
+}
+
+// Status returns HTTPResponse.Status
+func (r PutLightsResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r PutLightsResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type StopEffectResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *LightsResult
+	JSONDefault  *Failure
+}
+
+// Status returns HTTPResponse.Status
+func (r StopEffectResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r StopEffectResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type PlayEffectResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON202      *LightsResult
+	JSONDefault  *Failure
+}
+
+// Status returns HTTPResponse.Status
+func (r PlayEffectResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r PlayEffectResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type CancelSleepResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *LightsResult
+	JSONDefault  *Failure
+}
+
+// Status returns HTTPResponse.Status
+func (r CancelSleepResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r CancelSleepResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type StartSleepResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON202      *LightsResult
+	JSONDefault  *Failure
+}
+
+// Status returns HTTPResponse.Status
+func (r StartSleepResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r StartSleepResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type DismissWakeupResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *LightsResult
+	JSONDefault  *Failure
+}
+
+// Status returns HTTPResponse.Status
+func (r DismissWakeupResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r DismissWakeupResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type StartWakeupResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON202      *LightsResult
+	JSONDefault  *Failure
+}
+
+// Status returns HTTPResponse.Status
+func (r StartWakeupResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r StartWakeupResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type SnoozeWakeupResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *LightsResult
+	JSONDefault  *Failure
+}
+
+// Status returns HTTPResponse.Status
+func (r SnoozeWakeupResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r SnoozeWakeupResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ListZonesResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *[]ZoneState
+	JSONDefault  *Failure
+}
+
+// Status returns HTTPResponse.Status
+func (r ListZonesResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ListZonesResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ListNetPanelsResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *[]NetPanelStatus
+	JSONDefault  *Failure
+}
+
+// Status returns HTTPResponse.Status
+func (r ListNetPanelsResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ListNetPanelsResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type RegisterNetPanelResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *NetPanelWelcome
+	JSONDefault  *Failure
+}
+
+// Status returns HTTPResponse.Status
+func (r RegisterNetPanelResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r RegisterNetPanelResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type PollNetPanelResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *NetPanelCommandsResult
+	JSONDefault  *Failure
+}
+
+// Status returns HTTPResponse.Status
+func (r PollNetPanelResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r PollNetPanelResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type NetPanelEventResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *NetPanelCommandsResult
+	JSONDefault  *Failure
+}
+
+// Status returns HTTPResponse.Status
+func (r NetPanelEventResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r NetPanelEventResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type NetPanelHeartbeatResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *NetPanelCommandsResult
+	JSONDefault  *Failure
+}
+
+// Status returns HTTPResponse.Status
+func (r NetPanelHeartbeatResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r NetPanelHeartbeatResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetSpecResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+}
+
+// Status returns HTTPResponse.Status
+func (r GetSpecResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetSpecResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ListPagesResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *[]Page
+	JSONDefault  *Failure
+}
+
+// Status returns HTTPResponse.Status
+func (r ListPagesResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ListPagesResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type PageResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON202      *StatusResponse
+	JSONDefault  *Failure
+}
+
+// Status returns HTTPResponse.Status
+func (r PageResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r PageResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ClosePageResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSONDefault  *Failure
+}
+
+// Status returns HTTPResponse.Status
+func (r ClosePageResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ClosePageResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ListPanelsResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *[]PanelState
+	JSONDefault  *Failure
+}
+
+// Status returns HTTPResponse.Status
+func (r ListPanelsResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ListPanelsResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ListScenesResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *SceneListResult
+	JSONDefault  *Failure
+}
+
+// Status returns HTTPResponse.Status
+func (r ListScenesResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ListScenesResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type CreateSceneResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON201      *SceneResult
+	JSONDefault  *Failure
+}
+
+// Status returns HTTPResponse.Status
+func (r CreateSceneResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r CreateSceneResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type DeleteSceneResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSONDefault  *Failure
+}
+
+// Status returns HTTPResponse.Status
+func (r DeleteSceneResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r DeleteSceneResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetSceneResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *SceneResult
+	JSONDefault  *Failure
+}
+
+// Status returns HTTPResponse.Status
+func (r GetSceneResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetSceneResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type UpdateSceneResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *SceneResult
+	JSONDefault  *Failure
+}
+
+// Status returns HTTPResponse.Status
+func (r UpdateSceneResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r UpdateSceneResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type RecallSceneResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *LightsResult
+	JSONDefault  *Failure
+}
+
+// Status returns HTTPResponse.Status
+func (r RecallSceneResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r RecallSceneResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetSensorsResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *SensorsState
+	JSONDefault  *Failure
+}
+
+// Status returns HTTPResponse.Status
+func (r GetSensorsResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetSensorsResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetSensorHistoryResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *[]SensorSample
+	JSONDefault  *Failure
+}
+
+// Status returns HTTPResponse.Status
+func (r GetSensorHistoryResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetSensorHistoryResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetStatusResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *NodeStatus
+	JSONDefault  *Failure
+}
+
+// Status returns HTTPResponse.Status
+func (r GetStatusResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetStatusResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type HealthzResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+}
+
+// Status returns HTTPResponse.Status
+func (r HealthzResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r HealthzResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ReadyzResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+}
+
+// Status returns HTTPResponse.Status
+func (r ReadyzResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ReadyzResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+// ListAlarmsWithResponse request returning *ListAlarmsResponse
+func (c *ClientWithResponses) ListAlarmsWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*ListAlarmsResponse, error) {
+	rsp, err := c.ListAlarms(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseListAlarmsResponse(rsp)
+}
+
+// CreateAlarmWithBodyWithResponse request with arbitrary body returning *CreateAlarmResponse
+func (c *ClientWithResponses) CreateAlarmWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*CreateAlarmResponse, error) {
+	rsp, err := c.CreateAlarmWithBody(ctx, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCreateAlarmResponse(rsp)
+}
+
+func (c *ClientWithResponses) CreateAlarmWithResponse(ctx context.Context, body CreateAlarmJSONRequestBody, reqEditors ...RequestEditorFn) (*CreateAlarmResponse, error) {
+	rsp, err := c.CreateAlarm(ctx, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCreateAlarmResponse(rsp)
+}
+
+// DeleteAlarmWithResponse request returning *DeleteAlarmResponse
+func (c *ClientWithResponses) DeleteAlarmWithResponse(ctx context.Context, id ID, reqEditors ...RequestEditorFn) (*DeleteAlarmResponse, error) {
+	rsp, err := c.DeleteAlarm(ctx, id, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDeleteAlarmResponse(rsp)
+}
+
+// GetAlarmWithResponse request returning *GetAlarmResponse
+func (c *ClientWithResponses) GetAlarmWithResponse(ctx context.Context, id ID, reqEditors ...RequestEditorFn) (*GetAlarmResponse, error) {
+	rsp, err := c.GetAlarm(ctx, id, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetAlarmResponse(rsp)
+}
+
+// UpdateAlarmWithBodyWithResponse request with arbitrary body returning *UpdateAlarmResponse
+func (c *ClientWithResponses) UpdateAlarmWithBodyWithResponse(ctx context.Context, id ID, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*UpdateAlarmResponse, error) {
+	rsp, err := c.UpdateAlarmWithBody(ctx, id, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseUpdateAlarmResponse(rsp)
+}
+
+func (c *ClientWithResponses) UpdateAlarmWithResponse(ctx context.Context, id ID, body UpdateAlarmJSONRequestBody, reqEditors ...RequestEditorFn) (*UpdateAlarmResponse, error) {
+	rsp, err := c.UpdateAlarm(ctx, id, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseUpdateAlarmResponse(rsp)
+}
+
+// UnskipAlarmWithResponse request returning *UnskipAlarmResponse
+func (c *ClientWithResponses) UnskipAlarmWithResponse(ctx context.Context, id ID, reqEditors ...RequestEditorFn) (*UnskipAlarmResponse, error) {
+	rsp, err := c.UnskipAlarm(ctx, id, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseUnskipAlarmResponse(rsp)
+}
+
+// SkipAlarmWithResponse request returning *SkipAlarmResponse
+func (c *ClientWithResponses) SkipAlarmWithResponse(ctx context.Context, id ID, reqEditors ...RequestEditorFn) (*SkipAlarmResponse, error) {
+	rsp, err := c.SkipAlarm(ctx, id, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSkipAlarmResponse(rsp)
+}
+
+// StreamEventsWithResponse request returning *StreamEventsResponse
+func (c *ClientWithResponses) StreamEventsWithResponse(ctx context.Context, params *StreamEventsParams, reqEditors ...RequestEditorFn) (*StreamEventsResponse, error) {
+	rsp, err := c.StreamEvents(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseStreamEventsResponse(rsp)
+}
+
+// PublishEventWithBodyWithResponse request with arbitrary body returning *PublishEventResponse
+func (c *ClientWithResponses) PublishEventWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PublishEventResponse, error) {
+	rsp, err := c.PublishEventWithBody(ctx, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePublishEventResponse(rsp)
+}
+
+func (c *ClientWithResponses) PublishEventWithResponse(ctx context.Context, body PublishEventJSONRequestBody, reqEditors ...RequestEditorFn) (*PublishEventResponse, error) {
+	rsp, err := c.PublishEvent(ctx, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePublishEventResponse(rsp)
+}
+
+// GetLightsWithResponse request returning *GetLightsResponse
+func (c *ClientWithResponses) GetLightsWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetLightsResponse, error) {
+	rsp, err := c.GetLights(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetLightsResponse(rsp)
+}
+
+// PutLightsWithBodyWithResponse request with arbitrary body returning *PutLightsResponse
+func (c *ClientWithResponses) PutLightsWithBodyWithResponse(ctx context.Context, params *PutLightsParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PutLightsResponse, error) {
+	rsp, err := c.PutLightsWithBody(ctx, params, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePutLightsResponse(rsp)
+}
+
+func (c *ClientWithResponses) PutLightsWithResponse(ctx context.Context, params *PutLightsParams, body PutLightsJSONRequestBody, reqEditors ...RequestEditorFn) (*PutLightsResponse, error) {
+	rsp, err := c.PutLights(ctx, params, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePutLightsResponse(rsp)
+}
+
+// StopEffectWithResponse request returning *StopEffectResponse
+func (c *ClientWithResponses) StopEffectWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*StopEffectResponse, error) {
+	rsp, err := c.StopEffect(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseStopEffectResponse(rsp)
+}
+
+// PlayEffectWithBodyWithResponse request with arbitrary body returning *PlayEffectResponse
+func (c *ClientWithResponses) PlayEffectWithBodyWithResponse(ctx context.Context, params *PlayEffectParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PlayEffectResponse, error) {
+	rsp, err := c.PlayEffectWithBody(ctx, params, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePlayEffectResponse(rsp)
+}
+
+func (c *ClientWithResponses) PlayEffectWithResponse(ctx context.Context, params *PlayEffectParams, body PlayEffectJSONRequestBody, reqEditors ...RequestEditorFn) (*PlayEffectResponse, error) {
+	rsp, err := c.PlayEffect(ctx, params, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePlayEffectResponse(rsp)
+}
+
+// CancelSleepWithResponse request returning *CancelSleepResponse
+func (c *ClientWithResponses) CancelSleepWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*CancelSleepResponse, error) {
+	rsp, err := c.CancelSleep(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCancelSleepResponse(rsp)
+}
+
+// StartSleepWithBodyWithResponse request with arbitrary body returning *StartSleepResponse
+func (c *ClientWithResponses) StartSleepWithBodyWithResponse(ctx context.Context, params *StartSleepParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*StartSleepResponse, error) {
+	rsp, err := c.StartSleepWithBody(ctx, params, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseStartSleepResponse(rsp)
+}
+
+func (c *ClientWithResponses) StartSleepWithResponse(ctx context.Context, params *StartSleepParams, body StartSleepJSONRequestBody, reqEditors ...RequestEditorFn) (*StartSleepResponse, error) {
+	rsp, err := c.StartSleep(ctx, params, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseStartSleepResponse(rsp)
+}
+
+// DismissWakeupWithResponse request returning *DismissWakeupResponse
+func (c *ClientWithResponses) DismissWakeupWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*DismissWakeupResponse, error) {
+	rsp, err := c.DismissWakeup(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDismissWakeupResponse(rsp)
+}
+
+// StartWakeupWithBodyWithResponse request with arbitrary body returning *StartWakeupResponse
+func (c *ClientWithResponses) StartWakeupWithBodyWithResponse(ctx context.Context, params *StartWakeupParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*StartWakeupResponse, error) {
+	rsp, err := c.StartWakeupWithBody(ctx, params, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseStartWakeupResponse(rsp)
+}
+
+func (c *ClientWithResponses) StartWakeupWithResponse(ctx context.Context, params *StartWakeupParams, body StartWakeupJSONRequestBody, reqEditors ...RequestEditorFn) (*StartWakeupResponse, error) {
+	rsp, err := c.StartWakeup(ctx, params, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseStartWakeupResponse(rsp)
+}
+
+// SnoozeWakeupWithResponse request returning *SnoozeWakeupResponse
+func (c *ClientWithResponses) SnoozeWakeupWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*SnoozeWakeupResponse, error) {
+	rsp, err := c.SnoozeWakeup(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSnoozeWakeupResponse(rsp)
+}
+
+// ListZonesWithResponse request returning *ListZonesResponse
+func (c *ClientWithResponses) ListZonesWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*ListZonesResponse, error) {
+	rsp, err := c.ListZones(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseListZonesResponse(rsp)
+}
+
+// ListNetPanelsWithResponse request returning *ListNetPanelsResponse
+func (c *ClientWithResponses) ListNetPanelsWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*ListNetPanelsResponse, error) {
+	rsp, err := c.ListNetPanels(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseListNetPanelsResponse(rsp)
+}
+
+// RegisterNetPanelWithBodyWithResponse request with arbitrary body returning *RegisterNetPanelResponse
+func (c *ClientWithResponses) RegisterNetPanelWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*RegisterNetPanelResponse, error) {
+	rsp, err := c.RegisterNetPanelWithBody(ctx, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRegisterNetPanelResponse(rsp)
+}
+
+func (c *ClientWithResponses) RegisterNetPanelWithResponse(ctx context.Context, body RegisterNetPanelJSONRequestBody, reqEditors ...RequestEditorFn) (*RegisterNetPanelResponse, error) {
+	rsp, err := c.RegisterNetPanel(ctx, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRegisterNetPanelResponse(rsp)
+}
+
+// PollNetPanelWithResponse request returning *PollNetPanelResponse
+func (c *ClientWithResponses) PollNetPanelWithResponse(ctx context.Context, id ID, params *PollNetPanelParams, reqEditors ...RequestEditorFn) (*PollNetPanelResponse, error) {
+	rsp, err := c.PollNetPanel(ctx, id, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePollNetPanelResponse(rsp)
+}
+
+// NetPanelEventWithBodyWithResponse request with arbitrary body returning *NetPanelEventResponse
+func (c *ClientWithResponses) NetPanelEventWithBodyWithResponse(ctx context.Context, id ID, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*NetPanelEventResponse, error) {
+	rsp, err := c.NetPanelEventWithBody(ctx, id, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseNetPanelEventResponse(rsp)
+}
+
+func (c *ClientWithResponses) NetPanelEventWithResponse(ctx context.Context, id ID, body NetPanelEventJSONRequestBody, reqEditors ...RequestEditorFn) (*NetPanelEventResponse, error) {
+	rsp, err := c.NetPanelEvent(ctx, id, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseNetPanelEventResponse(rsp)
+}
+
+// NetPanelHeartbeatWithBodyWithResponse request with arbitrary body returning *NetPanelHeartbeatResponse
+func (c *ClientWithResponses) NetPanelHeartbeatWithBodyWithResponse(ctx context.Context, id ID, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*NetPanelHeartbeatResponse, error) {
+	rsp, err := c.NetPanelHeartbeatWithBody(ctx, id, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseNetPanelHeartbeatResponse(rsp)
+}
+
+func (c *ClientWithResponses) NetPanelHeartbeatWithResponse(ctx context.Context, id ID, body NetPanelHeartbeatJSONRequestBody, reqEditors ...RequestEditorFn) (*NetPanelHeartbeatResponse, error) {
+	rsp, err := c.NetPanelHeartbeat(ctx, id, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseNetPanelHeartbeatResponse(rsp)
+}
+
+// GetSpecWithResponse request returning *GetSpecResponse
+func (c *ClientWithResponses) GetSpecWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetSpecResponse, error) {
+	rsp, err := c.GetSpec(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetSpecResponse(rsp)
+}
+
+// ListPagesWithResponse request returning *ListPagesResponse
+func (c *ClientWithResponses) ListPagesWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*ListPagesResponse, error) {
+	rsp, err := c.ListPages(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseListPagesResponse(rsp)
+}
+
+// PageWithResponse request returning *PageResponse
+func (c *ClientWithResponses) PageWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*PageResponse, error) {
+	rsp, err := c.Page(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePageResponse(rsp)
+}
+
+// ClosePageWithResponse request returning *ClosePageResponse
+func (c *ClientWithResponses) ClosePageWithResponse(ctx context.Context, id int, reqEditors ...RequestEditorFn) (*ClosePageResponse, error) {
+	rsp, err := c.ClosePage(ctx, id, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseClosePageResponse(rsp)
+}
+
+// ListPanelsWithResponse request returning *ListPanelsResponse
+func (c *ClientWithResponses) ListPanelsWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*ListPanelsResponse, error) {
+	rsp, err := c.ListPanels(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseListPanelsResponse(rsp)
+}
+
+// ListScenesWithResponse request returning *ListScenesResponse
+func (c *ClientWithResponses) ListScenesWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*ListScenesResponse, error) {
+	rsp, err := c.ListScenes(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseListScenesResponse(rsp)
+}
+
+// CreateSceneWithBodyWithResponse request with arbitrary body returning *CreateSceneResponse
+func (c *ClientWithResponses) CreateSceneWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*CreateSceneResponse, error) {
+	rsp, err := c.CreateSceneWithBody(ctx, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCreateSceneResponse(rsp)
+}
+
+func (c *ClientWithResponses) CreateSceneWithResponse(ctx context.Context, body CreateSceneJSONRequestBody, reqEditors ...RequestEditorFn) (*CreateSceneResponse, error) {
+	rsp, err := c.CreateScene(ctx, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCreateSceneResponse(rsp)
+}
+
+// DeleteSceneWithResponse request returning *DeleteSceneResponse
+func (c *ClientWithResponses) DeleteSceneWithResponse(ctx context.Context, id ID, reqEditors ...RequestEditorFn) (*DeleteSceneResponse, error) {
+	rsp, err := c.DeleteScene(ctx, id, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDeleteSceneResponse(rsp)
+}
+
+// GetSceneWithResponse request returning *GetSceneResponse
+func (c *ClientWithResponses) GetSceneWithResponse(ctx context.Context, id ID, reqEditors ...RequestEditorFn) (*GetSceneResponse, error) {
+	rsp, err := c.GetScene(ctx, id, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetSceneResponse(rsp)
+}
+
+// UpdateSceneWithBodyWithResponse request with arbitrary body returning *UpdateSceneResponse
+func (c *ClientWithResponses) UpdateSceneWithBodyWithResponse(ctx context.Context, id ID, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*UpdateSceneResponse, error) {
+	rsp, err := c.UpdateSceneWithBody(ctx, id, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseUpdateSceneResponse(rsp)
+}
+
+func (c *ClientWithResponses) UpdateSceneWithResponse(ctx context.Context, id ID, body UpdateSceneJSONRequestBody, reqEditors ...RequestEditorFn) (*UpdateSceneResponse, error) {
+	rsp, err := c.UpdateScene(ctx, id, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseUpdateSceneResponse(rsp)
+}
+
+// RecallSceneWithResponse request returning *RecallSceneResponse
+func (c *ClientWithResponses) RecallSceneWithResponse(ctx context.Context, id ID, params *RecallSceneParams, reqEditors ...RequestEditorFn) (*RecallSceneResponse, error) {
+	rsp, err := c.RecallScene(ctx, id, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRecallSceneResponse(rsp)
+}
+
+// GetSensorsWithResponse request returning *GetSensorsResponse
+func (c *ClientWithResponses) GetSensorsWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetSensorsResponse, error) {
+	rsp, err := c.GetSensors(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetSensorsResponse(rsp)
+}
+
+// GetSensorHistoryWithResponse request returning *GetSensorHistoryResponse
+func (c *ClientWithResponses) GetSensorHistoryWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetSensorHistoryResponse, error) {
+	rsp, err := c.GetSensorHistory(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetSensorHistoryResponse(rsp)
+}
+
+// GetStatusWithResponse request returning *GetStatusResponse
+func (c *ClientWithResponses) GetStatusWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetStatusResponse, error) {
+	rsp, err := c.GetStatus(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetStatusResponse(rsp)
+}
+
+// HealthzWithResponse request returning *HealthzResponse
+func (c *ClientWithResponses) HealthzWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*HealthzResponse, error) {
+	rsp, err := c.Healthz(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseHealthzResponse(rsp)
+}
+
+// ReadyzWithResponse request returning *ReadyzResponse
+func (c *ClientWithResponses) ReadyzWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*ReadyzResponse, error) {
+	rsp, err := c.Readyz(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseReadyzResponse(rsp)
+}
+
+// ParseListAlarmsResponse parses an HTTP response from a ListAlarmsWithResponse call
+func ParseListAlarmsResponse(rsp *http.Response) (*ListAlarmsResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ListAlarmsResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest AlarmListResult
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Failure
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseCreateAlarmResponse parses an HTTP response from a CreateAlarmWithResponse call
+func ParseCreateAlarmResponse(rsp *http.Response) (*CreateAlarmResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &CreateAlarmResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 201:
+		var dest AlarmResult
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON201 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Failure
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseDeleteAlarmResponse parses an HTTP response from a DeleteAlarmWithResponse call
+func ParseDeleteAlarmResponse(rsp *http.Response) (*DeleteAlarmResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &DeleteAlarmResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Failure
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetAlarmResponse parses an HTTP response from a GetAlarmWithResponse call
+func ParseGetAlarmResponse(rsp *http.Response) (*GetAlarmResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetAlarmResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest AlarmResult
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Failure
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseUpdateAlarmResponse parses an HTTP response from a UpdateAlarmWithResponse call
+func ParseUpdateAlarmResponse(rsp *http.Response) (*UpdateAlarmResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &UpdateAlarmResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest AlarmResult
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Failure
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseUnskipAlarmResponse parses an HTTP response from a UnskipAlarmWithResponse call
+func ParseUnskipAlarmResponse(rsp *http.Response) (*UnskipAlarmResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &UnskipAlarmResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest AlarmResult
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Failure
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseSkipAlarmResponse parses an HTTP response from a SkipAlarmWithResponse call
+func ParseSkipAlarmResponse(rsp *http.Response) (*SkipAlarmResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &SkipAlarmResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest AlarmResult
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Failure
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseStreamEventsResponse parses an HTTP response from a StreamEventsWithResponse call
+func ParseStreamEventsResponse(rsp *http.Response) (*StreamEventsResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &StreamEventsResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Failure
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParsePublishEventResponse parses an HTTP response from a PublishEventWithResponse call
+func ParsePublishEventResponse(rsp *http.Response) (*PublishEventResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &PublishEventResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 202:
+		var dest Event
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON202 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Failure
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetLightsResponse parses an HTTP response from a GetLightsWithResponse call
+func ParseGetLightsResponse(rsp *http.Response) (*GetLightsResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetLightsResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest LightsResult
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Failure
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParsePutLightsResponse parses an HTTP response from a PutLightsWithResponse call
+func ParsePutLightsResponse(rsp *http.Response) (*PutLightsResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &PutLightsResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest LightsResult
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Failure
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseStopEffectResponse parses an HTTP response from a StopEffectWithResponse call
+func ParseStopEffectResponse(rsp *http.Response) (*StopEffectResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &StopEffectResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest LightsResult
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Failure
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParsePlayEffectResponse parses an HTTP response from a PlayEffectWithResponse call
+func ParsePlayEffectResponse(rsp *http.Response) (*PlayEffectResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &PlayEffectResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 202:
+		var dest LightsResult
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON202 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Failure
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseCancelSleepResponse parses an HTTP response from a CancelSleepWithResponse call
+func ParseCancelSleepResponse(rsp *http.Response) (*CancelSleepResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &CancelSleepResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest LightsResult
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Failure
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseStartSleepResponse parses an HTTP response from a StartSleepWithResponse call
+func ParseStartSleepResponse(rsp *http.Response) (*StartSleepResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &StartSleepResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 202:
+		var dest LightsResult
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON202 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Failure
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseDismissWakeupResponse parses an HTTP response from a DismissWakeupWithResponse call
+func ParseDismissWakeupResponse(rsp *http.Response) (*DismissWakeupResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &DismissWakeupResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest LightsResult
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Failure
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseStartWakeupResponse parses an HTTP response from a StartWakeupWithResponse call
+func ParseStartWakeupResponse(rsp *http.Response) (*StartWakeupResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &StartWakeupResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 202:
+		var dest LightsResult
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON202 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Failure
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseSnoozeWakeupResponse parses an HTTP response from a SnoozeWakeupWithResponse call
+func ParseSnoozeWakeupResponse(rsp *http.Response) (*SnoozeWakeupResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &SnoozeWakeupResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest LightsResult
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Failure
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseListZonesResponse parses an HTTP response from a ListZonesWithResponse call
+func ParseListZonesResponse(rsp *http.Response) (*ListZonesResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ListZonesResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest []ZoneState
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Failure
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseListNetPanelsResponse parses an HTTP response from a ListNetPanelsWithResponse call
+func ParseListNetPanelsResponse(rsp *http.Response) (*ListNetPanelsResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ListNetPanelsResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest []NetPanelStatus
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Failure
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseRegisterNetPanelResponse parses an HTTP response from a RegisterNetPanelWithResponse call
+func ParseRegisterNetPanelResponse(rsp *http.Response) (*RegisterNetPanelResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &RegisterNetPanelResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest NetPanelWelcome
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Failure
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParsePollNetPanelResponse parses an HTTP response from a PollNetPanelWithResponse call
+func ParsePollNetPanelResponse(rsp *http.Response) (*PollNetPanelResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &PollNetPanelResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest NetPanelCommandsResult
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Failure
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseNetPanelEventResponse parses an HTTP response from a NetPanelEventWithResponse call
+func ParseNetPanelEventResponse(rsp *http.Response) (*NetPanelEventResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &NetPanelEventResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest NetPanelCommandsResult
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Failure
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseNetPanelHeartbeatResponse parses an HTTP response from a NetPanelHeartbeatWithResponse call
+func ParseNetPanelHeartbeatResponse(rsp *http.Response) (*NetPanelHeartbeatResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &NetPanelHeartbeatResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest NetPanelCommandsResult
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Failure
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetSpecResponse parses an HTTP response from a GetSpecWithResponse call
+func ParseGetSpecResponse(rsp *http.Response) (*GetSpecResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetSpecResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	return response, nil
+}
+
+// ParseListPagesResponse parses an HTTP response from a ListPagesWithResponse call
+func ParseListPagesResponse(rsp *http.Response) (*ListPagesResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ListPagesResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest []Page
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Failure
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParsePageResponse parses an HTTP response from a PageWithResponse call
+func ParsePageResponse(rsp *http.Response) (*PageResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &PageResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 202:
+		var dest StatusResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON202 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Failure
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseClosePageResponse parses an HTTP response from a ClosePageWithResponse call
+func ParseClosePageResponse(rsp *http.Response) (*ClosePageResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ClosePageResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Failure
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseListPanelsResponse parses an HTTP response from a ListPanelsWithResponse call
+func ParseListPanelsResponse(rsp *http.Response) (*ListPanelsResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ListPanelsResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest []PanelState
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Failure
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseListScenesResponse parses an HTTP response from a ListScenesWithResponse call
+func ParseListScenesResponse(rsp *http.Response) (*ListScenesResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ListScenesResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest SceneListResult
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Failure
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseCreateSceneResponse parses an HTTP response from a CreateSceneWithResponse call
+func ParseCreateSceneResponse(rsp *http.Response) (*CreateSceneResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &CreateSceneResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 201:
+		var dest SceneResult
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON201 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Failure
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseDeleteSceneResponse parses an HTTP response from a DeleteSceneWithResponse call
+func ParseDeleteSceneResponse(rsp *http.Response) (*DeleteSceneResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &DeleteSceneResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Failure
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetSceneResponse parses an HTTP response from a GetSceneWithResponse call
+func ParseGetSceneResponse(rsp *http.Response) (*GetSceneResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetSceneResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest SceneResult
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Failure
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseUpdateSceneResponse parses an HTTP response from a UpdateSceneWithResponse call
+func ParseUpdateSceneResponse(rsp *http.Response) (*UpdateSceneResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &UpdateSceneResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest SceneResult
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Failure
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseRecallSceneResponse parses an HTTP response from a RecallSceneWithResponse call
+func ParseRecallSceneResponse(rsp *http.Response) (*RecallSceneResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &RecallSceneResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest LightsResult
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Failure
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetSensorsResponse parses an HTTP response from a GetSensorsWithResponse call
+func ParseGetSensorsResponse(rsp *http.Response) (*GetSensorsResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetSensorsResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest SensorsState
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Failure
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetSensorHistoryResponse parses an HTTP response from a GetSensorHistoryWithResponse call
+func ParseGetSensorHistoryResponse(rsp *http.Response) (*GetSensorHistoryResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetSensorHistoryResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest []SensorSample
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Failure
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetStatusResponse parses an HTTP response from a GetStatusWithResponse call
+func ParseGetStatusResponse(rsp *http.Response) (*GetStatusResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetStatusResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest NodeStatus
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Failure
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseHealthzResponse parses an HTTP response from a HealthzWithResponse call
+func ParseHealthzResponse(rsp *http.Response) (*HealthzResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &HealthzResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	return response, nil
+}
+
+// ParseReadyzResponse parses an HTTP response from a ReadyzWithResponse call
+func ParseReadyzResponse(rsp *http.Response) (*ReadyzResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ReadyzResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	return response, nil
+}
@@ -0,0 +1,18 @@
+// Package apiclient is a typed client for the bedroom node's /api/v1 API,
+// generated from the OpenAPI description in openapi.yaml.
+//
+// Regenerate it after changing the API:
+//
+//	go generate ./pkg/apiclient
+package apiclient
+
+import (
+	_ "embed"
+)
+
+//go:generate go run github.com/oapi-codegen/oapi-codegen/v2/cmd/oapi-codegen@v2.5.1 -config oapi-codegen.yaml openapi.yaml
+
+// Spec is the OpenAPI 3 description of the node API, as YAML.
+//
+//go:embed openapi.yaml
+var Spec []byte
@@ -0,0 +1,50 @@
+package bh1750
+
+import (
+	"fmt"
+	"time"
+
+	"periph.io/x/conn/v3/i2c"
+)
+
+// DefaultAddress is the sensor's I2C address with its ADDR pin low. With
+// ADDR high it is 0x5C.
+const DefaultAddress uint16 = 0x23
+
+const (
+	opPowerOn        = 0x01
+	opContinuousHRes = 0x10 // 1 lux resolution, 120ms per measurement
+)
+
+// Dev is a BH1750 ambient light sensor on an I2C bus.
+type Dev struct {
+	dev i2c.Dev
+}
+
+// New powers on the sensor and starts it measuring continuously.
+func New(bus i2c.Bus, addr uint16) (*Dev, error) {
+	d := &Dev{dev: i2c.Dev{Bus: bus, Addr: addr}}
+	if err := d.dev.Tx([]byte{opPowerOn}, nil); err != nil {
+		return nil, err
+	}
+	if err := d.dev.Tx([]byte{opContinuousHRes}, nil); err != nil {
+		return nil, err
+	}
+	// Wait out the first measurement, which takes up to 180ms
+	time.Sleep(180 * time.Millisecond)
+	return d, nil
+}
+
+func (d *Dev) String() string {
+	return fmt.Sprintf("BH1750(%s)", d.dev.String())
+}
+
+// ReadLux reads the latest measurement, in lux.
+func (d *Dev) ReadLux() (float64, error) {
+	r := make([]byte, 2)
+	if err := d.dev.Tx(nil, r); err != nil {
+		return 0, err
+	}
+	// The count is 1.2 per lux at the default measurement time
+	return float64(uint16(r[0])<<8|uint16(r[1])) / 1.2, nil
+}
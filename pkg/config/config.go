@@ -8,6 +8,11 @@ import (
 	"github.com/influxdata/influxdb-client-go/v2/api"
 	"github.com/joho/godotenv"
 	"github.com/klaital/max31855"
+	"github.com/klaital/wannetiot/pkg/ambient"
+	"github.com/klaital/wannetiot/pkg/bh1750"
+	"github.com/klaital/wannetiot/pkg/health"
+	"github.com/klaital/wannetiot/pkg/mcp3008"
+	"github.com/klaital/wannetiot/pkg/ratelimit"
 	"github.com/ryszard/sds011/go/sds011"
 	log "github.com/sirupsen/logrus"
 	"github.com/warthog618/gpiod"
@@ -15,6 +20,8 @@ import (
 	"periph.io/x/conn/v3/driver/driverreg"
 	"periph.io/x/conn/v3/gpio"
 	"periph.io/x/conn/v3/gpio/gpioreg"
+	"periph.io/x/conn/v3/i2c"
+	"periph.io/x/conn/v3/i2c/i2creg"
 	"periph.io/x/conn/v3/spi"
 	"periph.io/x/conn/v3/spi/spireg"
 	"periph.io/x/host/v3"
@@ -29,6 +36,30 @@ type Config struct {
 	LogLevel       log.Level
 	Logger         *log.Logger
 	WakeupDuration time.Duration `env:"WAKEUP_DURATION" envDefault:"30m"`
+	// WakeupCurve selects the default wakeup curve: sunrise, linear, or custom
+	WakeupCurve string `env:"WAKEUP_CURVE" envDefault:"sunrise"`
+	// WakeupKeyframes defines the "custom" wakeup curve, as "at:colour:brightness,..."
+	WakeupKeyframes      string        `env:"WAKEUP_KEYFRAMES"`
+	WakeupStepInterval   time.Duration `env:"WAKEUP_STEP" envDefault:"250ms"`
+	WakeupSnoozeDuration time.Duration `env:"WAKEUP_SNOOZE_DURATION" envDefault:"9m"`
+	WakeupSnoozeLimit    int           `env:"WAKEUP_SNOOZE_LIMIT" envDefault:"3"`
+	// The audible alarm at the end of the wakeup, played on the listed control panels' speakers
+	WakeupFinale         bool          `env:"WAKEUP_FINALE" envDefault:"false"`
+	WakeupFinaleMax      time.Duration `env:"WAKEUP_FINALE_MAX" envDefault:"10m"`
+	WakeupFinaleInterval time.Duration `env:"WAKEUP_FINALE_INTERVAL" envDefault:"10s"`
+	WakeupFinalePanels   []int         `env:"WAKEUP_FINALE_PANELS" envDefault:"1,2"`
+	WakeupFinaleEffect   string        `env:"WAKEUP_FINALE_EFFECT"` // light effect played as the alarm starts, such as "flash,count=5"
+	// WakeupFinaleNetPanels lists the IDs of the network panels to play the alarm on as well
+	WakeupFinaleNetPanels []string `env:"WAKEUP_FINALE_NETPANELS"`
+	// The wind-down sleep timer fades the lights out, optionally shifting them to SleepWarmKelvin first
+	SleepDuration     time.Duration `env:"SLEEP_DURATION" envDefault:"30m"`
+	SleepStepInterval time.Duration `env:"SLEEP_STEP" envDefault:"250ms"`
+	SleepWarm         bool          `env:"SLEEP_WARM" envDefault:"true"`
+	SleepWarmKelvin   float64       `env:"SLEEP_WARM_KELVIN" envDefault:"1800"`
+	SleepWarmDuration time.Duration `env:"SLEEP_WARM_DURATION" envDefault:"2m"`
+	// Wakeup alarms are saved to AlarmsFile, and scheduled in AlarmsTimezone ("Local" for the system zone)
+	AlarmsFile     string `env:"ALARMS_FILE" envDefault:"alarms.json"`
+	AlarmsTimezone string `env:"ALARMS_TIMEZONE" envDefault:"Local"`
 
 	// InfluxDB
 	InfluxHost       string `env:"INFLUX_HOST"`
@@ -39,6 +70,8 @@ type Config struct {
 	influxClient     api.WriteAPIBlocking
 
 	// RF Remote Control
+	RadioEnabled       bool          `env:"RF_ENABLED" envDefault:"false"`
+	RadioScenes        []string      `env:"RF_SCENES" envDefault:"full,low,off"` // scenes recalled by buttons A, B and C
 	RadioWaitTimeout   time.Duration `env:"RF_WAIT_TIMEOUT" envDefault:"1s"`
 	RadioLatchResetPin string        `env:"RF_LATCH_RESET_PIN" envDefault:"GPIO17"`
 	RadioChannelAPin   string        `env:"RF_CHANNEL_A_PIN" envDefault:"GPIO23"`
@@ -46,6 +79,44 @@ type Config struct {
 	RadioChannelCPin   string        `env:"RF_CHANNEL_C_PIN" envDefault:"GPIO8"`
 	RadioChannelDPin   string        `env:"RF_CHANNEL_D_PIN" envDefault:"GPIO24"`
 
+	// HTTP API authentication. APITokens lists the bearer tokens as "name:scope:sha256",
+	// where scope is read or control, and sha256 is the hex SHA-256 of the token, such
+	// as from `echo -n $TOKEN | sha256sum`. The API is served over TLS when APITLSCert
+	// and APITLSKey are set, and with APIClientCA set other nodes can authenticate with
+	// a client certificate instead, at APIClientCertScope. With no tokens and no client
+	// CA the API is open to the network. Denied requests are recorded in APIAuditLog, or
+	// the main log when it isn't set.
+	APITokens          []string `env:"API_TOKENS"`
+	APITLSCert         string   `env:"API_TLS_CERT"`
+	APITLSKey          string   `env:"API_TLS_KEY"`
+	APIClientCA        string   `env:"API_CLIENT_CA"`
+	APIClientCertScope string   `env:"API_CLIENT_CERT_SCOPE" envDefault:"control"`
+	APIAuditLog        string   `env:"API_AUDIT_LOG"`
+	// PagerCallbackHosts lists the hosts a page may ask to be acknowledged on, as
+	// well as the host that sent it.
+	PagerCallbackHosts []string `env:"PAGER_CALLBACK_HOSTS"`
+	// Pages within PagerCooldown of the last one are counted on it rather than sent
+	// again, and the panel that sent them is told it has already paged.
+	PagerCooldown time.Duration `env:"PAGER_COOLDOWN" envDefault:"30s"`
+	// Each client's requests are limited separately for reads, controls and pages,
+	// to a rate such as "10/s" or "6/m,burst=3". Clients are told by their token's
+	// name, or else their address. "off" removes a limit.
+	RateLimitRead    ratelimit.Rate `env:"RATE_LIMIT_READ" envDefault:"20/s,burst=40"`
+	RateLimitControl ratelimit.Rate `env:"RATE_LIMIT_CONTROL" envDefault:"5/s,burst=20"`
+	RateLimitPager   ratelimit.Rate `env:"RATE_LIMIT_PAGER" envDefault:"6/m,burst=3"`
+	// The /api/v1/events stream keeps the last EventsHistory events for clients that
+	// reconnect, and sends a heartbeat when it has been quiet for EventsHeartbeat. The
+	// lights are checked for changes every EventsLightsInterval.
+	EventsHistory        int           `env:"EVENTS_HISTORY" envDefault:"256"`
+	EventsHeartbeat      time.Duration `env:"EVENTS_HEARTBEAT" envDefault:"15s"`
+	EventsLightsInterval time.Duration `env:"EVENTS_LIGHTS_INTERVAL" envDefault:"1s"`
+	// The access log has each request's route, status, size and latency. It can
+	// also have the headers, with secrets redacted, and the start of the bodies.
+	AccessLogHeaders   bool `env:"ACCESS_LOG_HEADERS" envDefault:"false"`
+	AccessLogBodyBytes int  `env:"ACCESS_LOG_BODY_BYTES" envDefault:"0"`
+	// The dashboard is served at /dashboard/ from the files built into the binary.
+	DashboardEnabled bool `env:"DASHBOARD_ENABLED" envDefault:"true"`
+
 	// Sensors
 	PollInterval  time.Duration `env:"POLL_INTERVAL" envDefault:"5s"`
 	AM2302Enabled bool          `env:"AM2302_ENABLED" envDefault:"false"`
@@ -56,6 +127,30 @@ type Config struct {
 	SDS011Rxd     string `env:"SDS011_RXD" envDefault:"GPIO15"`
 	SDSSerialPath string `env:"SDS_SERIAL_PATH" envDefault:"/dev/ttyAMA0"`
 	SDSSensor     *sds011.Sensor
+	// The dust sensor is woken every DustPollInterval, and read once its fan has
+	// run for DustWarmup.
+	DustPollInterval time.Duration `env:"DUST_POLL_INTERVAL" envDefault:"5m"`
+	DustWarmup       time.Duration `env:"DUST_WARMUP" envDefault:"30s"`
+	// The readings are sampled every SensorHistoryInterval, and the last
+	// SensorHistory samples are kept for the dashboard's graphs.
+	SensorHistory         int           `env:"SENSOR_HISTORY" envDefault:"120"`
+	SensorHistoryInterval time.Duration `env:"SENSOR_HISTORY_INTERVAL" envDefault:"1m"`
+
+	// Ambient light sensor: ldr for an LDR or photodiode on an MCP3008 channel, or
+	// bh1750 for a BH1750 over I2C. Empty disables it. The LDR's full scale reading
+	// is AMBIENT_LDR_MAX_LUX, with AMBIENT_LDR_GAMMA correcting its response. The
+	// BH1750 address is given in decimal, such as 35 for 0x23.
+	AmbientSensorType   string        `env:"AMBIENT_SENSOR"`
+	AmbientPollInterval time.Duration `env:"AMBIENT_POLL_INTERVAL" envDefault:"30s"`
+	AmbientAdcBus       string        `env:"AMBIENT_ADC_BUS" envDefault:"/dev/spidev0.0"`
+	AmbientAdcChannel   byte          `env:"AMBIENT_ADC_CHANNEL" envDefault:"0"`
+	AmbientLdrMaxLux    float64       `env:"AMBIENT_LDR_MAX_LUX" envDefault:"1000"`
+	AmbientLdrGamma     float64       `env:"AMBIENT_LDR_GAMMA" envDefault:"1.0"`
+	AmbientI2CBus       string        `env:"AMBIENT_I2C_BUS"`
+	AmbientI2CAddress   uint16        `env:"AMBIENT_I2C_ADDRESS" envDefault:"35"`
+	AmbientSensor       ambient.Sensor
+	ambientSpi          spi.PortCloser
+	ambientI2C          i2c.BusCloser
 
 	// Thermocouple
 	Thermocouple1Enabled bool   `env:"THERMO1_ENABLED" envDefault:"false"`
@@ -84,8 +179,82 @@ type Config struct {
 	LedControlWhitePin gpio.PinOut
 	LedControlBlue     string `env:"LED_CTRL_BLUE" envDefault:"GPIO18"`
 	LedControlBluePin  gpio.PinOut
+	// LedZonesFile lists the strip zones as JSON. When it isn't set, the single strip
+	// on the LED_CTRL_* pins above is the "main" zone.
+	LedZonesFile string `env:"LIGHTS_ZONES_FILE"`
+	LedZones     []ZoneConfig
+	// LedPWMFrequency is the default PWM frequency for the strips. Pins without
+	// hardware PWM are limited by the DMA sample rate, so a lower frequency gives
+	// them more distinct brightness steps. LedPWMResolution is the default number
+	// of bits of duty cycle to drive, where 0 uses all that the driver offers.
+	LedPWMFrequency  string `env:"LIGHTS_PWM_FREQUENCY" envDefault:"1kHz"`
+	LedPWMResolution int    `env:"LIGHTS_PWM_RESOLUTION" envDefault:"0"`
+	i2cBuses         []i2c.BusCloser
+	// Scenes and the last light state are saved to LightsStateFile. LightsBootState is
+	// the scene to start up in, or "last" to restore the state from before the restart.
+	LightsStateFile string `env:"LIGHTS_STATE_FILE" envDefault:"lights.json"`
+	LightsBootState string `env:"LIGHTS_BOOT_STATE" envDefault:"last"`
+	// Transitions between light states
+	FadeDuration time.Duration `env:"LIGHTS_FADE_DURATION" envDefault:"1s"`
+	FadeEasing   string        `env:"LIGHTS_FADE_EASING" envDefault:"ease-in-out"` // linear, ease-in-out or exponential
+	FadeInterval time.Duration `env:"LIGHTS_FADE_INTERVAL" envDefault:"10ms"`
+	// Light effects. PagerEffect is played when a page is received, such as "flash,color=0/0/0/100,count=3".
+	EffectStepInterval time.Duration `env:"LIGHTS_EFFECT_STEP" envDefault:"20ms"`
+	PagerEffect        string        `env:"PAGER_EFFECT"`
+	// Perceptual brightness and per-channel calibration. Duty settings are fractions of full power.
+	BrightnessCurve  string  `env:"LIGHTS_BRIGHTNESS_CURVE" envDefault:"cie"` // cie or gamma
+	BrightnessGamma  float64 `env:"LIGHTS_GAMMA" envDefault:"2.2"`
+	DimLevel         float64 `env:"LIGHTS_DIM_LEVEL" envDefault:"0.35"` // perceived brightness for the "low" setting
+	MinDuty          float64 `env:"LIGHTS_MIN_DUTY" envDefault:"0.01"`
+	MaxDutyRed       float64 `env:"LIGHTS_MAX_DUTY_RED" envDefault:"1.0"`
+	MaxDutyGreen     float64 `env:"LIGHTS_MAX_DUTY_GREEN" envDefault:"1.0"`
+	MaxDutyWhite     float64 `env:"LIGHTS_MAX_DUTY_WHITE" envDefault:"1.0"`
+	MaxDutyBlue      float64 `env:"LIGHTS_MAX_DUTY_BLUE" envDefault:"1.0"`
+	CalibrationRed   float64 `env:"LIGHTS_CAL_RED" envDefault:"1.0"`
+	CalibrationGreen float64 `env:"LIGHTS_CAL_GREEN" envDefault:"1.0"`
+	CalibrationWhite float64 `env:"LIGHTS_CAL_WHITE" envDefault:"1.0"`
+	CalibrationBlue  float64 `env:"LIGHTS_CAL_BLUE" envDefault:"1.0"`
+	// Power draw of each channel at 100% duty, for estimating the strip's power
+	// consumption. The warm and cool channels of a cct zone default to the white
+	// channel's. Zones can override these per channel.
+	WattsRed   float64 `env:"LIGHTS_WATTS_RED" envDefault:"0"`
+	WattsGreen float64 `env:"LIGHTS_WATTS_GREEN" envDefault:"0"`
+	WattsWhite float64 `env:"LIGHTS_WATTS_WHITE" envDefault:"0"`
+	WattsBlue  float64 `env:"LIGHTS_WATTS_BLUE" envDefault:"0"`
+	// Auto-brightness scales the "low" and "full" scenes by the ambient light level,
+	// from AUTO_BRIGHTNESS_MIN_LEVEL of their brightness at DARK_LUX and below, up to
+	// all of it at BRIGHT_LUX and above. The scenes adapt when they are recalled, as
+	// the strip itself lights up the sensor. The wakeup lights are skipped when the
+	// room is at least SKIP_WAKEUP_LUX, where zero never skips them.
+	AutoBrightness              bool    `env:"LIGHTS_AUTO_BRIGHTNESS" envDefault:"false"`
+	AutoBrightnessDarkLux       float64 `env:"LIGHTS_AUTO_BRIGHTNESS_DARK_LUX" envDefault:"5"`
+	AutoBrightnessBrightLux     float64 `env:"LIGHTS_AUTO_BRIGHTNESS_BRIGHT_LUX" envDefault:"300"`
+	AutoBrightnessMinLevel      float64 `env:"LIGHTS_AUTO_BRIGHTNESS_MIN_LEVEL" envDefault:"0.4"`
+	AutoBrightnessSkipWakeupLux float64 `env:"LIGHTS_AUTO_BRIGHTNESS_SKIP_WAKEUP_LUX" envDefault:"400"`
+	// LightsTelemetryInterval is how often the power estimate is recorded as the
+	// "lights" measurement in InfluxDB. Zero disables it.
+	LightsTelemetryInterval time.Duration `env:"LIGHTS_TELEMETRY_INTERVAL" envDefault:"1m"`
+	// Colour temperature calibration for the strip's white LED
+	WhiteKelvin     float64 `env:"LIGHTS_WHITE_KELVIN" envDefault:"4000"`
+	KelvinMin       float64 `env:"LIGHTS_KELVIN_MIN" envDefault:"1800"`
+	KelvinMax       float64 `env:"LIGHTS_KELVIN_MAX" envDefault:"6500"`
+	KelvinGainRed   float64 `env:"LIGHTS_KELVIN_GAIN_RED" envDefault:"0.75"`
+	KelvinGainGreen float64 `env:"LIGHTS_KELVIN_GAIN_GREEN" envDefault:"0.3"`
+	KelvinGainWhite float64 `env:"LIGHTS_KELVIN_GAIN_WHITE" envDefault:"1.0"`
+	KelvinGainBlue  float64 `env:"LIGHTS_KELVIN_GAIN_BLUE" envDefault:"0.5"`
 
 	// Control Panels
+	PanelPollInterval time.Duration `env:"PANEL_POLL_INTERVAL" envDefault:"50ms"`
+	PanelLongPress    time.Duration `env:"PANEL_LONG_PRESS" envDefault:"800ms"`
+	PanelDoubleTap    time.Duration `env:"PANEL_DOUBLE_TAP" envDefault:"400ms"`
+	// PanelScenes is the cycle of scenes that each touch of the light switch steps through
+	PanelScenes []string `env:"PANEL_SCENES" envDefault:"off,low,full"`
+	// Network panels register over the API, and send a heartbeat every NetPanelHeartbeat.
+	// Their polls for commands are held open for up to NetPanelPollWait.
+	NetPanelsEnabled  bool          `env:"NETPANELS_ENABLED" envDefault:"true"`
+	NetPanelHeartbeat time.Duration `env:"NETPANEL_HEARTBEAT" envDefault:"10s"`
+	NetPanelPollWait  time.Duration `env:"NETPANEL_POLL_WAIT" envDefault:"30s"`
+
 	ControlPanelsAdcClk int `env:"ADC1_CLK" envDefault:"5"` // Pin 29 / GPIO5
 	//ControlPanelsAdcClkPin  gpio.PinIO
 	ControlPanelsAdcCsz int `env:"ADC1_CSZ" envDefault:"21"` // Pin 40 / GPIO21
@@ -96,6 +265,8 @@ type Config struct {
 	//ControlPanelsAdcDoutPin gpio.PinIO
 	GPIOChip         *gpiod.Chip
 	ControlPanelsAdc *mcp3w0c.MCP3w0c
+	// gpioErrors records the pins that failed to initialize, for the health report
+	gpioErrors []string
 
 	Panel1Enabled        bool   `env:"PANEL1_ENABLED" envDefault:"false"`
 	Panel1DimmerChannel  int    `env:"PANEL1_DIMMER_CHANNEL" envDefault:"0"`
@@ -168,6 +339,10 @@ func (cfg *Config) InitSensors() {
 
 	}
 
+	if err = cfg.initAmbientSensor(); err != nil {
+		cfg.Logger.WithError(err).WithField("sensor", cfg.AmbientSensorType).Fatal("Failed to initialize ambient light sensor")
+	}
+
 	// TODO: Air Quality
 	if cfg.SDS011Enabled {
 		cfg.SDSSensor, err = sds011.New(cfg.SDSSerialPath)
@@ -233,6 +408,54 @@ func spiDeviceList() string {
 	return fmt.Sprintf("[%s]", strings.Join(spiList, ", "))
 }
 
+// initAmbientSensor opens the ambient light sensor, if one is configured.
+func (cfg *Config) initAmbientSensor() error {
+	var err error
+	switch cfg.AmbientSensorType {
+	case "":
+		return nil
+	case "ldr":
+		cfg.ambientSpi, err = spireg.Open(cfg.AmbientAdcBus)
+		if err != nil {
+			log.WithField("availableSPI", spiDeviceList()).WithError(err).Error("Failed to open SPI bus")
+			return err
+		}
+		cfg.AmbientSensor = &ambient.LDR{
+			ADC:     mcp3008.New(cfg.ambientSpi, mcp3008.SingleEndedMode),
+			Channel: cfg.AmbientAdcChannel,
+			MaxLux:  cfg.AmbientLdrMaxLux,
+			Gamma:   cfg.AmbientLdrGamma,
+		}
+	case "bh1750":
+		cfg.ambientI2C, err = i2creg.Open(cfg.AmbientI2CBus)
+		if err != nil {
+			return err
+		}
+		cfg.AmbientSensor, err = bh1750.New(cfg.ambientI2C, cfg.AmbientI2CAddress)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown ambient light sensor %q - valid options: ldr, bh1750", cfg.AmbientSensorType)
+	}
+	lux, err := cfg.AmbientSensor.ReadLux()
+	if err != nil {
+		return err
+	}
+	log.WithField("lux", lux).Info("Initial ambient light reading")
+	return nil
+}
+
+// HaltAmbientSensor closes the ambient light sensor's bus.
+func (cfg *Config) HaltAmbientSensor() {
+	if cfg.ambientSpi != nil {
+		cfg.ambientSpi.Close()
+	}
+	if cfg.ambientI2C != nil {
+		cfg.ambientI2C.Close()
+	}
+}
+
 func (cfg *Config) InitThermocouples() error {
 	var err error
 	logger := cfg.Logger.WithFields(log.Fields{
@@ -291,6 +514,7 @@ func New() *Config {
 
 func (cfg *Config) InitPins() {
 	var err error
+	health.Register("gpio", true, health.ReporterFunc(cfg.gpioHealth))
 
 	if cfg.Panel1Enabled || cfg.Panel2Enabled {
 		log.Debug("initializing ADC")
@@ -313,35 +537,14 @@ func (cfg *Config) InitPins() {
 		log.WithError(err).Fatal("Failed to init driverreg")
 	}
 
+	if cfg.LedStripEnabled && cfg.LedZonesFile == "" {
+		cfg.LedControlRedPin = cfg.initLedPin("Red", cfg.LedControlRed)
+		cfg.LedControlGreenPin = cfg.initLedPin("Green", cfg.LedControlGreen)
+		cfg.LedControlWhitePin = cfg.initLedPin("White", cfg.LedControlWhite)
+		cfg.LedControlBluePin = cfg.initLedPin("Blue", cfg.LedControlBlue)
+	}
 	if cfg.LedStripEnabled {
-		cfg.LedControlRedPin = gpioreg.ByName(cfg.LedControlRed)
-		if cfg.LedControlRedPin == nil {
-			log.WithField("pin", cfg.LedControlRedPin.String()).Error("Failed to init Red LED pin")
-		}
-		if err = cfg.LedControlRedPin.Out(gpio.Low); err != nil {
-			log.WithField("pin", cfg.LedControlRedPin.String()).WithError(err).Error("Error with initial Red LED settings")
-		}
-		cfg.LedControlGreenPin = gpioreg.ByName(cfg.LedControlGreen)
-		if cfg.LedControlGreenPin == nil {
-			log.WithField("pin", cfg.LedControlGreenPin.String()).Error("Failed to init Green LED pin")
-		}
-		if err = cfg.LedControlGreenPin.Out(gpio.Low); err != nil {
-			log.WithField("pin", cfg.LedControlGreenPin.String()).WithError(err).Error("Error with initial Green LED settings")
-		}
-		cfg.LedControlWhitePin = gpioreg.ByName(cfg.LedControlWhite)
-		if cfg.LedControlWhitePin == nil {
-			log.WithField("pin", cfg.LedControlWhitePin.String()).Error("Failed to init White LED pin")
-		}
-		if err = cfg.LedControlWhitePin.Out(gpio.Low); err != nil {
-			log.WithField("pin", cfg.LedControlWhitePin.String()).WithError(err).Error("Error with initial White LED settings")
-		}
-		cfg.LedControlBluePin = gpioreg.ByName(cfg.LedControlBlue)
-		if cfg.LedControlBluePin == nil {
-			log.WithField("pin", cfg.LedControlBluePin.String()).Fatal("Failed to init Blue LED pin")
-		}
-		if err = cfg.LedControlBluePin.Out(gpio.Low); err != nil {
-			log.WithField("pin", cfg.LedControlBluePin.String()).WithError(err).Error("Error with initial Blue LED settings")
-		}
+		cfg.initZones()
 	}
 
 	if cfg.Panel1Enabled {
@@ -410,6 +613,37 @@ func (cfg *Config) InitPins() {
 	}
 }
 
+// initLedPin sets up one of the LED strip's control pins, and turns it off.
+// Failures are logged and reported in the GPIO health, rather than stopping
+// the node, so that the rest of it still works.
+func (cfg *Config) initLedPin(color, name string) gpio.PinOut {
+	pin := gpioreg.ByName(name)
+	if pin == nil {
+		log.WithField("pin", name).Errorf("Failed to init %s LED pin", color)
+		cfg.gpioErrors = append(cfg.gpioErrors, fmt.Sprintf("%s LED pin %s not found", color, name))
+		return nil
+	}
+	if err := pin.Out(gpio.Low); err != nil {
+		log.WithField("pin", name).WithError(err).Errorf("Error with initial %s LED settings", color)
+		cfg.gpioErrors = append(cfg.gpioErrors, fmt.Sprintf("%s LED pin %s: %v", color, name, err))
+	}
+	return pin
+}
+
+// gpioHealth reports any pins that failed to initialize. Most failures stop
+// the node, so the rest only degrade it.
+func (cfg *Config) gpioHealth() health.Report {
+	if len(cfg.gpioErrors) == 0 {
+		return health.Report{Status: health.StatusOK}
+	}
+	return health.Report{
+		Status:    health.StatusDegraded,
+		LastError: cfg.gpioErrors[len(cfg.gpioErrors)-1],
+		Errors:    len(cfg.gpioErrors),
+		Details:   map[string]interface{}{"errors": cfg.gpioErrors},
+	}
+}
+
 // HaltPins will call pin.Halt on all pins used for PWM output.
 // This function must be called before the program exits, or else
 // the Raspberry Pi must be reset before PWM can be used again.
@@ -427,6 +661,7 @@ func (cfg *Config) HaltPins() {
 	if cfg.LedControlBluePin != nil {
 		cfg.LedControlBluePin.Halt()
 	}
+	cfg.haltZones()
 	if cfg.Panel1SpeakerPin != nil {
 		cfg.Panel1SpeakerPin.Halt()
 	}
@@ -0,0 +1,330 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/klaital/wannetiot/pkg/pca9685"
+	log "github.com/sirupsen/logrus"
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/gpio/gpioreg"
+	"periph.io/x/conn/v3/i2c"
+	"periph.io/x/conn/v3/i2c/i2creg"
+	"periph.io/x/conn/v3/physic"
+	"periph.io/x/conn/v3/pin"
+)
+
+// ZoneConfig describes one independently controlled LED strip zone.
+type ZoneConfig struct {
+	Name string `json:"name"`
+	// Type is the strip's channel layout: rgbw, rgb, white, or cct (warm and cool white).
+	Type string `json:"type"`
+	// Groups lets several zones be addressed together, such as "bedroom".
+	Groups []string `json:"groups,omitempty"`
+	// Driver is what generates the PWM signal: gpio (the default) for the
+	// host's own pins, pca9685 for a PCA9685 on an I2C bus, or pca9685-sim
+	// for a simulated PCA9685 when the hardware isn't attached.
+	Driver string `json:"driver,omitempty"`
+	// Bus and Address locate a PCA9685. An empty bus uses the first I2C bus,
+	// and the address defaults to 0x40.
+	Bus     string `json:"bus,omitempty"`
+	Address uint16 `json:"address,omitempty"`
+	// Pins maps each of the strip's channels to its GPIO name, or for a
+	// PCA9685 to its channel number, 0 - 15.
+	Pins map[string]string `json:"pins"`
+	// Frequency is the PWM frequency, such as "800Hz". Empty uses LIGHTS_PWM_FREQUENCY.
+	// Every zone on the same PCA9685 shares one frequency.
+	Frequency string `json:"frequency,omitempty"`
+	// Resolution is the number of bits of duty cycle to drive, rounding off
+	// steps the driver can't produce cleanly. Zero uses LIGHTS_PWM_RESOLUTION.
+	Resolution int `json:"resolution,omitempty"`
+	// Calibration and MaxDuty override the global per-channel settings for
+	// this zone, keyed by red, green, white and blue.
+	Calibration map[string]float64 `json:"calibration,omitempty"`
+	MaxDuty     map[string]float64 `json:"max_duty,omitempty"`
+	// Watts overrides the global power draw at 100% duty, keyed by channel.
+	Watts map[string]float64 `json:"watts,omitempty"`
+	// WarmKelvin and CoolKelvin are the colour temperatures of a cct strip's channels.
+	WarmKelvin float64 `json:"warm_kelvin,omitempty"`
+	CoolKelvin float64 `json:"cool_kelvin,omitempty"`
+
+	// PinOuts holds the initialized pin for each channel.
+	PinOuts map[string]gpio.PinOut `json:"-"`
+	// PWMFrequency is the parsed Frequency, or for a PCA9685 the frequency
+	// the chip actually runs at.
+	PWMFrequency physic.Frequency `json:"-"`
+	// HardwarePWM reports whether every channel has a dedicated PWM
+	// generator, rather than PWM timed in software or by DMA.
+	HardwarePWM bool `json:"-"`
+}
+
+// ZoneDrivers lists the supported PWM drivers.
+var ZoneDrivers = []string{"gpio", "pca9685", "pca9685-sim"}
+
+// ZoneChannels lists the channels each type of strip has.
+var ZoneChannels = map[string][]string{
+	"rgbw":  {"red", "green", "white", "blue"},
+	"rgb":   {"red", "green", "blue"},
+	"white": {"white"},
+	"cct":   {"warm", "cool"},
+}
+
+// zoneTypes lists the valid strip types, for error messages.
+func zoneTypes() []string {
+	types := make([]string, 0, len(ZoneChannels))
+	for t := range ZoneChannels {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// loadZones reads the zone definitions from LedZonesFile, and checks that
+// each names a pin for every one of its channels.
+func (cfg *Config) loadZones() error {
+	b, err := os.ReadFile(cfg.LedZonesFile)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(b, &cfg.LedZones); err != nil {
+		return err
+	}
+	seen := make(map[string]bool)
+	for i := range cfg.LedZones {
+		z := &cfg.LedZones[i]
+		if z.Name == "" {
+			return fmt.Errorf("zone %d has no name", i)
+		}
+		if seen[z.Name] {
+			return fmt.Errorf("zone %q is defined twice", z.Name)
+		}
+		seen[z.Name] = true
+		if z.Type == "" {
+			z.Type = "rgbw"
+		}
+		switch z.Driver {
+		case "":
+			z.Driver = "gpio"
+		case "gpio", "pca9685", "pca9685-sim":
+		default:
+			return fmt.Errorf("zone %q has unknown driver %q - valid options: %v", z.Name, z.Driver, ZoneDrivers)
+		}
+		if z.Frequency != "" {
+			if err = z.PWMFrequency.Set(z.Frequency); err != nil || z.PWMFrequency <= 0 {
+				return fmt.Errorf("zone %q has invalid frequency %q", z.Name, z.Frequency)
+			}
+		}
+		if z.Resolution < 0 || z.Resolution > 24 {
+			return fmt.Errorf("zone %q has invalid resolution %d - valid range 1 - 24 bits", z.Name, z.Resolution)
+		}
+		channels, ok := ZoneChannels[z.Type]
+		if !ok {
+			return fmt.Errorf("zone %q has unknown type %q - valid options: %v", z.Name, z.Type, zoneTypes())
+		}
+		for _, c := range channels {
+			if z.Pins[c] == "" {
+				return fmt.Errorf("zone %q has no pin for its %s channel", z.Name, c)
+			}
+			if z.Driver != "gpio" {
+				if ch, err := strconv.Atoi(z.Pins[c]); err != nil || ch < 0 || ch >= pca9685.Channels {
+					return fmt.Errorf("zone %q has invalid PCA9685 channel %q for its %s channel", z.Name, z.Pins[c], c)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// initZones sets up the LED strip zones. Without a zones file, the single
+// strip on the LED_CTRL_* pins becomes the "main" zone. A channel whose pin
+// can't be found is left off, as with initLedPin.
+func (cfg *Config) initZones() {
+	var defaultFreq physic.Frequency
+	if err := defaultFreq.Set(cfg.LedPWMFrequency); err != nil || defaultFreq <= 0 {
+		log.WithError(err).WithField("frequency", cfg.LedPWMFrequency).Fatal("Invalid LED PWM frequency")
+	}
+
+	if cfg.LedZonesFile == "" {
+		cfg.LedZones = []ZoneConfig{{
+			Name:   "main",
+			Type:   "rgbw",
+			Driver: "gpio",
+			Pins: map[string]string{
+				"red":   cfg.LedControlRed,
+				"green": cfg.LedControlGreen,
+				"white": cfg.LedControlWhite,
+				"blue":  cfg.LedControlBlue,
+			},
+			PinOuts: map[string]gpio.PinOut{
+				"red":   cfg.LedControlRedPin,
+				"green": cfg.LedControlGreenPin,
+				"white": cfg.LedControlWhitePin,
+				"blue":  cfg.LedControlBluePin,
+			},
+		}}
+	} else if err := cfg.loadZones(); err != nil {
+		log.WithError(err).WithField("file", cfg.LedZonesFile).Fatal("Failed to load LED zones")
+	}
+
+	// Zones on the same PCA9685 share the chip, and its frequency
+	chips := make(map[string]*pca9685.Dev)
+	// generators records which pin is using each hardware PWM generator
+	generators := make(map[string]string)
+
+	for i := range cfg.LedZones {
+		z := &cfg.LedZones[i]
+		if z.PWMFrequency == 0 {
+			z.PWMFrequency = defaultFreq
+		}
+		if z.Resolution == 0 {
+			z.Resolution = cfg.LedPWMResolution
+		}
+
+		if z.Driver != "gpio" {
+			key := fmt.Sprintf("%s/%s/%#x", z.Driver, z.Bus, z.Address)
+			chip, ok := chips[key]
+			if !ok {
+				chip = cfg.openPCA9685(z)
+				chips[key] = chip
+			} else if chip.Frequency() != z.PWMFrequency {
+				log.WithFields(log.Fields{
+					"zone":      z.Name,
+					"frequency": z.PWMFrequency.String(),
+					"chip":      chip.Frequency().String(),
+				}).Warn("Zone shares a PCA9685 with another zone, and must use its frequency")
+			}
+			z.PWMFrequency = chip.Frequency()
+			z.HardwarePWM = true
+			z.PinOuts = make(map[string]gpio.PinOut)
+			for _, c := range ZoneChannels[z.Type] {
+				ch, _ := strconv.Atoi(z.Pins[c])
+				p, err := chip.Pin(ch)
+				if err != nil {
+					log.WithError(err).WithField("zone", z.Name).Fatal("Failed to init LED zone channel")
+				}
+				z.PinOuts[c] = p
+			}
+			continue
+		}
+
+		if z.PinOuts == nil {
+			z.PinOuts = make(map[string]gpio.PinOut)
+		}
+		z.HardwarePWM = true
+		for _, c := range ZoneChannels[z.Type] {
+			logger := log.WithFields(log.Fields{
+				"zone":    z.Name,
+				"channel": c,
+				"pin":     z.Pins[c],
+			})
+			p, given := z.PinOuts[c]
+			if p == nil {
+				// A pin that is missing is left undriven, and reported in the
+				// GPIO health. The LED_CTRL_* pins were already reported.
+				delete(z.PinOuts, c)
+				if given {
+					continue
+				}
+				p = gpioreg.ByName(z.Pins[c])
+				if p == nil {
+					logger.Error("Failed to init LED zone pin, leaving the channel off")
+					cfg.gpioErrors = append(cfg.gpioErrors, fmt.Sprintf("zone %s %s pin %s not found", z.Name, c, z.Pins[c]))
+					continue
+				}
+				if err := p.Out(gpio.Low); err != nil {
+					logger.WithError(err).Error("Error with initial LED zone settings")
+					cfg.gpioErrors = append(cfg.gpioErrors, fmt.Sprintf("zone %s %s pin %s: %v", z.Name, c, z.Pins[c], err))
+				}
+				z.PinOuts[c] = p
+			}
+
+			generator, ok := HardwarePWM(p)
+			if !ok {
+				z.HardwarePWM = false
+				logger.WithField("frequency", z.PWMFrequency.String()).Warn("LED pin has no hardware PWM, so it is driven by DMA or software and may flicker. Consider a lower frequency, or a hardware PWM pin or driver.")
+				continue
+			}
+			if other, taken := generators[generator]; taken {
+				logger.WithFields(log.Fields{
+					"generator": generator,
+					"shared":    other,
+				}).Warn("LED pin shares its hardware PWM generator with another pin, so both will output the same duty cycle")
+			}
+			generators[generator] = p.Name()
+		}
+	}
+}
+
+// HardwarePWM reports the hardware PWM generator driving the pin, if it has
+// one. On the Raspberry Pi, only PWM1 is driven in hardware, as PWM0 clocks
+// the DMA engine that generates PWM on every other pin.
+func HardwarePWM(p gpio.PinOut) (string, bool) {
+	if cp, ok := p.(*pca9685.Pin); ok {
+		return cp.Name(), true
+	}
+	pf, ok := p.(pin.PinFunc)
+	if !ok {
+		return "", false
+	}
+	for _, f := range pf.SupportedFuncs() {
+		if f.Generalize() == gpio.PWM && f != "PWM0" {
+			return string(f), true
+		}
+	}
+	return "", false
+}
+
+// openPCA9685 connects to the zone's PCA9685, or sets up a simulated one.
+func (cfg *Config) openPCA9685(z *ZoneConfig) *pca9685.Dev {
+	addr := z.Address
+	if addr == 0 {
+		addr = pca9685.DefaultAddress
+	}
+	logger := log.WithFields(log.Fields{
+		"zone":    z.Name,
+		"driver":  z.Driver,
+		"bus":     z.Bus,
+		"address": fmt.Sprintf("%#x", addr),
+	})
+
+	var bus i2c.Bus
+	if z.Driver == "pca9685-sim" {
+		bus = pca9685.NewSim(addr)
+	} else {
+		closer, err := i2creg.Open(z.Bus)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to open I2C bus for PCA9685")
+		}
+		cfg.i2cBuses = append(cfg.i2cBuses, closer)
+		bus = closer
+	}
+	chip, err := pca9685.New(bus, addr, z.PWMFrequency)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to init PCA9685")
+	}
+	if chip.Frequency() != z.PWMFrequency {
+		logger.WithFields(log.Fields{
+			"requested": z.PWMFrequency.String(),
+			"actual":    chip.Frequency().String(),
+		}).Info("PCA9685 frequency rounded to its prescaler")
+	}
+	return chip
+}
+
+// haltZones halts the pins of every LED strip zone, and closes any I2C buses.
+func (cfg *Config) haltZones() {
+	for _, z := range cfg.LedZones {
+		for _, p := range z.PinOuts {
+			if p != nil {
+				p.Halt()
+			}
+		}
+	}
+	for _, bus := range cfg.i2cBuses {
+		bus.Close()
+	}
+	cfg.i2cBuses = nil
+}
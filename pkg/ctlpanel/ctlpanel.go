@@ -13,9 +13,14 @@ import (
 	"github.com/warthog618/gpiod/spi/mcp3w0c"
 	"periph.io/x/conn/v3/gpio"
 	"periph.io/x/conn/v3/physic"
+	"sync"
 	"time"
 )
 
+// stateLock guards the readings the panels record as they are polled, so
+// that they can be reported while the panels run.
+var stateLock sync.Mutex
+
 // ControlPanel models the hardware interface to the panel's devices. It also tracks the
 // reads from the dimmer to only trigger an update when the value actually changes.
 type ControlPanel struct {
@@ -28,6 +33,15 @@ type ControlPanel struct {
 	DimmerAdc       *mcp3w0c.MCP3w0c
 	DimmerLastValue uint16
 
+	// Gesture timings for the light switch
+	LongPress       time.Duration
+	DoubleTapWindow time.Duration
+	// SnoozeDuration is how long a tap snoozes the wakeup sequence for
+	SnoozeDuration time.Duration
+	// SceneCycle lists the IDs of the scenes each touch of the light switch steps through, in order
+	SceneCycle []string
+	Scenes     *lights.SceneStore
+
 	logger *log.Entry
 }
 
@@ -37,6 +51,8 @@ func New(pager, light gpio.PinIn, reset, led, speaker gpio.PinOut, dimmerSelect
 	if logger == nil {
 		logger = log.NewEntry(log.New())
 	}
+	// Without a saved store, only the built-in scenes are available
+	scenes, _ := lights.OpenSceneStore("")
 	return ControlPanel{
 		ResetPin:        reset,
 		PagerPin:        pager,
@@ -46,6 +62,11 @@ func New(pager, light gpio.PinIn, reset, led, speaker gpio.PinOut, dimmerSelect
 		DimmerChannel:   dimmerSelect,
 		DimmerAdc:       dimmerAdc,
 		DimmerLastValue: 0,
+		LongPress:       800 * time.Millisecond,
+		DoubleTapWindow: 400 * time.Millisecond,
+		SnoozeDuration:  9 * time.Minute,
+		SceneCycle:      []string{"off", "low", "full"},
+		Scenes:          scenes,
 		logger:          logger,
 	}
 }
@@ -63,7 +84,18 @@ func (p *ControlPanel) ReadDimmerPct() uint16 {
 	}
 
 	raw32 := uint32(rawValue) * 100
-	return uint16(raw32 / 1023)
+	pct := uint16(raw32 / 1023)
+	stateLock.Lock()
+	p.DimmerLastValue = pct
+	stateLock.Unlock()
+	return pct
+}
+
+// State copies out the panel's last dimmer reading and its scene cycle.
+func (p *ControlPanel) State() (dimmer uint16, sceneCycle []string) {
+	stateLock.Lock()
+	defer stateLock.Unlock()
+	return p.DimmerLastValue, append([]string(nil), p.SceneCycle...)
 }
 
 // BlinkLED drives the panel's button's LED on for the specified duration.
@@ -93,6 +125,12 @@ func (p *ControlPanel) Chirp(tone physic.Frequency, d time.Duration) {
 	if err != nil {
 		logger.WithError(err).Fatal("Failed to parse duty 10%")
 	}
+	p.chirpAt(tone, duty, d, logger)
+}
+
+// chirpAt plays the tone with the speaker driven at the given duty cycle.
+// Duty cycles further from 50% sound quieter.
+func (p *ControlPanel) chirpAt(tone physic.Frequency, duty gpio.Duty, d time.Duration, logger *log.Entry) {
 	if err := p.Speaker.PWM(duty, tone); err != nil {
 		logger.WithError(err).Error("Failed to drive speaker")
 	}
@@ -102,11 +140,39 @@ func (p *ControlPanel) Chirp(tone physic.Frequency, d time.Duration) {
 	}
 }
 
+// PlayAlarm plays one round of the end-of-wakeup alarm. Each level adds a
+// beep, raises the pitch and drives the speaker harder, up to level 5.
+func (p *ControlPanel) PlayAlarm(level int) {
+	if level < 1 {
+		level = 1
+	}
+	if level > 5 {
+		level = 5
+	}
+	logger := p.logger.WithFields(log.Fields{
+		"op":    "ControlPanel#PlayAlarm",
+		"level": level,
+	})
+	duty := gpio.DutyMax / 10 * gpio.Duty(level)
+	tone := physic.Frequency(3000+500*level) * physic.Hertz
+	for i := 0; i <= level; i++ {
+		if p.LedPin != nil {
+			util.Flash(p.LedPin, 1*time.Millisecond, p.logger)
+		}
+		p.chirpAt(tone, duty, 150*time.Millisecond, logger)
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
 func (p *ControlPanel) ResetLatches() {
 	util.Flash(p.ResetPin, 1*time.Millisecond, p.logger)
 }
 
-func (p *ControlPanel) HandlePager() {
+// HandlePager checks the pager button's latch, and sends the page with the
+// given func, which reports whether it was a repeat of one already sent.
+// The LED and speaker confirm the page, or play AlreadyPaged for a repeat.
+// Returns true if the pager was pressed.
+func (p *ControlPanel) HandlePager(page func() (repeat bool)) bool {
 	if p.PagerPin.Read() {
 		log.Debug("Pager request detected")
 		// Flash the LED and chirp the speaker, then reset the latch
@@ -116,16 +182,49 @@ func (p *ControlPanel) HandlePager() {
 		p.Chirp(6*physic.KiloHertz, 80*time.Millisecond)
 
 		util.Flash(p.ResetPin, 1*time.Millisecond, p.logger)
-		// TODO: send the notification to slack, then chirp and flash the LED again
+		if page() {
+			p.AlreadyPaged()
+			return true
+		}
 		time.Sleep(500 * time.Millisecond)
 		p.BlinkLED(500 * time.Millisecond)
 		p.Chirp(5*physic.KiloHertz, 200*time.Millisecond)
+		return true
 	}
+	return false
+}
+
+// AlreadyPaged plays a low falling pair of chirps, to show that a page was
+// sent recently, and that this one was only added to it.
+func (p *ControlPanel) AlreadyPaged() {
+	p.logger.Info("Already paged")
+	time.Sleep(300 * time.Millisecond)
+	p.BlinkLED(150 * time.Millisecond)
+	p.Chirp(3*physic.KiloHertz, 120*time.Millisecond)
+	time.Sleep(80 * time.Millisecond)
+	p.BlinkLED(150 * time.Millisecond)
+	p.Chirp(2*physic.KiloHertz, 200*time.Millisecond)
 }
 
+// HandleTouchSwitch reads a gesture from the light switch, if it has been touched.
+// While a wakeup is in progress, gestures snooze or dismiss it. Otherwise each
+// touch steps the lights through the panel's scene cycle, and a long press
+// starts the sleep timer.
 func (p *ControlPanel) HandleTouchSwitch(oldLightSettings *lights.LightConfig) (updated bool) {
-	if p.LightSwitchPin.Read() {
-		log.Debug("Light Switch touch detected")
+	gesture := p.ReadGesture()
+	if gesture != GestureNone {
+		log.WithField("gesture", gesture.String()).Debug("Light Switch touch detected")
+		if running, _ := lights.WakeupProgress(); running {
+			return p.handleWakeupGesture(gesture, oldLightSettings)
+		}
+		if gesture == GestureLongPress {
+			// The sleep timer drives the strip itself, and finishes with the lights off
+			log.Info("Sleep timer started from panel")
+			p.PlayTones(ToneSleep)
+			lights.DoSleep()
+			*oldLightSettings = lights.Off
+			return false
+		}
 
 		p.BlinkLED(500 * time.Millisecond)
 		p.Chirp(5*physic.KiloHertz, 80*time.Millisecond)
@@ -133,42 +232,49 @@ func (p *ControlPanel) HandleTouchSwitch(oldLightSettings *lights.LightConfig) (
 		p.Chirp(7*physic.KiloHertz, 80*time.Millisecond)
 
 		util.Flash(p.ResetPin, 1*time.Millisecond, nil)
-		// If the lights are off, turn them to low. If low, go high. If high, turn off.
-		// If in the middle of using the dimmer, move directly to "high".
 		log.WithFields(log.Fields{
 			"oldsettings": oldLightSettings,
 		}).Debug("Changing light settings")
-		var newSettings lights.LightConfig
-		switch oldLightSettings.Name {
-		case "LIGHTS_OFF":
-			newSettings.Name = "LIGHTS_LOW"
-			newSettings.R = lights.Full.R / 10
-			newSettings.G = lights.Full.G / 10
-			newSettings.W = lights.Full.W / 10
-			newSettings.B = lights.Full.B / 10
-		case "LIGHTS_FULL":
-			newSettings.Name = "LIGHTS_OFF"
-			// Use default zero values
-		case "LIGHTS_LOW":
-			newSettings = lights.Full
-		default:
-			newSettings = lights.Full
-		}
+		newSettings := p.nextScene(oldLightSettings.Name)
 		log.WithFields(log.Fields{
 			"old": oldLightSettings,
 			"new": newSettings,
 		}).Debug("light settings updated")
 
-		oldLightSettings.Name = newSettings.Name
-		oldLightSettings.R = newSettings.R
-		oldLightSettings.G = newSettings.G
-		oldLightSettings.W = newSettings.W
-		oldLightSettings.B = newSettings.B
+		*oldLightSettings = newSettings
 		return true
 	}
 	return false
 }
 
+// nextScene finds the scene after the current one in the panel's cycle.
+func (p *ControlPanel) nextScene(current string) lights.LightConfig {
+	return NextScene(p.Scenes, p.SceneCycle, current, p.logger)
+}
+
+// NextScene finds the scene after the current one in a panel's cycle. If
+// the lights aren't in any of the cycle's scenes, it starts from the
+// beginning. Scenes that can't be found are skipped.
+func NextScene(scenes *lights.SceneStore, cycle []string, current string, logger *log.Entry) lights.LightConfig {
+	next := 0
+	for i, id := range cycle {
+		if id == current {
+			next = i + 1
+			break
+		}
+	}
+	for i := range cycle {
+		id := cycle[(next+i)%len(cycle)]
+		settings, err := scenes.SceneSettings(id)
+		if err != nil {
+			logger.WithError(err).WithField("scene", id).Error("Skipping scene in the panel cycle")
+			continue
+		}
+		return settings
+	}
+	return lights.LightSettingsFull()
+}
+
 // StartPagerInterrupt will watch for edges on the Pager pin, and write into the given
 // channel when one is detected. It runs forever until the context is cancelled.
 func (p *ControlPanel) StartPagerInterrupt(ctx context.Context, ch chan uint8, panelCode uint8) {
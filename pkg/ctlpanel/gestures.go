@@ -0,0 +1,164 @@
+package ctlpanel
+
+import (
+	"time"
+
+	"github.com/klaital/wannetiot/pkg/lights"
+	"github.com/klaital/wannetiot/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"periph.io/x/conn/v3/physic"
+)
+
+// Gesture is a way of touching the panel's light switch.
+type Gesture int
+
+const (
+	GestureNone Gesture = iota
+	GestureTap
+	GestureDoubleTap
+	GestureLongPress
+)
+
+func (g Gesture) String() string {
+	switch g {
+	case GestureTap:
+		return "tap"
+	case GestureDoubleTap:
+		return "double-tap"
+	case GestureLongPress:
+		return "long-press"
+	}
+	return "none"
+}
+
+// gestureSampleInterval is how often the switch is sampled while classifying a gesture.
+const gestureSampleInterval = 10 * time.Millisecond
+
+// ReadGesture checks the light switch latch, and if it has been touched,
+// watches it long enough to tell a tap from a double tap or a long press.
+// The latch is reset as it is sampled, so it only stays set while the
+// switch is still being touched.
+func (p *ControlPanel) ReadGesture() Gesture {
+	if !p.LightSwitchPin.Read() {
+		return GestureNone
+	}
+
+	// Still held after the long press threshold?
+	touched := time.Now()
+	for time.Since(touched) < p.LongPress {
+		p.ResetLatches()
+		time.Sleep(gestureSampleInterval)
+		if !p.LightSwitchPin.Read() {
+			break
+		}
+	}
+	if time.Since(touched) >= p.LongPress {
+		p.waitForRelease()
+		return GestureLongPress
+	}
+
+	// Touched again soon after release?
+	released := time.Now()
+	for time.Since(released) < p.DoubleTapWindow {
+		time.Sleep(gestureSampleInterval)
+		if p.LightSwitchPin.Read() {
+			p.waitForRelease()
+			return GestureDoubleTap
+		}
+	}
+	return GestureTap
+}
+
+// waitForRelease resets the latch until the switch is no longer touched.
+func (p *ControlPanel) waitForRelease() {
+	for {
+		p.ResetLatches()
+		time.Sleep(gestureSampleInterval)
+		if !p.LightSwitchPin.Read() {
+			return
+		}
+	}
+}
+
+// Tone is one beep in a feedback pattern, followed by a pause.
+type Tone struct {
+	Frequency physic.Frequency
+	Duration  time.Duration
+	Pause     time.Duration
+}
+
+// Feedback patterns, so each action can be told apart without looking.
+var (
+	// ToneSnooze is two falling chirps.
+	ToneSnooze = []Tone{
+		{Frequency: 6 * physic.KiloHertz, Duration: 80 * time.Millisecond, Pause: 60 * time.Millisecond},
+		{Frequency: 4 * physic.KiloHertz, Duration: 160 * time.Millisecond},
+	}
+	// ToneDismissOn is three rising chirps.
+	ToneDismissOn = []Tone{
+		{Frequency: 4 * physic.KiloHertz, Duration: 60 * time.Millisecond, Pause: 40 * time.Millisecond},
+		{Frequency: 5 * physic.KiloHertz, Duration: 60 * time.Millisecond, Pause: 40 * time.Millisecond},
+		{Frequency: 7 * physic.KiloHertz, Duration: 120 * time.Millisecond},
+	}
+	// ToneDismissOff is a single long, low tone.
+	ToneDismissOff = []Tone{
+		{Frequency: 3 * physic.KiloHertz, Duration: 400 * time.Millisecond},
+	}
+	// ToneSleep is three slow, falling tones.
+	ToneSleep = []Tone{
+		{Frequency: 5 * physic.KiloHertz, Duration: 150 * time.Millisecond, Pause: 120 * time.Millisecond},
+		{Frequency: 4 * physic.KiloHertz, Duration: 150 * time.Millisecond, Pause: 120 * time.Millisecond},
+		{Frequency: 3 * physic.KiloHertz, Duration: 300 * time.Millisecond},
+	}
+	// ToneDenied is a rapid low buzz.
+	ToneDenied = []Tone{
+		{Frequency: 2 * physic.KiloHertz, Duration: 50 * time.Millisecond, Pause: 30 * time.Millisecond},
+		{Frequency: 2 * physic.KiloHertz, Duration: 50 * time.Millisecond, Pause: 30 * time.Millisecond},
+		{Frequency: 2 * physic.KiloHertz, Duration: 50 * time.Millisecond},
+	}
+)
+
+// PlayTones chirps each tone in turn on the panel's speaker, blinking the LED along with it.
+func (p *ControlPanel) PlayTones(tones []Tone) {
+	for _, t := range tones {
+		if p.LedPin != nil {
+			util.Flash(p.LedPin, 1*time.Millisecond, p.logger)
+		}
+		p.Chirp(t.Frequency, t.Duration)
+		time.Sleep(t.Pause)
+	}
+}
+
+// handleWakeupGesture snoozes or dismisses the wakeup sequence in progress.
+// A tap snoozes, a long press dismisses to full brightness, and a double tap
+// dismisses to off. Returns true if the light settings were changed.
+func (p *ControlPanel) handleWakeupGesture(g Gesture, lightSettings *lights.LightConfig) (updated bool) {
+	logger := p.logger.WithFields(log.Fields{
+		"op":      "ControlPanel#handleWakeupGesture",
+		"gesture": g.String(),
+	})
+	switch g {
+	case GestureTap:
+		if err := lights.SnoozeWakeup(p.SnoozeDuration); err != nil {
+			logger.WithError(err).Info("Unable to snooze wakeup")
+			p.PlayTones(ToneDenied)
+			return false
+		}
+		logger.Info("Wakeup snoozed from panel")
+		p.PlayTones(ToneSnooze)
+		return false
+	case GestureLongPress:
+		lights.HaltWakeup()
+		logger.Info("Wakeup dismissed from panel, lights on")
+		*lightSettings = lights.LightSettingsFull()
+		p.PlayTones(ToneDismissOn)
+		return true
+	case GestureDoubleTap:
+		lights.HaltWakeup()
+		logger.Info("Wakeup dismissed from panel, lights off")
+		*lightSettings = lights.Off
+		p.PlayTones(ToneDismissOff)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The types of event published by the nodes.
+const (
+	// Lights is published with the new state when the lights change.
+	Lights = "lights"
+	// Wakeup reports a wakeup starting, its progress, snoozes, and its end.
+	Wakeup = "wakeup"
+	// Pager reports the pager being pressed, received and acknowledged.
+	Pager = "pager"
+	// Sensor is published with each new sensor reading.
+	Sensor = "sensor"
+	// Alarm reports a leak or fire detected by a node.
+	Alarm = "alarm"
+	// Resync tells a subscriber that events were missed, and it should
+	// fetch the current state again.
+	Resync = "resync"
+)
+
+// Types lists the event types that can be subscribed to.
+var Types = []string{Lights, Wakeup, Pager, Sensor, Alarm}
+
+// Event is something that happened on the node. IDs increase through the
+// life of the broker, and are prefixed with when it started, so that a
+// subscriber reconnecting after a restart can tell it missed events.
+type Event struct {
+	ID   string          `json:"id"`
+	Type string          `json:"type"`
+	Time time.Time       `json:"time"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Broker fans events out to subscribers, and keeps the most recent ones so
+// that subscribers can catch up on the events they missed while reconnecting.
+type Broker struct {
+	lock    sync.Mutex
+	epoch   string
+	seq     uint64
+	history []Event
+	size    int
+	subs    map[*Subscription]bool
+}
+
+// NewBroker creates a broker that keeps the last history events.
+func NewBroker(history int) *Broker {
+	return &Broker{
+		epoch: strconv.FormatInt(time.Now().Unix(), 36),
+		size:  history,
+		subs:  make(map[*Subscription]bool),
+	}
+}
+
+// Publish sends an event to every subscriber that wants its type. The data
+// is marshalled as JSON straight away, so it can be changed afterwards.
+// Subscribers that have fallen too far behind are dropped, and can catch up
+// by subscribing again from the last event they received.
+func (b *Broker) Publish(typ string, data interface{}) (Event, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, err
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.seq++
+	e := Event{
+		ID:   fmt.Sprintf("%s-%d", b.epoch, b.seq),
+		Type: typ,
+		Time: time.Now(),
+		Data: raw,
+	}
+	b.history = append(b.history, e)
+	if len(b.history) > b.size {
+		b.history = b.history[len(b.history)-b.size:]
+	}
+	for s := range b.subs {
+		if !s.wants(typ) {
+			continue
+		}
+		select {
+		case s.c <- e:
+		default:
+			b.drop(s)
+		}
+	}
+	return e, nil
+}
+
+// drop closes a subscription. The lock must be held.
+func (b *Broker) drop(s *Subscription) {
+	if b.subs[s] {
+		delete(b.subs, s)
+		close(s.c)
+	}
+}
+
+// sequence finds the sequence number of an event ID from this broker.
+func (b *Broker) sequence(id string) (uint64, bool) {
+	i := strings.LastIndex(id, "-")
+	if i < 0 || id[:i] != b.epoch {
+		return 0, false
+	}
+	seq, err := strconv.ParseUint(id[i+1:], 10, 64)
+	if err != nil || seq > b.seq {
+		return 0, false
+	}
+	return seq, true
+}
+
+// Subscription receives the events of the types it asked for on C, which is
+// closed if it falls too far behind.
+type Subscription struct {
+	C     <-chan Event
+	c     chan Event
+	types map[string]bool
+}
+
+func (s *Subscription) wants(typ string) bool {
+	return len(s.types) == 0 || s.types[typ]
+}
+
+// Subscribe starts receiving events of the given types, or of every type
+// when none are given. With the ID of the last event the subscriber saw, the
+// events since then are returned to be sent first. If some of them are no
+// longer kept, or the ID is from before a restart, complete is false.
+func (b *Broker) Subscribe(types []string, lastID string, buffer int) (s *Subscription, missed []Event, complete bool) {
+	s = &Subscription{c: make(chan Event, buffer), types: make(map[string]bool)}
+	s.C = s.c
+	for _, t := range types {
+		s.types[t] = true
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.subs[s] = true
+
+	complete = true
+	if lastID != "" {
+		seq, ok := b.sequence(lastID)
+		oldest := b.seq - uint64(len(b.history)) + 1
+		if !ok || seq+1 < oldest {
+			complete = false
+		}
+		for _, e := range b.history {
+			if n, _ := b.sequence(e.ID); n > seq && s.wants(e.Type) {
+				missed = append(missed, e)
+			}
+		}
+	}
+	return s, missed, complete
+}
+
+// Unsubscribe stops the subscription receiving events.
+func (b *Broker) Unsubscribe(s *Subscription) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.drop(s)
+}
+
+// ValidType reports whether the type can be subscribed to.
+func ValidType(typ string) bool {
+	for _, t := range Types {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
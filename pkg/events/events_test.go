@@ -0,0 +1,136 @@
+package events
+
+import (
+	"fmt"
+	"testing"
+)
+
+// newTestBroker returns a broker with a known epoch, that has published the
+// types in order.
+func newTestBroker(t *testing.T, epoch string, history int, types ...string) *Broker {
+	b := NewBroker(history)
+	b.epoch = epoch
+	for i, typ := range types {
+		if _, err := b.Publish(typ, i+1); err != nil {
+			t.Fatalf("publishing %s: %v", typ, err)
+		}
+	}
+	return b
+}
+
+func ids(events []Event) []string {
+	out := make([]string, 0, len(events))
+	for _, e := range events {
+		out = append(out, e.ID)
+	}
+	return out
+}
+
+func TestSubscribeReplay(t *testing.T) {
+	tests := []struct {
+		name   string
+		types  []string
+		lastID string
+		// want are the IDs of the missed events
+		want     []string
+		complete bool
+	}{
+		{name: "new subscriber", want: []string{}, complete: true},
+		{name: "up to date", lastID: "e1-5", want: []string{}, complete: true},
+		{name: "missed some", lastID: "e1-3", want: []string{"e1-4", "e1-5"}, complete: true},
+		{name: "missed all that are kept", lastID: "e1-2", want: []string{"e1-3", "e1-4", "e1-5"}, complete: true},
+		{name: "missed more than are kept", lastID: "e1-1", want: []string{"e1-3", "e1-4", "e1-5"}, complete: false},
+		{name: "only the types asked for", types: []string{Sensor}, lastID: "e1-2", want: []string{"e1-4"}, complete: true},
+		{name: "from before a restart", lastID: "e0-4", want: []string{"e1-3", "e1-4", "e1-5"}, complete: false},
+		{name: "from the future", lastID: "e1-9", want: []string{"e1-3", "e1-4", "e1-5"}, complete: false},
+		{name: "not an event ID", lastID: "bogus", want: []string{"e1-3", "e1-4", "e1-5"}, complete: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBroker(t, "e1", 3, Lights, Sensor, Lights, Sensor, Lights)
+			s, missed, complete := b.Subscribe(tt.types, tt.lastID, 1)
+			defer b.Unsubscribe(s)
+			if got := ids(missed); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("missed %v, want %v", got, tt.want)
+			}
+			if complete != tt.complete {
+				t.Errorf("complete is %v, want %v", complete, tt.complete)
+			}
+		})
+	}
+}
+
+func TestSubscribeAfterRestart(t *testing.T) {
+	before := newTestBroker(t, "e1", 10, Lights, Lights)
+	s, _, _ := before.Subscribe(nil, "", 10)
+	last, err := before.Publish(Wakeup, "started")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := <-s.C; got.ID != last.ID {
+		t.Fatalf("received %s, want %s", got.ID, last.ID)
+	}
+
+	// The new broker's sequence starts again, so the old ID's number means nothing
+	after := newTestBroker(t, "e2", 10, Lights, Lights, Lights, Lights)
+	s, missed, complete := after.Subscribe(nil, last.ID, 10)
+	defer after.Unsubscribe(s)
+	if complete {
+		t.Errorf("resubscribing after a restart was complete")
+	}
+	if got, want := fmt.Sprint(ids(missed)), "[e2-1 e2-2 e2-3 e2-4]"; got != want {
+		t.Errorf("missed %s, want %s", got, want)
+	}
+}
+
+func TestPublish(t *testing.T) {
+	b := newTestBroker(t, "e1", 10)
+	all, _, _ := b.Subscribe(nil, "", 10)
+	sensors, _, _ := b.Subscribe([]string{Sensor}, "", 10)
+	slow, _, _ := b.Subscribe(nil, "", 1)
+
+	for _, typ := range []string{Lights, Sensor} {
+		if _, err := b.Publish(typ, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		sub  *Subscription
+		want []string
+		// closed is whether the subscription was dropped for falling behind
+		closed bool
+	}{
+		{"every type", all, []string{"e1-1", "e1-2"}, false},
+		{"one type", sensors, []string{"e1-2"}, false},
+		{"fallen behind", slow, []string{"e1-1"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []Event
+			for range tt.want {
+				e, ok := <-tt.sub.C
+				if !ok {
+					t.Fatalf("closed after %v", ids(got))
+				}
+				got = append(got, e)
+			}
+			if fmt.Sprint(ids(got)) != fmt.Sprint(tt.want) {
+				t.Errorf("received %v, want %v", ids(got), tt.want)
+			}
+			select {
+			case e, ok := <-tt.sub.C:
+				if ok {
+					t.Errorf("received %s as well", e.ID)
+				} else if !tt.closed {
+					t.Errorf("closed unexpectedly")
+				}
+			default:
+				if tt.closed {
+					t.Errorf("not dropped")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+// Package health collects the health of a node's subsystems. Each subsystem
+// registers a Reporter when it starts, and the node's status endpoints ask
+// every reporter for its health when they are called.
+package health
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Status is how well a subsystem is working.
+type Status string
+
+const (
+	// StatusOK means the subsystem is working.
+	StatusOK Status = "ok"
+	// StatusDegraded means the subsystem is working, but has had recent errors.
+	StatusDegraded Status = "degraded"
+	// StatusDown means the subsystem has stopped working.
+	StatusDown Status = "down"
+)
+
+// Worse returns the worse of the two statuses.
+func (s Status) Worse(other Status) Status {
+	rank := map[Status]int{StatusOK: 0, StatusDegraded: 1, StatusDown: 2}
+	if rank[other] > rank[s] {
+		return other
+	}
+	return s
+}
+
+// Report is the health of one subsystem.
+type Report struct {
+	Name   string `json:"name"`
+	Status Status `json:"status"`
+	// Critical subsystems must not be down for the node to be ready.
+	Critical    bool       `json:"critical"`
+	Message     string     `json:"message,omitempty"`
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+	// Errors counts the errors since the subsystem started.
+	Errors  int                    `json:"errors,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// Reporter reports the health of a subsystem.
+type Reporter interface {
+	Health() Report
+}
+
+// ReporterFunc adapts a function to a Reporter.
+type ReporterFunc func() Report
+
+func (f ReporterFunc) Health() Report {
+	return f()
+}
+
+type entry struct {
+	critical bool
+	reporter Reporter
+}
+
+// Registry holds the reporters of a node's subsystems.
+type Registry struct {
+	lock    sync.Mutex
+	entries map[string]entry
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{entries: make(map[string]entry)}
+}
+
+// Register adds a subsystem's reporter, replacing any already registered
+// under the same name.
+func (r *Registry) Register(name string, critical bool, reporter Reporter) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.entries[name] = entry{critical: critical, reporter: reporter}
+}
+
+// Unregister removes a subsystem's reporter.
+func (r *Registry) Unregister(name string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.entries, name)
+}
+
+// Check asks every reporter for its health, sorted by name. The status is
+// the worst of them all, and ready is false if a critical one is down.
+func (r *Registry) Check() (reports []Report, status Status, ready bool) {
+	r.lock.Lock()
+	entries := make(map[string]entry, len(r.entries))
+	for name, e := range r.entries {
+		entries[name] = e
+	}
+	r.lock.Unlock()
+
+	status, ready = StatusOK, true
+	reports = make([]Report, 0, len(entries))
+	for name, e := range entries {
+		report := e.reporter.Health()
+		report.Name = name
+		report.Critical = e.critical
+		if report.Status == "" {
+			report.Status = StatusOK
+		}
+		status = status.Worse(report.Status)
+		if e.critical && report.Status == StatusDown {
+			ready = false
+		}
+		reports = append(reports, report)
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].Name < reports[j].Name })
+	return reports, status, ready
+}
+
+// Default is the registry the node's subsystems register with.
+var Default = NewRegistry()
+
+// Register adds a subsystem's reporter to the default registry.
+func Register(name string, critical bool, reporter Reporter) {
+	Default.Register(name, critical, reporter)
+}
@@ -0,0 +1,114 @@
+package health
+
+import (
+	"sync"
+	"time"
+)
+
+// Tracker records the results of something a subsystem does repeatedly,
+// such as reading a sensor or writing telemetry.
+type Tracker struct {
+	lock        sync.Mutex
+	stale       time.Duration
+	lastSuccess time.Time
+	lastErr     error
+	errors      int
+}
+
+// NewTracker creates a tracker that is down when it has gone longer than
+// stale without a success. Zero means it is never down, only degraded.
+func NewTracker(stale time.Duration) *Tracker {
+	return &Tracker{stale: stale}
+}
+
+// Success records that the last attempt worked.
+func (t *Tracker) Success() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.lastSuccess = time.Now()
+	t.lastErr = nil
+}
+
+// Failure records that the last attempt failed.
+func (t *Tracker) Failure(err error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.lastErr = err
+	t.errors++
+}
+
+// Health reports the tracker as OK while the last attempt worked, degraded
+// when it failed, and down once there has been no success for too long.
+func (t *Tracker) Health() Report {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	r := Report{Status: StatusOK, Errors: t.errors}
+	if !t.lastSuccess.IsZero() {
+		last := t.lastSuccess
+		r.LastSuccess = &last
+	}
+	if t.lastErr != nil {
+		r.Status = StatusDegraded
+		r.LastError = t.lastErr.Error()
+		if t.stale > 0 && time.Since(t.lastSuccess) > t.stale {
+			r.Status = StatusDown
+		}
+	}
+	return r
+}
+
+// Loop tracks whether a background loop is running. Loops that wake up
+// regularly can also Beat, so that one that is stuck shows as down.
+type Loop struct {
+	lock     sync.Mutex
+	stall    time.Duration
+	running  bool
+	stopped  bool
+	lastBeat time.Time
+}
+
+// NewLoop creates a loop that is down once it has gone longer than stall
+// without a beat. Zero means it doesn't need to beat.
+func NewLoop(stall time.Duration) *Loop {
+	return &Loop{stall: stall}
+}
+
+// Start records that the loop is running.
+func (l *Loop) Start() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.running, l.stopped = true, false
+	l.lastBeat = time.Now()
+}
+
+// Beat records that the loop is still going round.
+func (l *Loop) Beat() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.lastBeat = time.Now()
+}
+
+// Stop records that the loop has exited.
+func (l *Loop) Stop() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.running, l.stopped = false, true
+}
+
+func (l *Loop) Health() Report {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	switch {
+	case l.stopped:
+		return Report{Status: StatusDown, Message: "stopped"}
+	case !l.running:
+		return Report{Status: StatusDown, Message: "not started"}
+	}
+	last := l.lastBeat
+	r := Report{Status: StatusOK, LastSuccess: &last}
+	if l.stall > 0 && time.Since(l.lastBeat) > l.stall {
+		r.Status = StatusDown
+		r.Message = "stalled"
+	}
+	return r
+}
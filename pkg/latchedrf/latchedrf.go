@@ -3,6 +3,7 @@ package latchedrf
 import (
 	"context"
 	"errors"
+	"github.com/klaital/wannetiot/pkg/health"
 	"github.com/klaital/wannetiot/pkg/util"
 	log "github.com/sirupsen/logrus"
 	"periph.io/x/conn/v3/gpio"
@@ -143,6 +144,16 @@ func (r *LatchedRadioReceiver) RegisterChannelDHandler(handlerFunc Handler) {
 // Run sets up watches on each of the input channels.
 // When one is triggered, the registered handler is executed, then the latch is reset.
 func (r *LatchedRadioReceiver) Run(ctx context.Context) {
+	// Each channel waits for an edge for WaitTimeout at a time, so they're
+	// stuck if none has come round for several times that
+	loop := health.NewLoop(10 * r.WaitTimeout)
+	health.Register("rf", true, loop)
+	loop.Start()
+	go func() {
+		<-ctx.Done()
+		loop.Stop()
+	}()
+
 	handlePin := func(c gpio.PinIn, h Handler) {
 		log.WithField("pin", c.Name()).Debug("Listening for edges on RF pin")
 		for {
@@ -150,6 +161,7 @@ func (r *LatchedRadioReceiver) Run(ctx context.Context) {
 			case <-ctx.Done():
 				return
 			default:
+				loop.Beat()
 				if c.WaitForEdge(r.WaitTimeout) {
 					if h != nil {
 						h()
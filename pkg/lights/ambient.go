@@ -0,0 +1,125 @@
+package lights
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/klaital/wannetiot/pkg/config"
+)
+
+// AutoBrightness adapts the "low" and "full" scenes to the ambient light, so
+// that they are gentler in a dark room, and skips the wakeup lights when the
+// room is already bright.
+type AutoBrightness struct {
+	Enabled bool
+	// DarkLux and below scales the scenes to MinLevel of their brightness.
+	// BrightLux and above leaves them at their full brightness.
+	DarkLux   float64
+	BrightLux float64
+	MinLevel  float64
+	// SkipWakeupLux skips the wakeup lights when the room is at least this
+	// bright. Zero never skips them.
+	SkipWakeupLux float64
+}
+
+// ambientStale is how old a reading can be before the light level is treated as unknown.
+const ambientStale = 5 * time.Minute
+
+var autoBrightness AutoBrightness
+var ambientLux float64
+var ambientRead time.Time
+var ambientLock sync.Mutex
+
+// NewAutoBrightness reads the auto-brightness settings from the app config.
+func NewAutoBrightness(cfg *config.Config) AutoBrightness {
+	return AutoBrightness{
+		Enabled:       cfg.AutoBrightness,
+		DarkLux:       cfg.AutoBrightnessDarkLux,
+		BrightLux:     cfg.AutoBrightnessBrightLux,
+		MinLevel:      cfg.AutoBrightnessMinLevel,
+		SkipWakeupLux: cfg.AutoBrightnessSkipWakeupLux,
+	}
+}
+
+// ConfigureAutoBrightness replaces the auto-brightness settings.
+func ConfigureAutoBrightness(a AutoBrightness) {
+	ambientLock.Lock()
+	defer ambientLock.Unlock()
+	autoBrightness = a
+}
+
+// SetAmbientLux records the latest ambient light reading.
+func SetAmbientLux(lux float64) {
+	ambientLock.Lock()
+	defer ambientLock.Unlock()
+	ambientLux = lux
+	ambientRead = time.Now()
+}
+
+// AmbientLux reports the latest ambient light reading, if there is a recent one.
+func AmbientLux() (lux float64, ok bool) {
+	ambientLock.Lock()
+	defer ambientLock.Unlock()
+	if ambientRead.IsZero() || time.Since(ambientRead) > ambientStale {
+		return 0, false
+	}
+	return ambientLux, true
+}
+
+// Level maps the light level onto the fraction of their brightness the
+// scenes are shown at. The eye sees light levels logarithmically, so the
+// level rises evenly with the log of the lux.
+func (a AutoBrightness) Level(lux float64) float64 {
+	if a.BrightLux <= a.DarkLux {
+		return 1
+	}
+	logLux := func(l float64) float64 { return math.Log1p(math.Max(0, l)) }
+	f := clampProgress((logLux(lux) - logLux(a.DarkLux)) / (logLux(a.BrightLux) - logLux(a.DarkLux)))
+	return a.MinLevel + (1-a.MinLevel)*f
+}
+
+// AutoBrightnessLevel reports the fraction of their brightness the "low" and
+// "full" scenes are currently shown at. It is 1.0 when auto-brightness is
+// off, or there is no recent reading.
+func AutoBrightnessLevel() float64 {
+	lux, ok := AmbientLux()
+	ambientLock.Lock()
+	a := autoBrightness
+	ambientLock.Unlock()
+	if !a.Enabled || !ok {
+		return 1
+	}
+	return a.Level(lux)
+}
+
+// ambientSkipsWakeup reports whether the room is already bright enough to
+// skip the wakeup lights.
+func ambientSkipsWakeup() (lux float64, skip bool) {
+	lux, ok := AmbientLux()
+	ambientLock.Lock()
+	a := autoBrightness
+	ambientLock.Unlock()
+	return lux, a.Enabled && ok && a.SkipWakeupLux > 0 && lux >= a.SkipWakeupLux
+}
+
+// autoScaled applies auto-brightness to the "low" and "full" scenes.
+func (s Scene) autoScaled() Scene {
+	if s.ID != "low" && s.ID != "full" {
+		return s
+	}
+	level := AutoBrightnessLevel()
+	if level == 1 {
+		return s
+	}
+	s.Brightness *= level
+	if len(s.Zones) > 0 {
+		zones := make(map[string]SceneZone, len(s.Zones))
+		for name, zone := range s.Zones {
+			zone.Brightness *= level
+			zones[name] = zone
+		}
+		s.Zones = zones
+	}
+	return s
+}
@@ -0,0 +1,156 @@
+package lights
+
+import (
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/klaital/wannetiot/pkg/config"
+	"periph.io/x/conn/v3/gpio"
+)
+
+// ChannelFactors holds a multiplier for each of the strip's channels.
+type ChannelFactors struct {
+	R float64
+	G float64
+	W float64
+	B float64
+}
+
+// BrightnessModel maps perceived brightness onto PWM duty cycles. LED output
+// is roughly linear in duty, but our eyes are not, so a linear ramp looks far
+// too bright at the low end.
+type BrightnessModel struct {
+	// Curve is either "cie" to use the CIE 1976 L* lightness formula, or
+	// "gamma" to use a simple power curve with the given Gamma.
+	Curve string
+	Gamma float64
+
+	// MinDuty is the smallest non-zero duty cycle to drive on any channel, as
+	// a fraction of full power. Below this the LEDs visibly flicker.
+	MinDuty float64
+	// MaxDuty caps each channel, as a fraction of full power.
+	MaxDuty ChannelFactors
+	// Calibration scales each channel to balance the LEDs against each other.
+	Calibration ChannelFactors
+}
+
+// DefaultBrightnessModel uses CIE L* with no calibration applied.
+func DefaultBrightnessModel() BrightnessModel {
+	return BrightnessModel{
+		Curve:       "cie",
+		Gamma:       2.2,
+		MinDuty:     0.01,
+		MaxDuty:     ChannelFactors{R: 1, G: 1, W: 1, B: 1},
+		Calibration: ChannelFactors{R: 1, G: 1, W: 1, B: 1},
+	}
+}
+
+// NewBrightnessModel loads the brightness model from the app config.
+func NewBrightnessModel(cfg *config.Config) BrightnessModel {
+	return BrightnessModel{
+		Curve:   cfg.BrightnessCurve,
+		Gamma:   cfg.BrightnessGamma,
+		MinDuty: cfg.MinDuty,
+		MaxDuty: ChannelFactors{
+			R: cfg.MaxDutyRed,
+			G: cfg.MaxDutyGreen,
+			W: cfg.MaxDutyWhite,
+			B: cfg.MaxDutyBlue,
+		},
+		Calibration: ChannelFactors{
+			R: cfg.CalibrationRed,
+			G: cfg.CalibrationGreen,
+			W: cfg.CalibrationWhite,
+			B: cfg.CalibrationBlue,
+		},
+	}
+}
+
+// Luminance converts a perceived brightness level (0.0 - 1.0) into the
+// fraction of full light output needed to produce it.
+func (m BrightnessModel) Luminance(perceived float64) float64 {
+	if perceived <= 0 {
+		return 0
+	}
+	if perceived >= 1 {
+		return 1
+	}
+	if strings.ToLower(m.Curve) == "gamma" {
+		gamma := m.Gamma
+		if gamma <= 0 {
+			gamma = 2.2
+		}
+		return math.Pow(perceived, gamma)
+	}
+
+	// CIE 1976: L* = 116 * Y^(1/3) - 16, for L* > 8
+	lightness := perceived * 100
+	if lightness <= 8 {
+		return lightness / 903.3
+	}
+	return math.Pow((lightness+16)/116, 3)
+}
+
+// Scale dims the colour mix in `base` to the given perceived brightness level.
+func (m BrightnessModel) Scale(base LightConfig, perceived float64) LightConfig {
+	y := m.Luminance(perceived)
+	scale := func(d gpio.Duty) gpio.Duty {
+		return gpio.Duty(math.Round(float64(d) * y))
+	}
+	return LightConfig{
+		Name:   base.Name,
+		R:      scale(base.R),
+		G:      scale(base.G),
+		W:      scale(base.W),
+		B:      scale(base.B),
+		Kelvin: base.Kelvin,
+	}
+}
+
+// Calibrate applies the per-channel calibration and max duty caps, and lifts
+// any channel that is on, but below the minimum duty, up to the minimum.
+func (m BrightnessModel) Calibrate(settings LightConfig) LightConfig {
+	calibrate := func(d gpio.Duty, factor, max float64) gpio.Duty {
+		if d <= 0 {
+			return 0
+		}
+		v := float64(d) * factor
+		if limit := max * float64(gpio.DutyMax); v > limit {
+			v = limit
+		}
+		if floor := m.MinDuty * float64(gpio.DutyMax); v < floor {
+			v = floor
+		}
+		if v > float64(gpio.DutyMax) {
+			v = float64(gpio.DutyMax)
+		}
+		return gpio.Duty(math.Round(v))
+	}
+	return LightConfig{
+		Name:   settings.Name,
+		R:      calibrate(settings.R, m.Calibration.R, m.MaxDuty.R),
+		G:      calibrate(settings.G, m.Calibration.G, m.MaxDuty.G),
+		W:      calibrate(settings.W, m.Calibration.W, m.MaxDuty.W),
+		B:      calibrate(settings.B, m.Calibration.B, m.MaxDuty.B),
+		Kelvin: settings.Kelvin,
+	}
+}
+
+// brightness is the model used for all output to the strip.
+var brightness = DefaultBrightnessModel()
+var brightnessLock sync.RWMutex
+
+// ConfigureBrightness replaces the brightness model used to drive the strip.
+func ConfigureBrightness(m BrightnessModel) {
+	brightnessLock.Lock()
+	defer brightnessLock.Unlock()
+	brightness = m
+}
+
+// Brightness returns the brightness model currently in use.
+func Brightness() BrightnessModel {
+	brightnessLock.RLock()
+	defer brightnessLock.RUnlock()
+	return brightness
+}
@@ -0,0 +1,165 @@
+package lights
+
+import (
+	"errors"
+	"math"
+	"sync"
+
+	"github.com/klaital/wannetiot/pkg/config"
+	"periph.io/x/conn/v3/gpio"
+)
+
+// KelvinToRGB approximates the colour of a black body radiator at the given
+// temperature, using Tanner Helland's curve fit to the CIE 1964 colour
+// matching functions. Each component is in the range 0.0 - 1.0.
+func KelvinToRGB(kelvin float64) (r, g, b float64) {
+	t := kelvin / 100
+	clamp := func(v float64) float64 {
+		return math.Max(0, math.Min(255, v)) / 255
+	}
+
+	if t <= 66 {
+		r = 1
+		g = clamp(99.4708025861*math.Log(t) - 161.1195681661)
+	} else {
+		r = clamp(329.698727446 * math.Pow(t-60, -0.1332047592))
+		g = clamp(288.1221695283 * math.Pow(t-60, -0.0755148492))
+	}
+
+	switch {
+	case t >= 66:
+		b = 1
+	case t <= 19:
+		b = 0
+	default:
+		b = clamp(138.5177312231*math.Log(t-10) - 305.0447927307)
+	}
+	return r, g, b
+}
+
+// ColorProfile describes the LEDs on our RGWB strip, so that a target colour
+// temperature can be mixed from the white LED plus the coloured ones.
+type ColorProfile struct {
+	// WhiteKelvin is the correlated colour temperature of the white LED.
+	WhiteKelvin float64
+	// Gain balances the output of each channel relative to the white LED. A
+	// gain below 1.0 means that channel appears brighter than the white.
+	Gain ChannelFactors
+	// MinKelvin and MaxKelvin bound the temperatures that may be requested.
+	MinKelvin float64
+	MaxKelvin float64
+}
+
+// DefaultColorProfile approximates a 4000K white LED.
+func DefaultColorProfile() ColorProfile {
+	return ColorProfile{
+		WhiteKelvin: 4000,
+		Gain:        ChannelFactors{R: 0.75, G: 0.3, W: 1.0, B: 0.5},
+		MinKelvin:   1800,
+		MaxKelvin:   6500,
+	}
+}
+
+// NewColorProfile loads the strip's colour calibration from the app config.
+func NewColorProfile(cfg *config.Config) ColorProfile {
+	return ColorProfile{
+		WhiteKelvin: cfg.WhiteKelvin,
+		Gain: ChannelFactors{
+			R: cfg.KelvinGainRed,
+			G: cfg.KelvinGainGreen,
+			W: cfg.KelvinGainWhite,
+			B: cfg.KelvinGainBlue,
+		},
+		MinKelvin: cfg.KelvinMin,
+		MaxKelvin: cfg.KelvinMax,
+	}
+}
+
+var ErrKelvinOutOfRange = errors.New("colour temperature out of the supported range")
+
+// Solve computes the channel duties to produce the given colour temperature
+// at a perceived brightness in the range 0.0 - 1.0.
+// As much of the light as possible comes from the white LED. The coloured
+// channels then make up the difference: red and green to warm it, or blue to
+// cool it. The mix is normalized so that the strongest channel is at full
+// power before the brightness is applied.
+func (p ColorProfile) Solve(kelvin, brightness float64) (LightConfig, error) {
+	if kelvin < p.MinKelvin || kelvin > p.MaxKelvin {
+		return LightConfig{}, ErrKelvinOutOfRange
+	}
+	if brightness < 0 || brightness > 1 {
+		return LightConfig{}, ErrInvalidMultiplier
+	}
+
+	tr, tg, tb := KelvinToRGB(kelvin)
+	wr, wg, wb := KelvinToRGB(p.WhiteKelvin)
+
+	// The largest amount of white that doesn't overshoot the target in any component
+	white := math.Inf(1)
+	for _, c := range [][2]float64{{tr, wr}, {tg, wg}, {tb, wb}} {
+		if c[1] > 0 {
+			white = math.Min(white, c[0]/c[1])
+		}
+	}
+	if math.IsInf(white, 1) {
+		white = 0
+	}
+
+	mix := ChannelFactors{
+		R: (tr - white*wr) * p.Gain.R,
+		G: (tg - white*wg) * p.Gain.G,
+		W: white * p.Gain.W,
+		B: (tb - white*wb) * p.Gain.B,
+	}
+	peak := math.Max(math.Max(mix.R, mix.G), math.Max(mix.W, mix.B))
+	if peak <= 0 {
+		return LightConfig{}, ErrKelvinOutOfRange
+	}
+	duty := func(v float64) gpio.Duty {
+		return gpio.Duty(math.Round(math.Max(0, v) / peak * float64(gpio.DutyMax)))
+	}
+
+	full := LightConfig{
+		Name:   "KELVIN",
+		R:      duty(mix.R),
+		G:      duty(mix.G),
+		W:      duty(mix.W),
+		B:      duty(mix.B),
+		Kelvin: kelvin,
+	}
+	return Brightness().Scale(full, brightness), nil
+}
+
+// colorProfile is the profile used for all colour temperature requests.
+var colorProfile = DefaultColorProfile()
+var colorProfileLock sync.RWMutex
+
+// ConfigureColorProfile replaces the colour calibration of the strip.
+func ConfigureColorProfile(p ColorProfile) {
+	colorProfileLock.Lock()
+	defer colorProfileLock.Unlock()
+	colorProfile = p
+}
+
+// ColorTemperature generates the LightConfig for the given colour temperature
+// and perceived brightness, using the configured colour profile.
+func ColorTemperature(kelvin, brightness float64) (LightConfig, error) {
+	colorProfileLock.RLock()
+	p := colorProfile
+	colorProfileLock.RUnlock()
+	return p.Solve(kelvin, brightness)
+}
+
+// kelvinRange reports the colour temperatures the profile can show.
+func kelvinRange() (min, max float64) {
+	colorProfileLock.RLock()
+	defer colorProfileLock.RUnlock()
+	return colorProfile.MinKelvin, colorProfile.MaxKelvin
+}
+
+// colorProfileKelvin reports the colour temperature of the strip's white LED.
+func colorProfileKelvin() float64 {
+	colorProfileLock.RLock()
+	defer colorProfileLock.RUnlock()
+	return colorProfile.WhiteKelvin
+}
@@ -0,0 +1,213 @@
+package lights
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"periph.io/x/conn/v3/gpio"
+)
+
+// WakeupCurve describes the light settings to drive at each point of the
+// wakeup sequence. Progress runs from 0.0 at the start to 1.0 at the end, at
+// which point the curve should have reached the target settings.
+type WakeupCurve interface {
+	At(progress float64, target LightConfig) LightConfig
+}
+
+// LinearCurve ramps the target colour mix up at a constant rate of perceived
+// brightness.
+type LinearCurve struct{}
+
+func (LinearCurve) At(progress float64, target LightConfig) LightConfig {
+	return Brightness().Scale(target, progress)
+}
+
+// Keyframe is one point on a KeyframeCurve.
+type Keyframe struct {
+	// At is the progress through the wakeup, 0.0 - 1.0, at which this keyframe is reached.
+	At float64
+	// Kelvin is the colour temperature to show. When 0, Color is used instead.
+	Kelvin float64
+	// Color is the colour mix to show, at full brightness.
+	Color LightConfig
+	// Target uses the wakeup's target colour mix, ignoring Kelvin and Color.
+	Target bool
+	// Brightness is the perceived brightness, 0.0 - 1.0.
+	Brightness float64
+}
+
+// KeyframeCurve interpolates between a series of keyframes, sorted by At.
+// Colour temperatures are interpolated in mireds, which better matches how
+// the colour of daylight changes, and brightness is interpolated in
+// perceived brightness.
+type KeyframeCurve []Keyframe
+
+// mix computes the full-brightness colour mix for a keyframe. A colour
+// temperature outside the colour profile's range shows the nearest one it
+// can, rather than going dark.
+func (k Keyframe) mix(target LightConfig) LightConfig {
+	if k.Target {
+		return target
+	}
+	if k.Kelvin > 0 {
+		min, max := kelvinRange()
+		mix, err := ColorTemperature(math.Min(math.Max(k.Kelvin, min), max), 1.0)
+		if err == nil {
+			return mix
+		}
+	}
+	return k.Color
+}
+
+func (c KeyframeCurve) At(progress float64, target LightConfig) LightConfig {
+	if len(c) == 0 {
+		return LinearCurve{}.At(progress, target)
+	}
+	if progress <= c[0].At {
+		return Brightness().Scale(c[0].mix(target), c[0].Brightness)
+	}
+	for i := 1; i < len(c); i++ {
+		from, to := c[i-1], c[i]
+		if progress > to.At {
+			continue
+		}
+		f := 0.0
+		if to.At > from.At {
+			f = (progress - from.At) / (to.At - from.At)
+		}
+
+		var mix LightConfig
+		if from.Kelvin > 0 && to.Kelvin > 0 && !from.Target && !to.Target {
+			mired := 1e6/from.Kelvin + (1e6/to.Kelvin-1e6/from.Kelvin)*f
+			mix = Keyframe{Kelvin: 1e6 / mired, Color: Blend(from.mix(target), to.mix(target), f)}.mix(target)
+		} else {
+			mix = Blend(from.mix(target), to.mix(target), f)
+		}
+		level := from.Brightness + (to.Brightness-from.Brightness)*f
+		return Brightness().Scale(mix, level)
+	}
+	last := c[len(c)-1]
+	return Brightness().Scale(last.mix(target), last.Brightness)
+}
+
+// SunriseCurve starts with a deep red glow at very low brightness, and warms
+// through orange to the target white.
+func SunriseCurve() KeyframeCurve {
+	return KeyframeCurve{
+		{At: 0.0, Color: LightConfig{R: gpio.DutyMax}, Brightness: 0.01},
+		{At: 0.2, Color: LightConfig{R: gpio.DutyMax, G: gpio.DutyMax / 20}, Brightness: 0.15},
+		{At: 0.45, Kelvin: 1800, Brightness: 0.4},
+		{At: 0.7, Kelvin: 2700, Brightness: 0.7},
+		{At: 1.0, Target: true, Brightness: 1.0},
+	}
+}
+
+var ErrInvalidKeyframes = errors.New("invalid wakeup keyframes")
+
+// ParseKeyframes reads a custom curve definition, in the form
+// "at:colour:brightness,...". Colour is either a colour temperature in
+// Kelvin within the colour profile's range, "target" to use the wakeup's
+// target settings, or a mix of R/G/W/B duty percentages such as "100/5/0/0".
+// For example:
+//
+//	0:100/0/0/0:0.01,0.5:2200:0.4,1:target:1
+func ParseKeyframes(spec string) (KeyframeCurve, error) {
+	curve := make(KeyframeCurve, 0)
+	for _, token := range strings.Split(spec, ",") {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+		fields := strings.Split(token, ":")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("%w: %q should be at:colour:brightness", ErrInvalidKeyframes, token)
+		}
+		var k Keyframe
+		var err error
+		if k.At, err = strconv.ParseFloat(fields[0], 64); err != nil || k.At < 0 || k.At > 1 {
+			return nil, fmt.Errorf("%w: invalid progress in %q", ErrInvalidKeyframes, token)
+		}
+		if k.Brightness, err = strconv.ParseFloat(fields[2], 64); err != nil || k.Brightness < 0 || k.Brightness > 1 {
+			return nil, fmt.Errorf("%w: invalid brightness in %q", ErrInvalidKeyframes, token)
+		}
+		switch colour := fields[1]; {
+		case colour == "target":
+			k.Target = true
+		case strings.Contains(colour, "/"):
+			duties := strings.Split(colour, "/")
+			if len(duties) != 4 {
+				return nil, fmt.Errorf("%w: colour mix in %q should be R/G/W/B", ErrInvalidKeyframes, token)
+			}
+			channels := make([]gpio.Duty, 4)
+			for i := range duties {
+				if channels[i], err = gpio.ParseDuty(duties[i] + "%"); err != nil {
+					return nil, fmt.Errorf("%w: invalid duty in %q: %v", ErrInvalidKeyframes, token, err)
+				}
+			}
+			k.Color = LightConfig{R: channels[0], G: channels[1], W: channels[2], B: channels[3]}
+		default:
+			if k.Kelvin, err = strconv.ParseFloat(colour, 64); err != nil || k.Kelvin <= 0 {
+				return nil, fmt.Errorf("%w: invalid colour temperature in %q", ErrInvalidKeyframes, token)
+			}
+			if min, max := kelvinRange(); k.Kelvin < min || k.Kelvin > max {
+				return nil, fmt.Errorf("%w: colour temperature in %q is outside %.0fK - %.0fK", ErrInvalidKeyframes, token, min, max)
+			}
+		}
+		curve = append(curve, k)
+	}
+	if len(curve) == 0 {
+		return nil, ErrInvalidKeyframes
+	}
+	sort.SliceStable(curve, func(i, j int) bool { return curve[i].At < curve[j].At })
+	return curve, nil
+}
+
+// curves holds the wakeup curves that can be selected by name.
+var curves = map[string]WakeupCurve{
+	"sunrise": SunriseCurve(),
+	"linear":  LinearCurve{},
+}
+var curvesLock sync.RWMutex
+
+var ErrUnknownCurve = errors.New("unknown wakeup curve")
+
+// RegisterCurve makes a wakeup curve available by name, such as for custom
+// keyframes loaded from the config.
+func RegisterCurve(name string, curve WakeupCurve) {
+	curvesLock.Lock()
+	defer curvesLock.Unlock()
+	curves[strings.ToLower(name)] = curve
+}
+
+// LookupCurve finds a registered wakeup curve by name.
+func LookupCurve(name string) (WakeupCurve, error) {
+	curvesLock.RLock()
+	defer curvesLock.RUnlock()
+	c, ok := curves[strings.ToLower(name)]
+	if !ok {
+		return nil, ErrUnknownCurve
+	}
+	return c, nil
+}
+
+// CurveNames lists the registered wakeup curves.
+func CurveNames() []string {
+	curvesLock.RLock()
+	defer curvesLock.RUnlock()
+	names := make([]string, 0, len(curves))
+	for name := range curves {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// clampProgress limits progress through a curve to the range 0.0 - 1.0.
+func clampProgress(p float64) float64 {
+	return math.Max(0, math.Min(1, p))
+}
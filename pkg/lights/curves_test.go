@@ -0,0 +1,105 @@
+package lights
+
+import (
+	"errors"
+	"testing"
+)
+
+// useColorProfile replaces the colour profile until the test ends.
+func useColorProfile(t *testing.T, p ColorProfile) {
+	colorProfileLock.RLock()
+	old := colorProfile
+	colorProfileLock.RUnlock()
+	ConfigureColorProfile(p)
+	t.Cleanup(func() { ConfigureColorProfile(old) })
+}
+
+func TestParseKeyframes(t *testing.T) {
+	useColorProfile(t, DefaultColorProfile())
+
+	tests := []struct {
+		name    string
+		spec    string
+		want    int
+		wantErr bool
+	}{
+		{"documented example", "0:100/0/0/0:0.01,0.5:2200:0.4,1:target:1", 3, false},
+		{"profile limits", "0:1800:0.1,1:6500:1", 2, false},
+		{"unsorted", "1:target:1,0:2200:0.1", 2, false},
+		{"too warm for the profile", "0:1500:0.1,1:target:1", 0, true},
+		{"too cool for the profile", "0:2200:0.1,1:8000:1", 0, true},
+		{"zero kelvin", "0:0:0.1", 0, true},
+		{"not a colour", "0:warm:0.1", 0, true},
+		{"missing brightness", "0:2200", 0, true},
+		{"brightness out of range", "0:2200:1.5", 0, true},
+		{"empty", "", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseKeyframes(tt.spec)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidKeyframes) {
+					t.Errorf("got %v, want ErrInvalidKeyframes", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != tt.want {
+				t.Errorf("got %d keyframes, want %d", len(got), tt.want)
+			}
+			for i := 1; i < len(got); i++ {
+				if got[i].At < got[i-1].At {
+					t.Errorf("keyframes not sorted: %v", got)
+				}
+			}
+		})
+	}
+}
+
+func TestParseKeyframesFollowsProfile(t *testing.T) {
+	p := DefaultColorProfile()
+	p.MinKelvin = 1500
+	useColorProfile(t, p)
+
+	if _, err := ParseKeyframes("0:1500:0.1,1:target:1"); err != nil {
+		t.Errorf("1500K rejected by a profile starting at 1500K: %v", err)
+	}
+}
+
+func TestKeyframeCurveClampsKelvin(t *testing.T) {
+	// The sunrise's 1800K keyframe is too warm for this strip
+	p := DefaultColorProfile()
+	p.MinKelvin = 2200
+	useColorProfile(t, p)
+
+	want, err := ColorTemperature(2200, 1.0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		curve KeyframeCurve
+		at    float64
+	}{
+		{"on the keyframe", SunriseCurve(), 0.45},
+		{"between keyframes", SunriseCurve(), 0.5},
+		{"first keyframe", KeyframeCurve{{At: 0, Kelvin: 1000, Brightness: 1}, {At: 1, Target: true, Brightness: 1}}, 0},
+		{"last keyframe", KeyframeCurve{{At: 0, Kelvin: 3000, Brightness: 1}, {At: 1, Kelvin: 1000, Brightness: 1}}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.curve.At(tt.at, Full)
+			if got.R == 0 && got.G == 0 && got.W == 0 && got.B == 0 {
+				t.Fatalf("went dark at %v", tt.at)
+			}
+		})
+	}
+
+	got := KeyframeCurve{{At: 0, Kelvin: 1000, Brightness: 1}}.At(0, Full)
+	if got.R != want.R || got.G != want.G || got.W != want.W || got.B != want.B {
+		t.Errorf("1000K gave %+v, want the profile's warmest %+v", got, want)
+	}
+}
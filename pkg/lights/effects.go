@@ -0,0 +1,308 @@
+package lights
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klaital/wannetiot/pkg/config"
+	log "github.com/sirupsen/logrus"
+	"periph.io/x/conn/v3/gpio"
+)
+
+// Effect is a parameterized animation played on the strip, after which the
+// lights return to the state they were in before.
+type Effect struct {
+	// Type is one of flash, pulse, breathe, candle or cycle.
+	Type string `json:"type"`
+	// Kelvin or Color sets the colour of the effect. Candle defaults to
+	// 1800K, and the others to the Soft White of the "full" scene. The
+	// colour cycle ignores both.
+	Kelvin float64         `json:"kelvin,omitempty"`
+	Color  *ChannelFactors `json:"color,omitempty"`
+	// Brightness is the peak perceived brightness, 0.0 - 1.0. Defaults to 1.0.
+	Brightness float64 `json:"brightness,omitempty"`
+	// Count is how many times the effect repeats, such as the number of flashes.
+	Count int `json:"count,omitempty"`
+	// Period is the length of one repeat, such as "500ms". For the candle it
+	// is how often the flame flickers.
+	Period string `json:"period,omitempty"`
+	// Duration loops the effect for this long, such as "10m", instead of
+	// repeating it Count times.
+	Duration string `json:"duration,omitempty"`
+	// Zones lists the zones or groups to play the effect on. Empty means all of them.
+	Zones []string `json:"zones,omitempty"`
+}
+
+var ErrInvalidEffect = errors.New("invalid effect")
+
+// effectDefaults holds the default period and repeat count of each type.
+var effectDefaults = map[string]struct {
+	period time.Duration
+	count  int
+}{
+	"flash":   {500 * time.Millisecond, 3},
+	"pulse":   {time.Second, 3},
+	"breathe": {4 * time.Second, 3},
+	"candle":  {120 * time.Millisecond, 5000}, // 10 minutes
+	"cycle":   {10 * time.Second, 1},
+}
+
+// EffectTypes lists the effects that can be played.
+func EffectTypes() []string {
+	return []string{"flash", "pulse", "breathe", "candle", "cycle"}
+}
+
+// plan resolves the defaults, returning the effect's colour at full
+// brightness, the length of one repeat, and the total length.
+func (e *Effect) plan() (color LightConfig, period, total time.Duration, err error) {
+	e.Type = strings.ToLower(e.Type)
+	defaults, ok := effectDefaults[e.Type]
+	if !ok {
+		return color, 0, 0, fmt.Errorf("%w: unknown type %q - valid options: %s", ErrInvalidEffect, e.Type, strings.Join(EffectTypes(), ", "))
+	}
+	if e.Brightness == 0 {
+		e.Brightness = 1
+	}
+	if e.Brightness < 0 || e.Brightness > 1 {
+		return color, 0, 0, fmt.Errorf("%w: brightness must be in the range 0.0 - 1.0", ErrInvalidEffect)
+	}
+	if e.Count < 0 {
+		return color, 0, 0, fmt.Errorf("%w: count must not be negative", ErrInvalidEffect)
+	}
+	if e.Count == 0 {
+		e.Count = defaults.count
+	}
+
+	period = defaults.period
+	if e.Period != "" {
+		if period, err = time.ParseDuration(e.Period); err != nil || period <= 0 {
+			return color, 0, 0, fmt.Errorf("%w: period must be a duration such as 500ms", ErrInvalidEffect)
+		}
+	}
+	total = period * time.Duration(e.Count)
+	if e.Duration != "" {
+		if total, err = time.ParseDuration(e.Duration); err != nil || total <= 0 {
+			return color, 0, 0, fmt.Errorf("%w: duration must be a duration such as 10m", ErrInvalidEffect)
+		}
+	}
+
+	switch {
+	case e.Kelvin > 0:
+		color, err = ColorTemperature(e.Kelvin, 1)
+		if err != nil {
+			return color, 0, 0, fmt.Errorf("%w: %v", ErrInvalidEffect, err)
+		}
+	case e.Color != nil:
+		duty := func(f float64) gpio.Duty {
+			return gpio.Duty(math.Round(clampProgress(f) * float64(gpio.DutyMax)))
+		}
+		color = LightConfig{R: duty(e.Color.R), G: duty(e.Color.G), W: duty(e.Color.W), B: duty(e.Color.B)}
+	case e.Type == "candle":
+		color, err = ColorTemperature(1800, 1)
+		if err != nil {
+			color = LightConfig{R: gpio.DutyMax, G: gpio.DutyMax / 5}
+		}
+	default:
+		color, _ = Scene{Color: softWhite(), Brightness: 1}.Settings()
+	}
+	return color, period, total, nil
+}
+
+// frames builds the frame function for the effect. Once it has finished,
+// the lights return to `from`.
+func (e Effect) frames(from, color LightConfig, period, total time.Duration) frameFunc {
+	model := Brightness()
+	at := func(level float64) LightConfig {
+		settings := model.Scale(color, e.Brightness*clampProgress(level))
+		settings.Name = "EFFECT"
+		return settings
+	}
+
+	// The candle picks a new flame level each period, and drifts towards it
+	var flameFrom, flameTo float64 = 1, 1
+	var flameStep int64 = -1
+
+	return func(elapsed time.Duration) (LightConfig, bool) {
+		if elapsed >= total {
+			return from, true
+		}
+		phase := float64(elapsed%period) / float64(period)
+		switch e.Type {
+		case "flash":
+			if phase < 0.5 {
+				return at(1), false
+			}
+			return at(0), false
+		case "pulse":
+			// Sharp attack, then a slow decay
+			return at(math.Exp(-4 * phase)), false
+		case "breathe":
+			return at(0.5 - 0.5*math.Cos(2*math.Pi*phase)), false
+		case "candle":
+			if step := int64(elapsed / period); step != flameStep {
+				flameStep = step
+				flameFrom = flameTo
+				flameTo = 0.55 + 0.45*rand.Float64()
+			}
+			return at(flameFrom + (flameTo-flameFrom)*phase), false
+		case "cycle":
+			// Red -> green -> blue -> red
+			segment, f := math.Modf(phase * 3)
+			var r, g, b float64
+			switch segment {
+			case 0:
+				r, g = 1-f, f
+			case 1:
+				g, b = 1-f, f
+			default:
+				b, r = 1-f, f
+			}
+			settings := model.Scale(LightConfig{
+				R: gpio.Duty(r * float64(gpio.DutyMax)),
+				G: gpio.Duty(g * float64(gpio.DutyMax)),
+				B: gpio.Duty(b * float64(gpio.DutyMax)),
+			}, e.Brightness)
+			settings.Name = "EFFECT"
+			return settings, false
+		}
+		return from, true
+	}
+}
+
+// effectStatus tracks the effect in progress, if any.
+type effectStatus struct {
+	effect Effect
+	anim   *animation
+	// from is the state each zone returns to afterwards
+	from map[*Zone]LightConfig
+}
+
+var currentEffect *effectStatus
+var effectLock sync.Mutex
+
+// PlayEffect plays the effect on the strip, interrupting any other animation,
+// then returns the lights to where they were. Any other light command stops
+// the effect without restoring the lights. The returned channel receives
+// true if the effect ran to completion.
+func PlayEffect(cfg *config.Config, e Effect) (<-chan bool, error) {
+	color, period, total, err := e.plan()
+	if err != nil {
+		return nil, err
+	}
+	zs, err := SelectZones(e.Zones...)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Logger.WithFields(log.Fields{
+		"effect":   e.Type,
+		"duration": total.String(),
+	}).Debug("Playing light effect")
+
+	status := &effectStatus{effect: e, from: make(map[*Zone]LightConfig)}
+	status.anim = animate(cfg, zs, cfg.EffectStepInterval, func(z *Zone, from LightConfig) frameFunc {
+		status.from[z] = from
+		return e.frames(from, color, period, total)
+	})
+	effectLock.Lock()
+	currentEffect = status
+	effectLock.Unlock()
+
+	done := make(chan bool, 1)
+	go func() {
+		completed := <-status.anim.done
+		effectLock.Lock()
+		if currentEffect == status {
+			currentEffect = nil
+		}
+		effectLock.Unlock()
+		done <- completed
+	}()
+	return done, nil
+}
+
+// StopEffect stops the effect in progress, if any, and fades the lights back
+// to where they were before it started.
+func StopEffect(cfg *config.Config) error {
+	effectLock.Lock()
+	status := currentEffect
+	effectLock.Unlock()
+	if status == nil {
+		return ErrNoEffect
+	}
+	status.anim.Stop()
+	fadeEach(cfg, status.anim.zones, func(z *Zone) LightConfig { return status.from[z] }, cfg.FadeDuration, EaseInOut)
+	return nil
+}
+
+var ErrNoEffect = errors.New("no effect in progress")
+
+// EffectPlaying reports the type of the effect in progress, if any.
+func EffectPlaying() (playing bool, effect string) {
+	effectLock.Lock()
+	defer effectLock.Unlock()
+	if currentEffect == nil {
+		return false, ""
+	}
+	return true, currentEffect.effect.Type
+}
+
+// ParseEffect reads an effect from a compact spec, for use in config and
+// alarms: the type followed by comma-separated options, such as
+// "flash,color=100/0/0/0,count=5" or "candle,duration=30m". The colour is
+// either a colour temperature, or R/G/W/B percentages.
+func ParseEffect(spec string) (Effect, error) {
+	fields := strings.Split(spec, ",")
+	e := Effect{Type: strings.TrimSpace(fields[0])}
+	for _, field := range fields[1:] {
+		kv := strings.SplitN(strings.TrimSpace(field), "=", 2)
+		if len(kv) != 2 {
+			return e, fmt.Errorf("%w: %q should be key=value", ErrInvalidEffect, field)
+		}
+		var err error
+		switch key, value := strings.ToLower(kv[0]), kv[1]; key {
+		case "color", "colour":
+			if strings.Contains(value, "/") {
+				pct := strings.Split(value, "/")
+				if len(pct) != 4 {
+					return e, fmt.Errorf("%w: colour should be R/G/W/B", ErrInvalidEffect)
+				}
+				channels := make([]float64, 4)
+				for i := range pct {
+					if channels[i], err = strconv.ParseFloat(pct[i], 64); err != nil {
+						return e, fmt.Errorf("%w: invalid colour %q", ErrInvalidEffect, value)
+					}
+					channels[i] /= 100
+				}
+				e.Color = &ChannelFactors{R: channels[0], G: channels[1], W: channels[2], B: channels[3]}
+			} else if e.Kelvin, err = strconv.ParseFloat(value, 64); err != nil {
+				return e, fmt.Errorf("%w: invalid colour temperature %q", ErrInvalidEffect, value)
+			}
+		case "brightness":
+			if e.Brightness, err = strconv.ParseFloat(value, 64); err != nil {
+				return e, fmt.Errorf("%w: invalid brightness %q", ErrInvalidEffect, value)
+			}
+		case "count":
+			if e.Count, err = strconv.Atoi(value); err != nil {
+				return e, fmt.Errorf("%w: invalid count %q", ErrInvalidEffect, value)
+			}
+		case "period":
+			e.Period = value
+		case "duration":
+			e.Duration = value
+		default:
+			return e, fmt.Errorf("%w: unknown option %q", ErrInvalidEffect, key)
+		}
+	}
+	// Check the options now, rather than when the effect is played
+	check := e
+	if _, _, _, err := check.plan(); err != nil {
+		return e, err
+	}
+	return e, nil
+}
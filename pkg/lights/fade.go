@@ -0,0 +1,264 @@
+package lights
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klaital/wannetiot/pkg/config"
+	log "github.com/sirupsen/logrus"
+	"periph.io/x/conn/v3/gpio"
+)
+
+// Easing maps the linear progress of a fade (0.0 - 1.0) onto the fraction of
+// the way between the start and target settings.
+type Easing func(progress float64) float64
+
+// EaseLinear moves at a constant rate.
+func EaseLinear(p float64) float64 {
+	return p
+}
+
+// EaseInOut starts and ends gently, moving fastest through the middle.
+func EaseInOut(p float64) float64 {
+	return 0.5 - 0.5*math.Cos(math.Pi*p)
+}
+
+// EaseExponential starts very slowly and accelerates towards the end.
+func EaseExponential(p float64) float64 {
+	if p <= 0 {
+		return 0
+	}
+	return (math.Pow(2, 10*p) - 1) / 1023
+}
+
+var ErrUnknownEasing = errors.New("unknown easing curve - valid options: linear, ease-in-out, exponential")
+
+// ParseEasing looks up an Easing curve by its configuration name.
+func ParseEasing(name string) (Easing, error) {
+	switch strings.ToLower(name) {
+	case "linear":
+		return EaseLinear, nil
+	case "ease-in-out", "easeinout", "":
+		return EaseInOut, nil
+	case "exponential", "exp":
+		return EaseExponential, nil
+	}
+	return nil, ErrUnknownEasing
+}
+
+// Blend interpolates each channel from `from` towards `to`. A progress of 0.0
+// returns `from`, 1.0 returns `to`. The Name is taken from `to`.
+func Blend(from, to LightConfig, progress float64) LightConfig {
+	if progress <= 0 {
+		progress = 0
+	}
+	if progress >= 1 {
+		progress = 1
+	}
+	lerp := func(a, b gpio.Duty) gpio.Duty {
+		return gpio.Duty(math.Round(float64(a) + (float64(b)-float64(a))*progress))
+	}
+	kelvin := to.Kelvin
+	if from.Kelvin > 0 && to.Kelvin > 0 {
+		kelvin = from.Kelvin + (to.Kelvin-from.Kelvin)*progress
+	}
+	return LightConfig{
+		Name:   to.Name,
+		R:      lerp(from.R, to.R),
+		G:      lerp(from.G, to.G),
+		W:      lerp(from.W, to.W),
+		B:      lerp(from.B, to.B),
+		Kelvin: kelvin,
+	}
+}
+
+// frameFunc computes the settings to drive at the given time since an
+// animation started, and whether the animation has finished.
+type frameFunc func(elapsed time.Duration) (settings LightConfig, done bool)
+
+// frameBuilder creates the frames for one zone of an animation, given the
+// zone's output at the moment the animation took it over.
+type frameBuilder func(z *Zone, from LightConfig) frameFunc
+
+// animationLock guards each zone's output, and which animation owns it.
+var animationLock sync.Mutex
+
+// animation is a handle on a running animation. Only one animation may own
+// each zone at a time.
+type animation struct {
+	zones   []*Zone
+	stop    chan struct{}
+	stopped bool
+	// done receives true if the animation ran to completion, or false if it
+	// was interrupted by another light command.
+	done chan bool
+}
+
+// release signals the animation to stop, and gives up the zones it still
+// owns. The animation lock must be held.
+func (a *animation) release() {
+	if a.stopped {
+		return
+	}
+	a.stopped = true
+	close(a.stop)
+	for _, z := range a.zones {
+		if z.anim == a {
+			z.anim = nil
+		}
+	}
+}
+
+// Stop interrupts the animation. It will not affect any animation that has
+// since replaced it on some of its zones.
+func (a *animation) Stop() {
+	animationLock.Lock()
+	defer animationLock.Unlock()
+	a.release()
+}
+
+// animate takes ownership of the zones, stopping any animation already
+// running on them, and runs frames at the given interval until the animation
+// finishes. Nil zones means all of them. Each zone's builder is given its
+// output at the moment the previous animation was stopped, so that the new
+// animation can continue smoothly from there.
+// An animation is stopped as a whole as soon as any of its zones is taken
+// over by another light command.
+func animate(cfg *config.Config, zs []*Zone, interval time.Duration, build frameBuilder) *animation {
+	if zs == nil {
+		zs = AllZones()
+	}
+	a := &animation{
+		zones: zs,
+		stop:  make(chan struct{}),
+		done:  make(chan bool, 1),
+	}
+
+	animationLock.Lock()
+	frames := make([]frameFunc, len(zs))
+	for i, z := range zs {
+		if z.anim != nil {
+			z.anim.release()
+		}
+		z.anim = a
+		frames[i] = build(z, z.output)
+	}
+	animationLock.Unlock()
+
+	if interval <= 0 {
+		interval = 10 * time.Millisecond
+	}
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		start := time.Now()
+		settings := make([]LightConfig, len(zs))
+		for {
+			elapsed := time.Since(start)
+			finished := true
+			for i, frame := range frames {
+				var done bool
+				settings[i], done = frame(elapsed)
+				finished = finished && done
+			}
+
+			// Frames are only written while this animation still owns the zones
+			animationLock.Lock()
+			if a.stopped {
+				animationLock.Unlock()
+				a.done <- false
+				return
+			}
+			for i, z := range zs {
+				z.write(settings[i])
+			}
+			if finished {
+				a.release()
+				animationLock.Unlock()
+				a.done <- true
+				return
+			}
+			animationLock.Unlock()
+
+			select {
+			case <-a.stop:
+				a.done <- false
+				return
+			case <-t.C:
+			}
+		}
+	}()
+	return a
+}
+
+// stopAnimations interrupts any animation running on the zones.
+// The animation lock must be held.
+func stopAnimations(zs []*Zone) {
+	for _, z := range zs {
+		if z.anim != nil {
+			z.anim.release()
+		}
+	}
+}
+
+// fadeEach smoothly transitions each zone from its current output to its own
+// target over the given duration. Any fade already in progress is
+// re-targeted from wherever it has reached. The returned channel receives
+// true if the fade completed, or false if it was interrupted.
+func fadeEach(cfg *config.Config, zs []*Zone, target func(z *Zone) LightConfig, d time.Duration, ease Easing) <-chan bool {
+	if ease == nil {
+		ease = EaseLinear
+	}
+	a := animate(cfg, zs, cfg.FadeInterval, func(z *Zone, from LightConfig) frameFunc {
+		to := target(z)
+		return func(elapsed time.Duration) (LightConfig, bool) {
+			if elapsed >= d {
+				return to, true
+			}
+			return Blend(from, to, ease(float64(elapsed)/float64(d))), false
+		}
+	})
+	return a.done
+}
+
+// FadeZonesTo smoothly transitions all four channels of the given zones to
+// the target over the given duration. Nil zones means all of them.
+func FadeZonesTo(cfg *config.Config, zs []*Zone, target LightConfig, d time.Duration, ease Easing) <-chan bool {
+	return fadeEach(cfg, zs, func(*Zone) LightConfig { return target }, d, ease)
+}
+
+// FadeTo smoothly transitions every zone to the target over the given duration.
+func FadeTo(cfg *config.Config, target LightConfig, d time.Duration, ease Easing) <-chan bool {
+	return FadeZonesTo(cfg, nil, target, d, ease)
+}
+
+// FadeLights transitions to the given settings using the configured fade
+// duration and easing curve.
+func FadeLights(cfg *config.Config, settings *LightConfig) <-chan bool {
+	return FadeLightsOver(cfg, settings, cfg.FadeDuration)
+}
+
+// FadeLightsOver transitions every zone to the given settings over the given
+// duration, using the configured easing curve.
+func FadeLightsOver(cfg *config.Config, settings *LightConfig, d time.Duration) <-chan bool {
+	return FadeZoneLights(cfg, nil, *settings, d)
+}
+
+// FadeZoneLights transitions the given zones to the settings over the given
+// duration, using the configured easing curve. Nil zones means all of them.
+func FadeZoneLights(cfg *config.Config, zs []*Zone, settings LightConfig, d time.Duration) <-chan bool {
+	ease, err := ParseEasing(cfg.FadeEasing)
+	if err != nil {
+		cfg.Logger.WithError(err).WithField("easing", cfg.FadeEasing).Error("Invalid fade easing, falling back to linear")
+		ease = EaseLinear
+	}
+	cfg.Logger.WithFields(log.Fields{
+		"lights":   settings,
+		"zones":    zoneNames(zs),
+		"duration": d.String(),
+	}).Debug("Fading lights")
+	return FadeZonesTo(cfg, zs, settings, d, ease)
+}
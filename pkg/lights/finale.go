@@ -0,0 +1,88 @@
+package lights
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/klaital/wannetiot/pkg/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// FinaleSpeaker plays one round of the audible alarm at the end of the
+// wakeup. The level starts at 1 and rises with each round, so the speaker
+// can escalate the pattern.
+type FinaleSpeaker func(level int)
+
+var finaleSpeakers []FinaleSpeaker
+var finaleLock sync.Mutex
+
+// RegisterFinaleSpeaker adds a speaker to play the end-of-wakeup alarm on,
+// such as a control panel.
+func RegisterFinaleSpeaker(s FinaleSpeaker) {
+	finaleLock.Lock()
+	defer finaleLock.Unlock()
+	finaleSpeakers = append(finaleSpeakers, s)
+}
+
+// runFinale sounds the alarm on every registered speaker, rising in
+// intensity each round, until the wakeup is dismissed (the context is
+// cancelled), snoozed, or the maximum duration passes. If an effect is
+// given, it is played on the lights as the alarm starts.
+// Returns the snooze duration and true if it was snoozed.
+func runFinale(ctx context.Context, cfg *config.Config, maxDuration time.Duration, effect *Effect, logger *log.Entry) (time.Duration, bool) {
+	finaleLock.Lock()
+	speakers := append([]FinaleSpeaker(nil), finaleSpeakers...)
+	finaleLock.Unlock()
+	if effect != nil {
+		if _, err := PlayEffect(cfg, *effect); err != nil {
+			logger.WithError(err).Error("Failed to play wakeup alarm effect")
+		}
+	}
+	if len(speakers) == 0 {
+		logger.Warn("No speakers registered for the wakeup alarm")
+		return 0, false
+	}
+
+	logger.WithField("max", maxDuration.String()).Info("Sounding wakeup alarm")
+	giveUp := time.NewTimer(maxDuration)
+	defer giveUp.Stop()
+	round := time.NewTicker(cfg.WakeupFinaleInterval)
+	defer round.Stop()
+
+	for level := 1; ; level++ {
+		setFinaleLevel(level)
+		for _, s := range speakers {
+			s(level)
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.Info("Wakeup alarm dismissed")
+			return 0, false
+		case d := <-snoozeWakeup:
+			logger.Info("Wakeup alarm snoozed")
+			return d, true
+		case <-giveUp.C:
+			logger.Info("Wakeup alarm gave up without being dismissed")
+			return 0, false
+		case <-round.C:
+		}
+	}
+}
+
+// setFinaleLevel records the alarm's current round for status reporting.
+func setFinaleLevel(level int) {
+	wakeupLock.Lock()
+	defer wakeupLock.Unlock()
+	if currentWakeup != nil {
+		currentWakeup.finaleLevel = level
+	}
+}
+
+// WakeupAlarmSounding reports whether the end-of-wakeup alarm is playing.
+func WakeupAlarmSounding() bool {
+	wakeupLock.Lock()
+	defer wakeupLock.Unlock()
+	return currentWakeup != nil && currentWakeup.finaleLevel > 0
+}
@@ -15,14 +15,41 @@ type LightConfig struct {
 	G    gpio.Duty
 	W    gpio.Duty
 	B    gpio.Duty
+	// Kelvin records the colour temperature these settings were solved for, or 0 for a custom mix.
+	Kelvin float64
 }
 
+// DriveLights jumps every zone straight to the given settings, interrupting any fade in progress.
 func DriveLights(cfg *config.Config, settings *LightConfig) {
-	cfg.Logger.WithField("lights", settings).Debug("Driving lights")
-	util.DrivePWM(cfg.LedControlRedPin, settings.R, 5*physic.KiloHertz, nil)
-	util.DrivePWM(cfg.LedControlGreenPin, settings.G, 5*physic.KiloHertz, nil)
-	util.DrivePWM(cfg.LedControlWhitePin, settings.W, 5*physic.KiloHertz, nil)
-	util.DrivePWM(cfg.LedControlBluePin, settings.B, 5*physic.KiloHertz, nil)
+	DriveZones(cfg, nil, *settings)
+}
+
+// DriveZones jumps the given zones straight to the settings, interrupting
+// any fade in progress on them. Nil zones means all of them.
+func DriveZones(cfg *config.Config, zs []*Zone, settings LightConfig) {
+	cfg.Logger.WithFields(log.Fields{
+		"lights": settings,
+		"zones":  zoneNames(zs),
+	}).Debug("Driving lights")
+	if zs == nil {
+		zs = AllZones()
+	}
+	animationLock.Lock()
+	defer animationLock.Unlock()
+	stopAnimations(zs)
+	for _, z := range zs {
+		z.write(settings)
+	}
+}
+
+// CurrentOutput reports the settings currently being driven on the first zone.
+func CurrentOutput() LightConfig {
+	animationLock.Lock()
+	defer animationLock.Unlock()
+	if len(zones) == 0 {
+		return LightConfig{}
+	}
+	return zones[0].output
 }
 
 // AddSettings mutates the values in `a` by adding the values contained in `b`.
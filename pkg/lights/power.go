@@ -0,0 +1,160 @@
+package lights
+
+import (
+	"time"
+
+	"periph.io/x/conn/v3/gpio"
+)
+
+// zoneEnergy integrates a zone's channel duty cycles over time, to estimate
+// how much power the strip draws. The totals reset at local midnight.
+type zoneEnergy struct {
+	// watts is each channel's power draw at 100% duty
+	watts map[string]float64
+	// duties are the calibrated duty cycles most recently driven on each channel
+	duties map[string]gpio.Duty
+	// since is when the totals were last brought up to date
+	since time.Time
+	// day is the local midnight that started the current totals
+	day time.Time
+
+	joules   float64
+	onTime   time.Duration
+	dutyTime map[string]time.Duration
+}
+
+func newZoneEnergy(watts map[string]float64) *zoneEnergy {
+	now := time.Now()
+	return &zoneEnergy{
+		watts:    watts,
+		duties:   make(map[string]gpio.Duty),
+		since:    now,
+		day:      midnight(now),
+		dutyTime: make(map[string]time.Duration),
+	}
+}
+
+// midnight finds the local midnight that started the day.
+func midnight(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// power estimates the instantaneous draw of the channels, in watts.
+func (e *zoneEnergy) power() float64 {
+	var w float64
+	for channel, duty := range e.duties {
+		w += e.watts[channel] * float64(duty) / float64(gpio.DutyMax)
+	}
+	return w
+}
+
+// account brings the totals up to now, starting new ones at midnight.
+func (e *zoneEnergy) account(now time.Time) {
+	for {
+		end := now
+		next := e.day.AddDate(0, 0, 1)
+		if !now.Before(next) {
+			end = next
+		}
+		if dt := end.Sub(e.since); dt > 0 {
+			e.joules += e.power() * dt.Seconds()
+			lit := false
+			for channel, duty := range e.duties {
+				if duty > 0 {
+					lit = true
+					e.dutyTime[channel] += time.Duration(float64(dt) * float64(duty) / float64(gpio.DutyMax))
+				}
+			}
+			if lit {
+				e.onTime += dt
+			}
+			e.since = end
+		}
+		if end == now {
+			return
+		}
+		// Start the next day's totals
+		e.day = next
+		e.joules = 0
+		e.onTime = 0
+		e.dutyTime = make(map[string]time.Duration)
+	}
+}
+
+// drive records the duty cycles being driven from now on.
+func (e *zoneEnergy) drive(duties map[string]gpio.Duty) {
+	e.account(time.Now())
+	e.duties = duties
+}
+
+// ZonePower reports a zone's estimated power draw and usage today.
+type ZonePower struct {
+	Zone string `json:"zone"`
+	// Watts is the estimated draw right now
+	Watts float64 `json:"watts"`
+	// KWhToday is the estimated energy used since midnight
+	KWhToday float64 `json:"kwh_today"`
+	// OnHoursToday is how long any channel has been lit since midnight
+	OnHoursToday float64 `json:"on_hours_today"`
+	// DutyHoursToday is each channel's duty cycle integrated over time since
+	// midnight, as the equivalent hours at 100%
+	DutyHoursToday map[string]float64 `json:"duty_hours_today"`
+}
+
+// PowerReport is the estimated power draw and usage of every zone.
+type PowerReport struct {
+	Watts        float64     `json:"watts"`
+	KWhToday     float64     `json:"kwh_today"`
+	OnHoursToday float64     `json:"on_hours_today"`
+	Zones        []ZonePower `json:"zones"`
+}
+
+// nodeEnergy tracks when any zone at all is lit, with a single channel
+// that is fully on while one is. The animation lock must be held.
+var nodeEnergy = newZoneEnergy(nil)
+
+// driveNode records whether any zone is lit. The animation lock must be held.
+func driveNode() {
+	lit := gpio.Duty(0)
+	for _, z := range zones {
+		for _, duty := range z.energy.duties {
+			if duty > 0 {
+				lit = gpio.DutyMax
+			}
+		}
+	}
+	nodeEnergy.drive(map[string]gpio.Duty{"any": lit})
+}
+
+// PowerUsage estimates the power the strips are drawing, and have used
+// today, from the configured watts at 100% duty of each channel.
+func PowerUsage() PowerReport {
+	animationLock.Lock()
+	defer animationLock.Unlock()
+
+	now := time.Now()
+	nodeEnergy.account(now)
+	report := PowerReport{
+		OnHoursToday: nodeEnergy.onTime.Hours(),
+		Zones:        make([]ZonePower, 0, len(zones)),
+	}
+	for _, z := range zones {
+		e := z.energy
+		e.account(now)
+		zp := ZonePower{
+			Zone:           z.Name,
+			Watts:          e.power(),
+			KWhToday:       e.joules / 3.6e6,
+			OnHoursToday:   e.onTime.Hours(),
+			DutyHoursToday: make(map[string]float64, len(e.dutyTime)),
+		}
+		for channel, d := range e.dutyTime {
+			zp.DutyHoursToday[channel] = d.Hours()
+		}
+		report.Watts += zp.Watts
+		report.KWhToday += zp.KWhToday
+		report.Zones = append(report.Zones, zp)
+	}
+	return report
+}
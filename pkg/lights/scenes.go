@@ -0,0 +1,435 @@
+package lights
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klaital/wannetiot/pkg/config"
+	"github.com/klaital/wannetiot/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"periph.io/x/conn/v3/gpio"
+)
+
+// Scene is a named light setting that can be recalled by its ID. The colour
+// is given either as a colour temperature, or as the mix of each channel.
+type Scene struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Kelvin is the colour temperature, such as 2700. Zero uses Color instead.
+	Kelvin float64 `json:"kelvin,omitempty"`
+	// Color is the mix of each channel at full brightness, each in the range 0.0 - 1.0.
+	Color *ChannelFactors `json:"color,omitempty"`
+	// Brightness is the perceived brightness, 0.0 - 1.0. Zero turns the lights off.
+	Brightness float64 `json:"brightness"`
+	// Transition is how long to fade into the scene, such as "2s". Empty uses the configured default.
+	Transition string `json:"transition,omitempty"`
+	// Zones overrides the colour and brightness for some zones, keyed by zone
+	// or group name. A zone's own name takes priority over its groups.
+	Zones map[string]SceneZone `json:"zones,omitempty"`
+}
+
+// SceneZone is the colour and brightness of a scene in one zone or group.
+type SceneZone struct {
+	Kelvin     float64         `json:"kelvin,omitempty"`
+	Color      *ChannelFactors `json:"color,omitempty"`
+	Brightness float64         `json:"brightness"`
+}
+
+var ErrSceneNotFound = errors.New("scene not found")
+var ErrDuplicateScene = errors.New("a scene with that ID already exists")
+var ErrInvalidScene = errors.New("invalid scene")
+
+var sceneID = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+var nonSceneID = regexp.MustCompile(`[^a-z0-9]+`)
+
+// softWhite is the colour mix used by the "full" and "low" scenes.
+func softWhite() *ChannelFactors {
+	return &ChannelFactors{R: 0.6, G: 0.1, W: 1}
+}
+
+// BuiltinScenes lists the scenes that are always available. Each can be
+// edited, and reverts to its default when deleted.
+var BuiltinScenes = []string{"off", "low", "full", "reading", "night-light", "custom"}
+
+// builtinScene generates the default for a built-in scene.
+func builtinScene(id string) (Scene, bool) {
+	switch id {
+	case "off":
+		return Scene{ID: id, Name: "Off"}, true
+	case "low":
+		// Follows the configured dimmer level
+		return Scene{ID: id, Name: "Low", Color: softWhite(), Brightness: DimLevel()}, true
+	case "full":
+		return Scene{ID: id, Name: "Full", Color: softWhite(), Brightness: 1}, true
+	case "reading":
+		return Scene{ID: id, Name: "Reading", Kelvin: 4000, Brightness: 0.8}, true
+	case "night-light":
+		return Scene{ID: id, Name: "Night Light", Kelvin: 1800, Brightness: 0.08}, true
+	case "custom":
+		return Scene{ID: id, Name: "Custom", Color: softWhite(), Brightness: 1}, true
+	}
+	return Scene{}, false
+}
+
+// Validate checks the scene's fields, and that it can be produced by the strip.
+func (s *Scene) Validate() error {
+	s.ID = strings.ToLower(s.ID)
+	if !sceneID.MatchString(s.ID) {
+		return fmt.Errorf("%w: id must be lower case letters, digits and dashes", ErrInvalidScene)
+	}
+	if strings.TrimSpace(s.Name) == "" {
+		s.Name = s.ID
+	}
+	if s.Brightness < 0 || s.Brightness > 1 {
+		return fmt.Errorf("%w: brightness must be in the range 0.0 - 1.0", ErrInvalidScene)
+	}
+	if s.Kelvin > 0 && s.Color != nil {
+		return fmt.Errorf("%w: give either kelvin or color, not both", ErrInvalidScene)
+	}
+	if s.Brightness > 0 && s.Kelvin <= 0 && s.Color == nil {
+		return fmt.Errorf("%w: kelvin or color is required", ErrInvalidScene)
+	}
+	if s.Color != nil {
+		for _, f := range []float64{s.Color.R, s.Color.G, s.Color.W, s.Color.B} {
+			if f < 0 || f > 1 {
+				return fmt.Errorf("%w: each color channel must be in the range 0.0 - 1.0", ErrInvalidScene)
+			}
+		}
+	}
+	if s.Transition != "" {
+		if d, err := time.ParseDuration(s.Transition); err != nil || d < 0 {
+			return fmt.Errorf("%w: transition must be a duration such as 2s", ErrInvalidScene)
+		}
+	}
+	if _, err := s.Settings(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidScene, err)
+	}
+	for name, zone := range s.Zones {
+		if _, err := SelectZones(name); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidScene, err)
+		}
+		override := s.override(zone)
+		if err := override.Validate(); err != nil {
+			return fmt.Errorf("%w: zone %q", err, name)
+		}
+	}
+	return nil
+}
+
+// override generates the scene as it appears in a zone with its own settings.
+func (s Scene) override(zone SceneZone) Scene {
+	return Scene{
+		ID:         s.ID,
+		Name:       s.Name,
+		Kelvin:     zone.Kelvin,
+		Color:      zone.Color,
+		Brightness: zone.Brightness,
+	}
+}
+
+// SettingsFor generates the duty cycles for the scene in the given zone,
+// applying the zone's override if it has one.
+func (s Scene) SettingsFor(z *Zone) (LightConfig, error) {
+	if zone, ok := s.Zones[z.Name]; ok {
+		return s.override(zone).Settings()
+	}
+	for _, g := range z.Groups {
+		if zone, ok := s.Zones[g]; ok {
+			return s.override(zone).Settings()
+		}
+	}
+	return s.Settings()
+}
+
+// Settings generates the duty cycles for the scene. The Name of the settings
+// is the scene ID, so the scene can be recognized again.
+func (s Scene) Settings() (LightConfig, error) {
+	var settings LightConfig
+	var err error
+	switch {
+	case s.Brightness <= 0:
+		// Off
+	case s.Kelvin > 0:
+		settings, err = ColorTemperature(s.Kelvin, s.Brightness)
+	case s.Color != nil:
+		duty := func(f float64) gpio.Duty {
+			return gpio.Duty(math.Round(f * float64(gpio.DutyMax)))
+		}
+		settings = Brightness().Scale(LightConfig{
+			R: duty(s.Color.R),
+			G: duty(s.Color.G),
+			W: duty(s.Color.W),
+			B: duty(s.Color.B),
+		}, s.Brightness)
+	}
+	settings.Name = s.ID
+	return settings, err
+}
+
+// FadeScene transitions the zones to the scene over the given duration,
+// with each zone fading to its own override, if any. Nil zs fades every zone.
+func FadeScene(cfg *config.Config, s Scene, zs []*Zone, d time.Duration) (<-chan bool, error) {
+	s = s.autoScaled()
+	if zs == nil {
+		zs = AllZones()
+	}
+	targets := make(map[*Zone]LightConfig, len(zs))
+	for _, z := range zs {
+		settings, err := s.SettingsFor(z)
+		if err != nil {
+			return nil, err
+		}
+		targets[z] = settings
+	}
+	ease, err := ParseEasing(cfg.FadeEasing)
+	if err != nil {
+		cfg.Logger.WithError(err).WithField("easing", cfg.FadeEasing).Error("Invalid fade easing, falling back to linear")
+		ease = EaseLinear
+	}
+	cfg.Logger.WithFields(log.Fields{
+		"scene":    s.ID,
+		"zones":    zoneNames(zs),
+		"duration": d.String(),
+	}).Debug("Fading to scene")
+	return fadeEach(cfg, zs, func(z *Zone) LightConfig { return targets[z] }, d, ease), nil
+}
+
+// TransitionDuration parses Transition, returning def if it isn't set.
+func (s Scene) TransitionDuration(def time.Duration) time.Duration {
+	if d, err := time.ParseDuration(s.Transition); err == nil {
+		return d
+	}
+	return def
+}
+
+// savedState is the layout of the state file.
+type savedState struct {
+	Scenes map[string]Scene `json:"scenes"`
+	// Last is the last light setting requested, to be restored on boot
+	Last *LightConfig `json:"last,omitempty"`
+	// DimLevel is the perceived brightness of the "low" scene
+	DimLevel *float64 `json:"dim_level,omitempty"`
+	// Presets are the light presets saved before scenes replaced them. They
+	// are only read, to be turned into scenes.
+	Presets map[string]LightConfig `json:"presets,omitempty"`
+}
+
+// SceneStore keeps the light scenes and the last light state, and persists
+// them to a local JSON file so that they survive a restart.
+type SceneStore struct {
+	path  string
+	lock  sync.Mutex
+	state savedState
+}
+
+// OpenSceneStore loads the state saved at path, if any. An empty path keeps
+// the state in memory only.
+func OpenSceneStore(path string) (*SceneStore, error) {
+	s := &SceneStore{path: path}
+	if path != "" {
+		if err := util.ReadJSON(path, &s.state); err != nil {
+			return nil, err
+		}
+	}
+	if s.state.Scenes == nil {
+		s.state.Scenes = make(map[string]Scene)
+	}
+	if len(s.state.Presets) > 0 {
+		s.migratePresets()
+		if err := s.save(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// migratePresets turns the saved presets into scenes with the same
+// channel mix. A scene that already has the preset's ID is kept.
+func (s *SceneStore) migratePresets() {
+	for id, preset := range s.state.Presets {
+		logger := log.WithField("preset", id)
+		if _, exists := s.state.Scenes[id]; exists {
+			logger.Warn("A scene already has the preset's ID, dropping the preset")
+			continue
+		}
+		scene := sceneFromPreset(id, preset)
+		if err := scene.Validate(); err != nil {
+			logger.WithError(err).Error("Unable to turn the preset into a scene, dropping it")
+			continue
+		}
+		s.state.Scenes[scene.ID] = scene
+		logger.Info("Turned the saved preset into a scene")
+	}
+	s.state.Presets = nil
+}
+
+// sceneFromPreset generates a scene with a preset's duty cycles.
+func sceneFromPreset(id string, preset LightConfig) Scene {
+	scene := Scene{ID: id, Name: id}
+	if preset.R == 0 && preset.G == 0 && preset.W == 0 && preset.B == 0 {
+		return scene
+	}
+	factor := func(d gpio.Duty) float64 {
+		return float64(d) / float64(gpio.DutyMax)
+	}
+	scene.Color = &ChannelFactors{R: factor(preset.R), G: factor(preset.G), W: factor(preset.W), B: factor(preset.B)}
+	scene.Brightness = 1
+	return scene
+}
+
+// save writes the state to disk. The lock must be held.
+func (s *SceneStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+	return util.WriteJSONAtomic(s.path, s.state)
+}
+
+// Scenes lists every scene, built-in and saved, ordered by ID.
+func (s *SceneStore) Scenes() []Scene {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	list := make([]Scene, 0, len(BuiltinScenes)+len(s.state.Scenes))
+	for _, id := range BuiltinScenes {
+		if _, saved := s.state.Scenes[id]; !saved {
+			scene, _ := builtinScene(id)
+			list = append(list, scene)
+		}
+	}
+	for _, scene := range s.state.Scenes {
+		list = append(list, scene)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// Scene looks up a scene by ID, falling back to the built-in defaults.
+func (s *SceneStore) Scene(id string) (Scene, error) {
+	id = strings.ToLower(id)
+	s.lock.Lock()
+	scene, ok := s.state.Scenes[id]
+	s.lock.Unlock()
+	if ok {
+		return scene, nil
+	}
+	if scene, ok = builtinScene(id); ok {
+		return scene, nil
+	}
+	return Scene{}, ErrSceneNotFound
+}
+
+// CreateScene adds a new scene. If no ID is given, one is generated from the name.
+func (s *SceneStore) CreateScene(scene Scene) (Scene, error) {
+	if scene.ID == "" {
+		scene.ID = strings.Trim(nonSceneID.ReplaceAllString(strings.ToLower(scene.Name), "-"), "-")
+	}
+	if err := scene.Validate(); err != nil {
+		return Scene{}, err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	_, saved := s.state.Scenes[scene.ID]
+	if _, builtin := builtinScene(scene.ID); saved || builtin {
+		return Scene{}, ErrDuplicateScene
+	}
+	s.state.Scenes[scene.ID] = scene
+	return scene, s.save()
+}
+
+// UpdateScene replaces the scene with the given ID. Built-in scenes can be
+// updated too. The ID cannot be changed.
+func (s *SceneStore) UpdateScene(id string, scene Scene) (Scene, error) {
+	scene.ID = id
+	if err := scene.Validate(); err != nil {
+		return Scene{}, err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	_, saved := s.state.Scenes[scene.ID]
+	if _, builtin := builtinScene(scene.ID); !saved && !builtin {
+		return Scene{}, ErrSceneNotFound
+	}
+	s.state.Scenes[scene.ID] = scene
+	return scene, s.save()
+}
+
+// DeleteScene removes a scene. A built-in scene reverts to its default.
+func (s *SceneStore) DeleteScene(id string) error {
+	id = strings.ToLower(id)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.state.Scenes[id]; !ok {
+		return ErrSceneNotFound
+	}
+	delete(s.state.Scenes, id)
+	return s.save()
+}
+
+// SceneSettings looks up a scene and generates its duty cycles, adapted to
+// the ambient light by auto-brightness.
+func (s *SceneStore) SceneSettings(id string) (LightConfig, error) {
+	scene, err := s.Scene(id)
+	if err != nil {
+		return LightConfig{}, err
+	}
+	return scene.autoScaled().Settings()
+}
+
+// LastState returns the last light setting saved with SetLastState, if any.
+func (s *SceneStore) LastState() (LightConfig, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.state.Last == nil {
+		return LightConfig{}, false
+	}
+	return *s.state.Last, true
+}
+
+// SetLastState records the light setting to restore on boot. It is only
+// written to disk when it has changed.
+func (s *SceneStore) SetLastState(settings LightConfig) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.state.Last != nil && *s.state.Last == settings {
+		return nil
+	}
+	s.state.Last = &settings
+	return s.save()
+}
+
+// DimLevel returns the saved perceived brightness of the "low" scene, if any.
+func (s *SceneStore) DimLevel() (float64, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.state.DimLevel == nil {
+		return 0, false
+	}
+	return *s.state.DimLevel, true
+}
+
+// SetDimLevel saves the perceived brightness of the "low" scene.
+func (s *SceneStore) SetDimLevel(level float64) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.state.DimLevel = &level
+	return s.save()
+}
+
+// BootState picks the light setting to start up in. "last" restores the
+// last saved state, falling back to off if there isn't one. Anything else is
+// looked up as a scene ID.
+func (s *SceneStore) BootState(boot string) (LightConfig, error) {
+	if strings.ToLower(boot) == "last" {
+		if last, ok := s.LastState(); ok {
+			return last, nil
+		}
+		return Off, nil
+	}
+	return s.SceneSettings(boot)
+}
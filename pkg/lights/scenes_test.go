@@ -0,0 +1,90 @@
+package lights
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"periph.io/x/conn/v3/gpio"
+)
+
+func TestOpenSceneStoreMigratesPresets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lights.json")
+	saved := `{
+		"presets": {
+			"movie": {"Name": "movie", "R": 4096, "G": 0, "W": 2048, "B": 0},
+			"dark": {"Name": "dark"},
+			"kept": {"Name": "kept", "R": 100},
+			"Not A Scene": {"R": 100}
+		},
+		"scenes": {
+			"kept": {"id": "kept", "name": "Kept", "kelvin": 2700, "brightness": 0.5}
+		},
+		"dim_level": 0.3
+	}`
+	if err := os.WriteFile(path, []byte(saved), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := OpenSceneStore(path)
+	if err != nil {
+		t.Fatalf("opening the store: %v", err)
+	}
+
+	tests := []struct {
+		id   string
+		want LightConfig
+	}{
+		{"movie", LightConfig{Name: "movie", R: 4096, W: 2048}},
+		{"dark", LightConfig{Name: "dark"}},
+	}
+	for _, tt := range tests {
+		scene, err := store.Scene(tt.id)
+		if err != nil {
+			t.Errorf("scene %q wasn't migrated: %v", tt.id, err)
+			continue
+		}
+		got, err := scene.Settings()
+		if err != nil {
+			t.Errorf("scene %q: %v", tt.id, err)
+		}
+		if got != tt.want {
+			t.Errorf("scene %q has settings %+v, want %+v", tt.id, got, tt.want)
+		}
+	}
+	if scene, _ := store.Scene("kept"); scene.Kelvin != 2700 {
+		t.Errorf("the existing scene was replaced by the preset: %+v", scene)
+	}
+	if _, err := store.Scene("not-a-scene"); err != ErrSceneNotFound {
+		t.Errorf("a preset with an invalid ID was migrated")
+	}
+
+	// The presets are dropped from the file once they are scenes
+	var state map[string]json.RawMessage
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, &state); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := state["presets"]; ok {
+		t.Errorf("the presets are still saved")
+	}
+	if _, ok := state["dim_level"]; !ok {
+		t.Errorf("the rest of the state was lost")
+	}
+}
+
+func TestSceneFromPresetKeepsFullDuty(t *testing.T) {
+	preset := LightConfig{R: gpio.DutyMax, G: gpio.DutyMax / 2, W: 0, B: 1}
+	got, err := sceneFromPreset("p", preset).Settings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	preset.Name = "p"
+	if got != preset {
+		t.Errorf("got %+v, want %+v", got, preset)
+	}
+}
@@ -2,6 +2,7 @@ package lights
 
 import (
 	"errors"
+	"github.com/klaital/wannetiot/pkg/util"
 	"periph.io/x/conn/v3/gpio"
 	"periph.io/x/conn/v3/physic"
 	"time"
@@ -31,6 +32,8 @@ type Service struct {
 	GreenPin gpio.PinOut
 	WhitePin gpio.PinOut
 	BluePin  gpio.PinOut
+	// PWMFrequency is the frequency to drive the pins at. Defaults to 5kHz.
+	PWMFrequency physic.Frequency
 
 	// BaseSetting specifies the color mix at 100% power. Other modes are calculated based on this.
 	BaseSetting LightConfig
@@ -69,12 +72,20 @@ func (s *Service) ConfigureColors(newColors LightConfig) {
 	s.BaseSetting.W = gpio.DutyMax
 }
 
+// driveLights outputs the base colour mix at the current power, which is
+// treated as a perceived brightness level.
 func (s *Service) driveLights() {
 	// TODO: hook up pins
-	s.RedPin.PWM(gpio.Duty(float64(s.BaseSetting.R)*s.CurrentPower), 5*physic.KiloHertz)
-	s.GreenPin.PWM(gpio.Duty(float64(s.BaseSetting.G)*s.CurrentPower), 5*physic.KiloHertz)
-	s.WhitePin.PWM(gpio.Duty(float64(s.BaseSetting.W)*s.CurrentPower), 5*physic.KiloHertz)
-	s.BluePin.PWM(gpio.Duty(float64(s.BaseSetting.B)*s.CurrentPower), 5*physic.KiloHertz)
+	model := Brightness()
+	settings := model.Calibrate(model.Scale(s.BaseSetting, s.CurrentPower))
+	f := s.PWMFrequency
+	if f <= 0 {
+		f = 5 * physic.KiloHertz
+	}
+	util.DrivePWM(s.RedPin, settings.R, f, nil)
+	util.DrivePWM(s.GreenPin, settings.G, f, nil)
+	util.DrivePWM(s.WhitePin, settings.W, f, nil)
+	util.DrivePWM(s.BluePin, settings.B, f, nil)
 }
 
 // StartWakeup causes the lights to start coming on
@@ -1,6 +1,8 @@
 package lights
 
 import (
+	"sync"
+
 	log "github.com/sirupsen/logrus"
 	"periph.io/x/conn/v3/gpio"
 )
@@ -18,7 +20,7 @@ func LightSettingsFull() LightConfig {
 		greenDuty = gpio.DutyMax / 5
 	}
 	return LightConfig{
-		Name: "LIGHTS_FULL",
+		Name: "full",
 		R:    redDuty,
 		G:    greenDuty,
 		W:    gpio.DutyMax,
@@ -27,26 +29,33 @@ func LightSettingsFull() LightConfig {
 }
 
 var Full LightConfig = LightSettingsFull()
-var Off LightConfig = LightConfig{Name: "LIGHTS_OFF"}
-var dimmerPct float64 = 10.0
+var Off LightConfig = LightConfig{Name: "off"}
+
+// dimLevel is the perceived brightness (0.0 - 1.0) used for the "low" setting.
+var dimLevel float64 = 0.35
+var dimLevelLock sync.RWMutex
+
+// SetDimLevel changes the perceived brightness used for the "low" setting.
+func SetDimLevel(level float64) error {
+	if level > 1.0 || level < 0.0 {
+		return ErrInvalidMultiplier
+	}
+	dimLevelLock.Lock()
+	defer dimLevelLock.Unlock()
+	dimLevel = level
+	return nil
+}
+
+// DimLevel reports the perceived brightness used for the "low" setting.
+func DimLevel() float64 {
+	dimLevelLock.RLock()
+	defer dimLevelLock.RUnlock()
+	return dimLevel
+}
 
 // LightSettingLow generates the LightConfig for dim Soft White Light.
 func LightSettingLow() LightConfig {
-	//redDuty, err := gpio.ParseDuty("1%")
-	//if err != nil {
-	//	log.WithError(err).Error("Failed to parse duty setting")
-	//	redDuty = gpio.DutyMax / 100
-	//}
-	//white, err := gpio.ParseDuty("10%")
-	//if err != nil {
-	//	log.WithError(err).Error("Failed to parse duty setting")
-	//	white = gpio.DutyMax / 10
-	//}
-	return LightConfig{
-		Name: "LIGHTS_LOW",
-		R:    gpio.Duty(float64(Full.R) * dimmerPct),
-		G:    gpio.Duty(float64(Full.G) * dimmerPct),
-		W:    gpio.Duty(float64(Full.W) * dimmerPct),
-		B:    gpio.Duty(float64(Full.B) * dimmerPct),
-	}
+	low := Brightness().Scale(Full, DimLevel())
+	low.Name = "low"
+	return low
 }
@@ -0,0 +1,56 @@
+package lights
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSetDimLevel(t *testing.T) {
+	defer SetDimLevel(DimLevel())
+	tests := []struct {
+		level float64
+		valid bool
+	}{
+		{0, true},
+		{0.5, true},
+		{1, true},
+		{-0.1, false},
+		{1.1, false},
+	}
+	for _, tt := range tests {
+		before := DimLevel()
+		err := SetDimLevel(tt.level)
+		if (err == nil) != tt.valid {
+			t.Errorf("SetDimLevel(%v) got %v, want valid %v", tt.level, err, tt.valid)
+		}
+		want := before
+		if tt.valid {
+			want = tt.level
+		}
+		if got := DimLevel(); got != want {
+			t.Errorf("after SetDimLevel(%v) the level is %v, want %v", tt.level, got, want)
+		}
+	}
+}
+
+// The level is set by the API while the panels read it, which -race checks.
+func TestDimLevelConcurrent(t *testing.T) {
+	defer SetDimLevel(DimLevel())
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				SetDimLevel(float64(i) / 4)
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				LightSettingLow()
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,203 @@
+package lights
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/klaital/wannetiot/pkg/config"
+	"github.com/klaital/wannetiot/pkg/health"
+	log "github.com/sirupsen/logrus"
+	"periph.io/x/conn/v3/gpio"
+)
+
+// SleepOptions customizes a single run of the wind-down sleep timer. Zero
+// values fall back to the configured defaults.
+type SleepOptions struct {
+	// Duration is how long the lights take to fade out.
+	Duration time.Duration
+	// Warm shifts the lights to a warm colour temperature before fading them out. Nil uses the configured default.
+	Warm *bool
+	// Zones lists the zones or groups to fade out. Empty means all of them.
+	Zones []string
+}
+
+// RunSleep fades the lights from wherever they are down to off over the
+// sleep duration, optionally shifting them to a warm colour first. The fade
+// follows the brightness model, so it looks even rather than dropping off at
+// the end. It is cancelled when the context is, or when any other light
+// command takes over the strip.
+func RunSleep(ctx context.Context, cfg *config.Config, opts SleepOptions) {
+	logger := cfg.Logger.WithField("op", "lights.RunSleep")
+	if opts.Duration <= 0 {
+		opts.Duration = cfg.SleepDuration
+	}
+	warm := cfg.SleepWarm
+	if opts.Warm != nil {
+		warm = *opts.Warm
+	}
+
+	zs, err := SelectZones(opts.Zones...)
+	if err != nil {
+		logger.WithError(err).WithField("zones", opts.Zones).Error("Unknown sleep zones, fading out all zones")
+		zs = nil
+	}
+
+	setSleepStatus(&sleepStatus{started: time.Now(), duration: opts.Duration})
+	defer setSleepStatus(nil)
+
+	logger.WithFields(log.Fields{
+		"duration": opts.Duration.String(),
+		"warm":     warm,
+	}).Debug("Starting sleep timer")
+
+	a := animate(cfg, zs, cfg.SleepStepInterval, func(_ *Zone, from LightConfig) frameFunc {
+		// The warm shift takes at most half the timer, leaving the rest to fade out
+		start := from
+		var shift time.Duration
+		if warm {
+			start = warmMatch(from, cfg.SleepWarmKelvin)
+			shift = cfg.SleepWarmDuration
+			if shift > opts.Duration/2 {
+				shift = opts.Duration / 2
+			}
+		}
+		return func(elapsed time.Duration) (LightConfig, bool) {
+			if elapsed >= opts.Duration {
+				return Off, true
+			}
+			var settings LightConfig
+			if elapsed < shift {
+				settings = Blend(from, start, EaseInOut(float64(elapsed)/float64(shift)))
+			} else {
+				remaining := 1 - float64(elapsed-shift)/float64(opts.Duration-shift)
+				settings = Brightness().Scale(start, remaining)
+			}
+			settings.Name = "SLEEP"
+			return settings, false
+		}
+	})
+
+	select {
+	case <-ctx.Done():
+		a.Stop()
+		logger.Info("Sleep timer cancelled")
+	case completed := <-a.done:
+		if !completed {
+			logger.Info("Sleep timer interrupted by another light command")
+			return
+		}
+		logger.Info("Sleep timer complete, lights off")
+	}
+}
+
+// warmMatch generates the colour temperature setting with the same peak
+// channel output as `from`, so the shift changes the colour but not the
+// overall brightness.
+func warmMatch(from LightConfig, kelvin float64) LightConfig {
+	peak := from.R
+	for _, d := range []gpio.Duty{from.G, from.W, from.B} {
+		if d > peak {
+			peak = d
+		}
+	}
+	warm, err := ColorTemperature(kelvin, 1)
+	if err != nil {
+		log.WithError(err).WithField("kelvin", kelvin).Error("Unable to shift to warm colour, fading from the current colour")
+		return from
+	}
+	y := float64(peak) / float64(gpio.DutyMax)
+	return LightConfig{
+		Name:   warm.Name,
+		R:      gpio.Duty(float64(warm.R) * y),
+		G:      gpio.Duty(float64(warm.G) * y),
+		W:      gpio.Duty(float64(warm.W) * y),
+		B:      gpio.Duty(float64(warm.B) * y),
+		Kelvin: warm.Kelvin,
+	}
+}
+
+// sleepStatus tracks the sleep timer in progress, if any.
+type sleepStatus struct {
+	started  time.Time
+	duration time.Duration
+}
+
+var currentSleep *sleepStatus
+var sleepLock sync.Mutex
+
+// cancelSleep stops the sleep timer in progress.
+var cancelSleep context.CancelFunc
+var startSleep chan SleepOptions
+
+func setSleepStatus(s *sleepStatus) {
+	sleepLock.Lock()
+	defer sleepLock.Unlock()
+	currentSleep = s
+}
+
+// SleepProgress reports whether the sleep timer is running, how far through
+// it is in the range 0.0 - 1.0, and how long is left until the lights are off.
+func SleepProgress() (running bool, progress float64, remaining time.Duration) {
+	sleepLock.Lock()
+	defer sleepLock.Unlock()
+	if currentSleep == nil {
+		return false, 0, 0
+	}
+	elapsed := time.Since(currentSleep.started)
+	remaining = currentSleep.duration - elapsed
+	if remaining < 0 {
+		remaining = 0
+	}
+	return true, clampProgress(float64(elapsed) / float64(currentSleep.duration)), remaining
+}
+
+// StartSleepRunner runs the sleep timer whenever it is requested, until the context is cancelled.
+func StartSleepRunner(ctx context.Context, cfg *config.Config) {
+	startSleep = make(chan SleepOptions)
+	cfg.Logger.Info("Starting background job: sleep timer runner")
+	loop := health.NewLoop(0)
+	health.Register("sleep", true, loop)
+	loop.Start()
+	defer loop.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			cfg.Logger.Info("Halting sleep timer process")
+			return
+		case opts := <-startSleep:
+			runCtx, cancel := context.WithCancel(ctx)
+			sleepLock.Lock()
+			cancelSleep = cancel
+			sleepLock.Unlock()
+
+			RunSleep(runCtx, cfg, opts)
+
+			sleepLock.Lock()
+			cancelSleep = nil
+			sleepLock.Unlock()
+			cancel()
+		}
+	}
+}
+
+// DoSleep starts the sleep timer with the default options.
+func DoSleep() {
+	DoSleepWith(SleepOptions{})
+}
+
+// DoSleepWith starts the sleep timer, restarting it if one is already running.
+func DoSleepWith(opts SleepOptions) {
+	CancelSleep()
+	startSleep <- opts
+}
+
+// CancelSleep stops the sleep timer, if one is running. The lights are left
+// as they are, for the caller to set.
+func CancelSleep() {
+	sleepLock.Lock()
+	defer sleepLock.Unlock()
+	if cancelSleep != nil {
+		cancelSleep()
+	}
+}
@@ -2,87 +2,340 @@ package lights
 
 import (
 	"context"
+	"errors"
+	"sync"
+	"time"
+
 	"github.com/klaital/wannetiot/pkg/config"
+	"github.com/klaital/wannetiot/pkg/health"
 	log "github.com/sirupsen/logrus"
-	"periph.io/x/conn/v3/gpio"
-	"time"
 )
 
-// RunWakeup will turn the lights off, then gradually dim them to 100% brightness over the course of 30 minutes.
-func RunWakeup(ctx context.Context, cfg *config.Config, targetSettings LightConfig) {
-	// how much to change the lights every second
-	settingsDelta := LightConfig{
-		Name: "",
-		R:    gpio.Duty(float64(targetSettings.R) / cfg.WakeupDuration.Minutes()),
-		G:    gpio.Duty(float64(targetSettings.G) / cfg.WakeupDuration.Minutes()),
-		W:    gpio.Duty(float64(targetSettings.W) / cfg.WakeupDuration.Minutes()),
-		B:    gpio.Duty(float64(targetSettings.B) / cfg.WakeupDuration.Minutes()),
-	}
-
-	t := time.NewTicker(1 * time.Minute)
-	wakeupTimer = time.NewTimer(cfg.WakeupDuration)
-	current := LightConfig{
-		Name: "WAKEUP",
-		R:    0,
-		G:    0,
-		W:    0,
-		B:    0,
-	}
-	cfg.Logger.WithFields(log.Fields{
-		"delta":  settingsDelta,
-		"target": targetSettings,
+// WakeupOptions customizes a single run of the wakeup sequence. Zero values
+// fall back to the configured defaults.
+type WakeupOptions struct {
+	// Curve is the name of a registered WakeupCurve, such as "sunrise" or "linear".
+	Curve string
+	// Duration is how long the lights take to reach the target.
+	Duration time.Duration
+	// Target is the light setting to finish on. Defaults to LightSettingsFull.
+	Target *LightConfig
+	// Finale sounds an escalating alarm on the registered speakers once the lights reach the target,
+	// until the wakeup is dismissed or FinaleMax passes. Nil uses the configured default.
+	Finale    *bool
+	FinaleMax time.Duration
+	// FinaleEffect is played on the lights as the alarm starts. Nil uses the configured default, if any.
+	FinaleEffect *Effect
+	// Zones lists the zones or groups to wake up. Empty means all of them.
+	Zones []string
+}
+
+// RunWakeup will turn the lights off, then gradually bring them up to the target following the selected curve.
+// The lights are updated every cfg.WakeupStepInterval so that there are no visible steps. The wakeup is
+// cancelled when the context is, or when any other light command takes over the strip.
+func RunWakeup(ctx context.Context, cfg *config.Config, opts WakeupOptions) {
+	logger := cfg.Logger.WithField("op", "lights.RunWakeup")
+	if opts.Curve == "" {
+		opts.Curve = cfg.WakeupCurve
+	}
+	curve, err := LookupCurve(opts.Curve)
+	if err != nil {
+		logger.WithError(err).WithField("curve", opts.Curve).Error("Unknown wakeup curve, using sunrise")
+		opts.Curve = "sunrise"
+		curve = SunriseCurve()
+	}
+	if opts.Duration <= 0 {
+		opts.Duration = cfg.WakeupDuration
+	}
+	finale := cfg.WakeupFinale
+	if opts.Finale != nil {
+		finale = *opts.Finale
+	}
+	if opts.FinaleMax <= 0 {
+		opts.FinaleMax = cfg.WakeupFinaleMax
+	}
+	if opts.FinaleEffect == nil && cfg.WakeupFinaleEffect != "" {
+		e, err := ParseEffect(cfg.WakeupFinaleEffect)
+		if err != nil {
+			logger.WithError(err).WithField("effect", cfg.WakeupFinaleEffect).Error("Invalid wakeup finale effect, ignoring")
+		} else {
+			opts.FinaleEffect = &e
+		}
+	}
+	target := LightSettingsFull()
+	if opts.Target != nil {
+		target = *opts.Target
+	}
+	zs, err := SelectZones(opts.Zones...)
+	if err != nil {
+		logger.WithError(err).WithField("zones", opts.Zones).Error("Unknown wakeup zones, waking up all zones")
+		zs = nil
+	}
+	if opts.FinaleEffect != nil && len(opts.Zones) > 0 && len(opts.FinaleEffect.Zones) == 0 {
+		// Play the alarm's effect on the zones being woken up
+		e := *opts.FinaleEffect
+		e.Zones = opts.Zones
+		opts.FinaleEffect = &e
+	}
+
+	// In an already bright room, the alarm still sounds, but the lights are left as they are
+	lux, skipLights := ambientSkipsWakeup()
+	if skipLights {
+		logger.WithField("lux", lux).Info("Room is already bright, skipping the wakeup lights")
+	}
+
+	status := &wakeupStatus{
+		duration:    opts.Duration,
+		curve:       opts.Curve,
+		segment:     wakeupNow(),
+		snoozeLimit: cfg.WakeupSnoozeLimit,
+	}
+	setWakeupStatus(status)
+	defer setWakeupStatus(nil)
+
+	logger.WithFields(log.Fields{
+		"curve":    opts.Curve,
+		"duration": opts.Duration.String(),
+		"target":   target,
 	}).Debug("Starting wakeup lights")
-	DriveLights(cfg, &current)
+
 	for {
+		// Progress banked before any snooze carries over into the resumed sequence
+		banked := status.banked
+		a := animate(cfg, zs, cfg.WakeupStepInterval, func(_ *Zone, from LightConfig) frameFunc {
+			return func(elapsed time.Duration) (LightConfig, bool) {
+				if skipLights {
+					return from, true
+				}
+				elapsed += banked
+				if elapsed >= opts.Duration {
+					return target, true
+				}
+				settings := curve.At(clampProgress(float64(elapsed)/float64(opts.Duration)), target)
+				settings.Name = "WAKEUP"
+				return settings, false
+			}
+		})
+
+		var d time.Duration
 		select {
 		case <-ctx.Done():
-			t.Stop()
+			a.Stop()
+			logger.Info("Wakeup lights halted")
 			return
-		case <-wakeupTimer.C:
-			cfg.Logger.Info("Wakeup lights complete")
-			DriveLights(cfg, &targetSettings)
-			t.Stop()
-			return
-		case <-t.C:
-			// New Settings
-			AddSettings(&current, &settingsDelta)
-
-			DriveLights(cfg, &current)
-		case <-haltWakeup:
-			t.Stop()
-			wakeupTimer.Stop()
+		case completed := <-a.done:
+			if !completed {
+				logger.Info("Wakeup lights interrupted by another light command")
+				return
+			}
+			logger.Info("Wakeup lights complete")
+			if !finale {
+				return
+			}
+			var snoozed bool
+			if d, snoozed = runFinale(ctx, cfg, opts.FinaleMax, opts.FinaleEffect, logger); !snoozed {
+				return
+			}
+		case d = <-snoozeWakeup:
+		}
+
+		wakeupLock.Lock()
+		status.snooze(d)
+		wakeupLock.Unlock()
+		logger.WithFields(log.Fields{
+			"snooze":   d.String(),
+			"progress": float64(status.banked) / float64(opts.Duration),
+		}).Info("Wakeup snoozed")
+
+		// Dim back down to the start of the curve while snoozing
+		if !skipLights {
+			dimmed := curve.At(0, target)
+			dimmed.Name = "WAKEUP"
+			FadeZonesTo(cfg, zs, dimmed, cfg.FadeDuration, EaseInOut)
+		}
+		resume := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			resume.Stop()
+			logger.Info("Wakeup lights halted while snoozed")
 			return
+		case <-resume.C:
 		}
+
+		wakeupLock.Lock()
+		status.resume()
+		wakeupLock.Unlock()
+		logger.Info("Resuming wakeup after snooze")
+	}
+}
+
+// wakeupStatus tracks the wakeup sequence in progress, if any.
+type wakeupStatus struct {
+	duration time.Duration
+	curve    string
+	// segment is when the sequence started, or last resumed from a snooze
+	segment time.Time
+	// banked is the time the sequence had run for before the last snooze
+	banked       time.Duration
+	snoozedUntil time.Time
+	snoozes      int
+	snoozeLimit  int
+	// finaleLevel is the current round of the end-of-wakeup alarm, or 0 if it isn't sounding
+	finaleLevel int
+}
+
+// snooze banks the time the sequence has run for, up to the end of the curve
+// if it was snoozed from the alarm, and starts the snooze. The wakeupLock
+// must be held.
+func (s *wakeupStatus) snooze(d time.Duration) {
+	now := wakeupNow()
+	s.banked += now.Sub(s.segment)
+	if s.banked > s.duration {
+		s.banked = s.duration
+	}
+	s.snoozedUntil = now.Add(d)
+	s.finaleLevel = 0
+}
+
+// resume starts the next segment of the sequence after a snooze. The
+// wakeupLock must be held.
+func (s *wakeupStatus) resume() {
+	s.segment = wakeupNow()
+	s.snoozedUntil = time.Time{}
+}
+
+// progress is how far through the sequence is. The wakeupLock must be held.
+func (s *wakeupStatus) progress() float64 {
+	elapsed := s.banked
+	if s.snoozedUntil.IsZero() {
+		elapsed += wakeupNow().Sub(s.segment)
+	}
+	return clampProgress(float64(elapsed) / float64(s.duration))
+}
+
+// wakeupNow is the clock the wakeup's progress is measured by, replaced in
+// tests.
+var wakeupNow = time.Now
+
+var currentWakeup *wakeupStatus
+var wakeupLock sync.Mutex
+
+// cancelWakeup stops the wakeup sequence in progress.
+var cancelWakeup context.CancelFunc
+var startWakeup chan WakeupOptions
+var snoozeWakeup = make(chan time.Duration, 1)
+
+var ErrNoWakeup = errors.New("no wakeup in progress")
+var ErrSnoozeLimit = errors.New("wakeup snooze limit reached")
+
+func setWakeupStatus(s *wakeupStatus) {
+	wakeupLock.Lock()
+	defer wakeupLock.Unlock()
+	currentWakeup = s
+	// Drop any snooze request left over from a previous run
+	select {
+	case <-snoozeWakeup:
+	default:
+	}
+}
+
+// WakeupProgress reports whether a wakeup sequence is running, and how far
+// through it is, in the range 0.0 - 1.0.
+func WakeupProgress() (running bool, progress float64) {
+	wakeupLock.Lock()
+	defer wakeupLock.Unlock()
+	if currentWakeup == nil {
+		return false, 0
+	}
+	return true, currentWakeup.progress()
+}
+
+// WakeupSnoozed reports whether the wakeup sequence is snoozed, and until when.
+func WakeupSnoozed() (snoozed bool, until time.Time) {
+	wakeupLock.Lock()
+	defer wakeupLock.Unlock()
+	if currentWakeup == nil || currentWakeup.snoozedUntil.IsZero() {
+		return false, time.Time{}
 	}
+	return true, currentWakeup.snoozedUntil
 }
 
-var startWakeup chan bool
-var haltWakeup chan bool
-var wakeupTimer *time.Timer
+// SnoozeWakeup dims the wakeup sequence back down, and resumes it from where
+// it was after the given duration. Each wakeup may only be snoozed up to its
+// configured limit.
+func SnoozeWakeup(d time.Duration) error {
+	wakeupLock.Lock()
+	defer wakeupLock.Unlock()
+	if currentWakeup == nil || !currentWakeup.snoozedUntil.IsZero() {
+		return ErrNoWakeup
+	}
+	if currentWakeup.snoozes >= currentWakeup.snoozeLimit {
+		return ErrSnoozeLimit
+	}
+	select {
+	case snoozeWakeup <- d:
+		currentWakeup.snoozes++
+		return nil
+	default:
+		return ErrNoWakeup
+	}
+}
 
+// StartWakeupRunner runs the wakeup sequence whenever it is requested, until the context is cancelled.
 func StartWakeupRunner(ctx context.Context, cfg *config.Config) {
-	wakeupTimer = time.NewTimer(cfg.WakeupDuration)
-	startWakeup = make(chan bool)
-	haltWakeup = make(chan bool)
+	startWakeup = make(chan WakeupOptions)
+	if cfg.WakeupKeyframes != "" {
+		custom, err := ParseKeyframes(cfg.WakeupKeyframes)
+		if err != nil {
+			cfg.Logger.WithError(err).WithField("keyframes", cfg.WakeupKeyframes).Error("Failed to parse custom wakeup keyframes")
+		} else {
+			RegisterCurve("custom", custom)
+		}
+	}
 	cfg.Logger.Info("Starting background job: wakeup lights runner")
+	loop := health.NewLoop(0)
+	health.Register("wakeup", true, loop)
+	loop.Start()
+	defer loop.Stop()
 	for {
 		select {
 		case <-ctx.Done():
 			cfg.Logger.Info("Halting wakeup lights process")
 			return
-		case <-startWakeup:
+		case opts := <-startWakeup:
 			cfg.Logger.Debug("Signalling wakeup lights to start")
-			RunWakeup(ctx, cfg, LightSettingsFull())
+			runCtx, cancel := context.WithCancel(ctx)
+			wakeupLock.Lock()
+			cancelWakeup = cancel
+			wakeupLock.Unlock()
+
+			RunWakeup(runCtx, cfg, opts)
+
+			wakeupLock.Lock()
+			cancelWakeup = nil
+			wakeupLock.Unlock()
+			cancel()
 		}
 	}
 }
 
+// DoWakeup starts the wakeup sequence with the default options.
 func DoWakeup() {
-	startWakeup <- true
+	DoWakeupWith(WakeupOptions{})
+}
+
+// DoWakeupWith starts the wakeup sequence, restarting it if one is already running.
+func DoWakeupWith(opts WakeupOptions) {
+	HaltWakeup()
+	startWakeup <- opts
 }
+
+// HaltWakeup stops the wakeup sequence, if one is running. The lights are
+// left as they are, for the caller to set.
 func HaltWakeup() {
-	if wakeupTimer != nil {
-		wakeupTimer.Stop()
-		//haltWakeup <- true
+	wakeupLock.Lock()
+	defer wakeupLock.Unlock()
+	if cancelWakeup != nil {
+		cancelWakeup()
 	}
 }
@@ -0,0 +1,116 @@
+package lights
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeClock replaces the wakeup's clock until the test ends.
+type fakeClock struct {
+	now time.Time
+}
+
+func useFakeClock(t *testing.T) *fakeClock {
+	c := &fakeClock{now: time.Date(2021, 6, 1, 6, 0, 0, 0, time.UTC)}
+	wakeupNow = func() time.Time { return c.now }
+	t.Cleanup(func() { wakeupNow = time.Now })
+	return c
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func TestWakeupSnoozeBanksProgress(t *testing.T) {
+	const ramp = 30 * time.Minute
+	const snooze = 9 * time.Minute
+
+	tests := []struct {
+		name string
+		// ran is how long the sequence runs for before each snooze
+		ran []time.Duration
+		// inFinale marks the snoozes made while the alarm was sounding
+		inFinale []bool
+		want     time.Duration
+	}{
+		{
+			name:     "snoozed during the ramp",
+			ran:      []time.Duration{10 * time.Minute},
+			inFinale: []bool{false},
+			want:     10 * time.Minute,
+		},
+		{
+			name:     "snoozed twice during the ramp",
+			ran:      []time.Duration{10 * time.Minute, 5 * time.Minute},
+			inFinale: []bool{false, false},
+			want:     15 * time.Minute,
+		},
+		{
+			name:     "snoozed during the alarm",
+			ran:      []time.Duration{ramp + 2*time.Minute},
+			inFinale: []bool{true},
+			want:     ramp,
+		},
+		{
+			name:     "snoozed during the ramp, then the alarm",
+			ran:      []time.Duration{10 * time.Minute, 25 * time.Minute},
+			inFinale: []bool{false, true},
+			want:     ramp,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := useFakeClock(t)
+			status := &wakeupStatus{duration: ramp, segment: wakeupNow()}
+			for i, ran := range tt.ran {
+				clock.advance(ran)
+				if tt.inFinale[i] {
+					status.finaleLevel = 2
+				}
+				status.snooze(snooze)
+				if got := status.snoozedUntil; !got.Equal(clock.now.Add(snooze)) {
+					t.Fatalf("snoozed until %v, want %v", got, clock.now.Add(snooze))
+				}
+				if status.finaleLevel != 0 {
+					t.Errorf("the alarm is still sounding after the snooze")
+				}
+
+				// The progress holds while snoozed, and carries on from it after
+				before := status.progress()
+				clock.advance(snooze)
+				if got := status.progress(); got != before {
+					t.Errorf("progress moved from %v to %v while snoozed", before, got)
+				}
+				status.resume()
+				if got := status.progress(); got != before {
+					t.Errorf("progress jumped from %v to %v on resuming", before, got)
+				}
+			}
+			if status.banked != tt.want {
+				t.Errorf("banked %v, want %v", status.banked, tt.want)
+			}
+		})
+	}
+}
+
+func TestWakeupProgress(t *testing.T) {
+	clock := useFakeClock(t)
+	status := &wakeupStatus{duration: 20 * time.Minute, segment: wakeupNow(), snoozeLimit: 1}
+	setWakeupStatus(status)
+	defer setWakeupStatus(nil)
+
+	clock.advance(5 * time.Minute)
+	if running, progress := WakeupProgress(); !running || progress != 0.25 {
+		t.Errorf("got running %v, progress %v, want a quarter of the way", running, progress)
+	}
+	if err := SnoozeWakeup(time.Minute); err != nil {
+		t.Fatalf("snoozing: %v", err)
+	}
+	if err := SnoozeWakeup(time.Minute); err != ErrSnoozeLimit {
+		t.Errorf("snoozing past the limit got %v, want %v", err, ErrSnoozeLimit)
+	}
+	clock.advance(time.Hour)
+	if _, progress := WakeupProgress(); progress != 1 {
+		t.Errorf("progress %v past the end, want 1", progress)
+	}
+}
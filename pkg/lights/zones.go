@@ -0,0 +1,273 @@
+package lights
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/klaital/wannetiot/pkg/config"
+	"github.com/klaital/wannetiot/pkg/util"
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/physic"
+)
+
+// Zone is an independently controlled LED strip. Every zone is driven with
+// the same RGBW light settings, which are mapped onto whichever channels the
+// strip actually has.
+type Zone struct {
+	Name   string
+	Type   string
+	Groups []string
+
+	channels    map[string]gpio.PinOut
+	maxDuty     ChannelFactors
+	calibration ChannelFactors
+	warmKelvin  float64
+	coolKelvin  float64
+	frequency   physic.Frequency
+	resolution  int
+	hardwarePWM bool
+
+	// output records the uncalibrated settings most recently written, so that
+	// fades can start from wherever the zone actually is.
+	output LightConfig
+	// anim is the animation that owns the zone, if any.
+	anim *animation
+	// energy accounts for the power the zone draws.
+	energy *zoneEnergy
+}
+
+// zones are all of the node's strips. They are set up once at startup.
+var zones []*Zone
+
+var ErrUnknownZone = errors.New("unknown zone")
+
+// ConfigureZones sets up the zones from the app config, with each zone's
+// calibration falling back to the global brightness model.
+func ConfigureZones(cfg *config.Config) {
+	model := NewBrightnessModel(cfg)
+	factors := func(overrides map[string]float64, defaults ChannelFactors) ChannelFactors {
+		f := defaults
+		for channel, v := range overrides {
+			switch channel {
+			case "red":
+				f.R = v
+			case "green":
+				f.G = v
+			case "white":
+				f.W = v
+			case "blue":
+				f.B = v
+			}
+		}
+		return f
+	}
+
+	configured := make([]*Zone, 0, len(cfg.LedZones))
+	for _, zc := range cfg.LedZones {
+		z := &Zone{
+			Name:        zc.Name,
+			Type:        zc.Type,
+			Groups:      zc.Groups,
+			channels:    zc.PinOuts,
+			maxDuty:     factors(zc.MaxDuty, model.MaxDuty),
+			calibration: factors(zc.Calibration, model.Calibration),
+			warmKelvin:  zc.WarmKelvin,
+			coolKelvin:  zc.CoolKelvin,
+			frequency:   zc.PWMFrequency,
+			resolution:  zc.Resolution,
+			hardwarePWM: zc.HardwarePWM,
+		}
+		watts := map[string]float64{
+			"red":   cfg.WattsRed,
+			"green": cfg.WattsGreen,
+			"white": cfg.WattsWhite,
+			"blue":  cfg.WattsBlue,
+			"warm":  cfg.WattsWhite,
+			"cool":  cfg.WattsWhite,
+		}
+		for channel, w := range zc.Watts {
+			watts[channel] = w
+		}
+		z.energy = newZoneEnergy(watts)
+		if z.frequency <= 0 {
+			z.frequency = 5 * physic.KiloHertz
+		}
+		if z.warmKelvin <= 0 {
+			z.warmKelvin = 2700
+		}
+		if z.coolKelvin <= 0 {
+			z.coolKelvin = 6500
+		}
+		configured = append(configured, z)
+	}
+
+	animationLock.Lock()
+	defer animationLock.Unlock()
+	zones = configured
+}
+
+// AllZones lists every zone, in the order they were configured.
+func AllZones() []*Zone {
+	animationLock.Lock()
+	defer animationLock.Unlock()
+	return append([]*Zone(nil), zones...)
+}
+
+// SelectZones finds the zones matching any of the given zone or group names.
+// No names, or "all", selects every zone.
+func SelectZones(names ...string) ([]*Zone, error) {
+	all := AllZones()
+	selected := make([]*Zone, 0, len(all))
+	picked := make(map[*Zone]bool)
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if name == "all" {
+			return all, nil
+		}
+		found := false
+		for _, z := range all {
+			if z.matches(name) {
+				found = true
+				if !picked[z] {
+					picked[z] = true
+					selected = append(selected, z)
+				}
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownZone, name)
+		}
+	}
+	if len(picked) == 0 {
+		return all, nil
+	}
+	return selected, nil
+}
+
+// zoneNames lists the names of the zones, for logging.
+func zoneNames(zs []*Zone) []string {
+	if zs == nil {
+		return []string{"all"}
+	}
+	names := make([]string, len(zs))
+	for i, z := range zs {
+		names[i] = z.Name
+	}
+	return names
+}
+
+// matches reports whether the zone has the given name, or is in the group.
+func (z *Zone) matches(name string) bool {
+	if z.Name == name {
+		return true
+	}
+	for _, g := range z.Groups {
+		if g == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Channels lists the names of the zone's channels.
+func (z *Zone) Channels() []string {
+	return config.ZoneChannels[z.Type]
+}
+
+// Frequency reports the zone's PWM frequency.
+func (z *Zone) Frequency() physic.Frequency {
+	return z.frequency
+}
+
+// HardwarePWM reports whether every channel of the zone has a dedicated PWM generator.
+func (z *Zone) HardwarePWM() bool {
+	return z.hardwarePWM
+}
+
+// Output reports the settings currently being driven on the zone.
+func (z *Zone) Output() LightConfig {
+	animationLock.Lock()
+	defer animationLock.Unlock()
+	return z.output
+}
+
+// write calibrates the settings for the zone, maps them onto its channels
+// and drives the pins. The animation lock must be held.
+func (z *Zone) write(settings LightConfig) {
+	model := Brightness()
+	model.MaxDuty = z.maxDuty
+	model.Calibration = z.calibration
+	duties := z.channelDuties(model.Calibrate(settings))
+	for channel, duty := range duties {
+		duties[channel] = z.quantize(duty)
+		if pin := z.channels[channel]; pin != nil {
+			util.DrivePWM(pin, duties[channel], z.frequency, nil)
+		}
+	}
+	z.output = settings
+	z.energy.drive(duties)
+	driveNode()
+}
+
+// quantize rounds the duty cycle to the zone's resolution, if it has one.
+func (z *Zone) quantize(d gpio.Duty) gpio.Duty {
+	if z.resolution <= 0 || z.resolution >= 24 {
+		return d
+	}
+	steps := float64(int(1)<<z.resolution - 1)
+	level := math.Round(float64(d) * steps / float64(gpio.DutyMax))
+	return gpio.Duty(math.Round(level * float64(gpio.DutyMax) / steps))
+}
+
+// channelDuties maps RGBW settings onto the zone's channels.
+func (z *Zone) channelDuties(s LightConfig) map[string]gpio.Duty {
+	sum := func(a, b gpio.Duty) gpio.Duty {
+		if a+b > gpio.DutyMax {
+			return gpio.DutyMax
+		}
+		return a + b
+	}
+	peak := s.R
+	for _, d := range []gpio.Duty{s.G, s.W, s.B} {
+		if d > peak {
+			peak = d
+		}
+	}
+
+	switch z.Type {
+	case "rgb":
+		// With no white LED, white is mixed from all three colours
+		return map[string]gpio.Duty{
+			"red":   sum(s.R, s.W),
+			"green": sum(s.G, s.W),
+			"blue":  sum(s.B, s.W),
+		}
+	case "white":
+		return map[string]gpio.Duty{"white": peak}
+	case "cct":
+		// Mix the warm and cool channels in mireds, which is how the eye sees
+		// the difference between colour temperatures
+		kelvin := s.Kelvin
+		if kelvin <= 0 {
+			kelvin = colorProfileKelvin()
+		}
+		mired := func(k float64) float64 { return 1e6 / k }
+		warm := clampProgress((mired(kelvin) - mired(z.coolKelvin)) / (mired(z.warmKelvin) - mired(z.coolKelvin)))
+		scale := float64(peak) / math.Max(warm, 1-warm)
+		return map[string]gpio.Duty{
+			"warm": gpio.Duty(math.Round(warm * scale)),
+			"cool": gpio.Duty(math.Round((1 - warm) * scale)),
+		}
+	}
+	return map[string]gpio.Duty{
+		"red":   s.R,
+		"green": s.G,
+		"white": s.W,
+		"blue":  s.B,
+	}
+}
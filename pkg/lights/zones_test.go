@@ -0,0 +1,122 @@
+package lights
+
+import (
+	"math"
+	"testing"
+
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/physic"
+
+	"github.com/klaital/wannetiot/pkg/pca9685"
+)
+
+// newSimZone creates a zone driven by a simulated PCA9685, with its channels
+// on consecutive outputs from 0.
+func newSimZone(t *testing.T, zoneType string, channels []string, calibration ChannelFactors) (*Zone, *pca9685.Sim) {
+	sim := pca9685.NewSim(pca9685.DefaultAddress)
+	dev, err := pca9685.New(sim, pca9685.DefaultAddress, physic.KiloHertz)
+	if err != nil {
+		t.Fatalf("starting the simulated chip: %v", err)
+	}
+	pins := make(map[string]gpio.PinOut, len(channels))
+	for i, name := range channels {
+		if pins[name], err = dev.Pin(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	z := &Zone{
+		Name:        "test",
+		Type:        zoneType,
+		channels:    pins,
+		maxDuty:     ChannelFactors{R: 1, G: 1, W: 1, B: 1},
+		calibration: calibration,
+		warmKelvin:  2700,
+		coolKelvin:  6500,
+		frequency:   dev.Frequency(),
+		energy:      newZoneEnergy(map[string]float64{}),
+	}
+	return z, sim
+}
+
+// assertDuty checks a channel's output to within one of the chip's steps.
+func assertDuty(t *testing.T, sim *pca9685.Sim, channel int, name string, want gpio.Duty) {
+	t.Helper()
+	got := sim.Duty(channel)
+	if math.Abs(float64(got)-float64(want)) > float64(gpio.DutyMax)/pca9685.Steps {
+		t.Errorf("%s outputs %d, want %d", name, got, want)
+	}
+}
+
+func TestZoneWriteRGBW(t *testing.T) {
+	minDuty := gpio.Duty(math.Round(DefaultBrightnessModel().MinDuty * float64(gpio.DutyMax)))
+	tests := []struct {
+		name        string
+		settings    LightConfig
+		calibration ChannelFactors
+		want        [4]gpio.Duty // red, green, white, blue
+	}{
+		{
+			name:        "full",
+			settings:    LightConfig{R: gpio.DutyMax, G: gpio.DutyMax, W: gpio.DutyMax, B: gpio.DutyMax},
+			calibration: ChannelFactors{R: 1, G: 1, W: 1, B: 1},
+			want:        [4]gpio.Duty{gpio.DutyMax, gpio.DutyMax, gpio.DutyMax, gpio.DutyMax},
+		},
+		{
+			name:        "off",
+			settings:    Off,
+			calibration: ChannelFactors{R: 1, G: 1, W: 1, B: 1},
+		},
+		{
+			name:        "calibrated",
+			settings:    LightConfig{R: gpio.DutyMax / 2, W: gpio.DutyMax, B: gpio.DutyMax / 4},
+			calibration: ChannelFactors{R: 0.5, G: 1, W: 0.8, B: 1},
+			want:        [4]gpio.Duty{gpio.DutyMax / 4, 0, gpio.DutyMax * 4 / 5, gpio.DutyMax / 4},
+		},
+		{
+			name:        "lifted to the minimum",
+			settings:    LightConfig{G: 1},
+			calibration: ChannelFactors{R: 1, G: 1, W: 1, B: 1},
+			want:        [4]gpio.Duty{0, minDuty, 0, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			z, sim := newSimZone(t, "rgbw", []string{"red", "green", "white", "blue"}, tt.calibration)
+			z.write(tt.settings)
+			for i, name := range []string{"red", "green", "white", "blue"} {
+				assertDuty(t, sim, i, name, tt.want[i])
+			}
+			if z.output != tt.settings {
+				t.Errorf("the zone recorded %+v as its output, want %+v", z.output, tt.settings)
+			}
+		})
+	}
+}
+
+func TestZoneWriteCCT(t *testing.T) {
+	ones := ChannelFactors{R: 1, G: 1, W: 1, B: 1}
+	half := gpio.DutyMax / 2
+	mired := func(k float64) float64 { return 1e6 / k }
+	// At 4000K the mix is part way between the warm and cool whites, in mireds
+	warm4000 := (mired(4000) - mired(6500)) / (mired(2700) - mired(6500))
+	tests := []struct {
+		name     string
+		settings LightConfig
+		warm     gpio.Duty
+		cool     gpio.Duty
+	}{
+		{"warm white", LightConfig{W: half, Kelvin: 2700}, half, 0},
+		{"cool white", LightConfig{W: half, Kelvin: 6500}, 0, half},
+		{"past the cool white", LightConfig{W: half, Kelvin: 9000}, 0, half},
+		{"in between", LightConfig{W: half, Kelvin: 4000}, gpio.Duty(float64(half) * warm4000 / (1 - warm4000)), half},
+		{"brightest channel", LightConfig{R: half, W: half / 2, Kelvin: 2700}, half, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			z, sim := newSimZone(t, "cct", []string{"warm", "cool"}, ones)
+			z.write(tt.settings)
+			assertDuty(t, sim, 0, "warm", tt.warm)
+			assertDuty(t, sim, 1, "cool", tt.cool)
+		})
+	}
+}
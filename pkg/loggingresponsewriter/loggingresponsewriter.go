@@ -1,80 +1,380 @@
+// Package loggingresponsewriter writes an access log entry for each HTTP
+// request, as structured logrus fields. Each request gets an ID, which is
+// passed on in its context and the X-Request-ID response header, so that
+// the other log entries made while handling it can be tied back to it.
 package loggingresponsewriter
 
 import (
-	"bytes"
-	"fmt"
-	"github.com/sirupsen/logrus"
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/sirupsen/logrus"
 )
 
+// RequestIDHeader carries the request ID. An ID sent by the client, such as
+// a proxy, is kept if it looks sane.
+const RequestIDHeader = "X-Request-ID"
+
+// Redacted replaces the values of secrets in the log.
+const Redacted = "REDACTED"
+
+// redactedHeaders are never logged as they are.
+var redactedHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+	"X-Api-Key":           true,
+}
+
+// redactedParams are query parameters that are never logged as they are.
+var redactedParams = map[string]bool{
+	"token":        true,
+	"access_token": true,
+	"api_key":      true,
+	"key":          true,
+	"password":     true,
+	"secret":       true,
+}
+
+// Options configures the access log.
+type Options struct {
+	// Logger receives the access log. Nil uses the standard logger.
+	Logger *logrus.Logger
+	// MaxBodyBytes is how much of each request and response body to log.
+	// Zero logs no bodies. Event streams are never logged.
+	MaxBodyBytes int
+	// Headers logs the request and response headers, with secrets redacted.
+	Headers bool
+}
+
+// LoggingResponseWriter records the status and size of a response, and the
+// start of its body when asked to.
 type LoggingResponseWriter struct {
-	w          *http.ResponseWriter
-	body       *bytes.Buffer
+	http.ResponseWriter
 	statusCode int
-	logger     *logrus.Entry
+	bytes      int64
+	body       *cappedBuffer
+}
+
+// NewLoggingResponseWriter wraps a response. Up to maxBody bytes of the
+// body are kept.
+func NewLoggingResponseWriter(w http.ResponseWriter, maxBody int) *LoggingResponseWriter {
+	lw := &LoggingResponseWriter{ResponseWriter: w}
+	if maxBody > 0 {
+		lw.body = &cappedBuffer{max: maxBody}
+	}
+	return lw
+}
+
+func (lw *LoggingResponseWriter) WriteHeader(statusCode int) {
+	if lw.statusCode == 0 {
+		lw.statusCode = statusCode
+	}
+	lw.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (lw *LoggingResponseWriter) Write(buf []byte) (int, error) {
+	if lw.statusCode == 0 {
+		lw.statusCode = http.StatusOK
+	}
+	n, err := lw.ResponseWriter.Write(buf)
+	lw.bytes += int64(n)
+	// Event streams run as long as the client is connected, so don't keep them
+	if lw.body != nil && !isEventStream(lw.Header()) {
+		lw.body.Write(buf[:n])
+	}
+	return n, err
 }
 
-func NewWithLogrus(w http.ResponseWriter, logger *logrus.Entry) *LoggingResponseWriter {
-	var buf bytes.Buffer
-	l := logger
-	if l == nil {
-		logger := logrus.New()
-		logger.SetLevel(logrus.DebugLevel)
-		l = logrus.NewEntry(logger)
+// Flush sends any buffered data to the client, for handlers that stream.
+func (lw *LoggingResponseWriter) Flush() {
+	if f, ok := lw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack hands the connection over to the handler, such as for a WebSocket.
+func (lw *LoggingResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := lw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("the response does not support hijacking")
+	}
+	if lw.statusCode == 0 {
+		lw.statusCode = http.StatusSwitchingProtocols
+	}
+	return h.Hijack()
+}
 
+// Unwrap returns the wrapped response, for http.ResponseController.
+func (lw *LoggingResponseWriter) Unwrap() http.ResponseWriter {
+	return lw.ResponseWriter
+}
+
+// Status is the response's status code, or 200 if none has been written yet.
+func (lw *LoggingResponseWriter) Status() int {
+	if lw.statusCode == 0 {
+		return http.StatusOK
 	}
-	return &LoggingResponseWriter{
-		w:      &w,
-		body:   &buf,
-		logger: l,
+	return lw.statusCode
+}
+
+// Bytes is the size of the response body written so far.
+func (lw *LoggingResponseWriter) Bytes() int64 {
+	return lw.bytes
+}
+
+// Body is the start of the response body, if it is being kept.
+func (lw *LoggingResponseWriter) Body() string {
+	if lw.body == nil {
+		return ""
 	}
+	return lw.body.String()
 }
 
+// AccessLog logs each request once it has been handled, at Info, or at Warn
+// and Error for client and server errors.
+func AccessLog(opts Options) func(next http.Handler) http.Handler {
+	logger := opts.Logger
+	if logger == nil {
+		logger = logrus.StandardLogger()
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			start := time.Now()
+			id := req.Header.Get(RequestIDHeader)
+			if !validRequestID(id) {
+				id = newRequestID()
+			}
+			resp.Header().Set(RequestIDHeader, id)
+			info := &requestInfo{id: id}
+			req = req.WithContext(context.WithValue(req.Context(), requestInfoKey{}, info))
+
+			var reqBody *cappedBuffer
+			if opts.MaxBodyBytes > 0 && req.Body != nil && req.Body != http.NoBody {
+				reqBody = &cappedBuffer{max: opts.MaxBodyBytes}
+				req.Body = &teeBody{ReadCloser: req.Body, copy: reqBody}
+			}
+			lw := NewLoggingResponseWriter(resp, opts.MaxBodyBytes)
+			next.ServeHTTP(lw, req)
+
+			fields := logrus.Fields{
+				"request_id": id,
+				"method":     req.Method,
+				"route":      info.route(req),
+				"status":     lw.Status(),
+				"bytes":      lw.Bytes(),
+				"latency_ms": float64(time.Since(start).Microseconds()) / 1000,
+				"remote":     req.RemoteAddr,
+				"user_agent": req.UserAgent(),
+			}
+			if q := redactQuery(req); q != "" {
+				fields["query"] = q
+			}
+			for k, v := range info.fields() {
+				fields[k] = v
+			}
+			if opts.Headers {
+				fields["request_headers"] = redactHeaders(req.Header)
+				fields["response_headers"] = redactHeaders(lw.Header())
+			}
+			if reqBody != nil && reqBody.Len() > 0 {
+				fields["request_body"] = reqBody.String()
+			}
+			if body := lw.Body(); body != "" {
+				fields["response_body"] = body
+			}
+
+			entry := logger.WithFields(fields)
+			switch status := lw.Status(); {
+			case status >= http.StatusInternalServerError:
+				entry.Error("Request failed")
+			case status >= http.StatusBadRequest:
+				entry.Warn("Request rejected")
+			default:
+				entry.Info("Request handled")
+			}
+		})
+	}
+}
+
+// RequestLoggerMiddleware logs each request to the standard logger, without
+// headers or bodies.
 func RequestLoggerMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-		lw := NewWithLogrus(w, nil)
-		defer func() {
-			lw.logger.Info(fmt.Sprintf("[%s] (%d ms) [Request: %s %s] [Response: %s]",
-				time.Now().Format(time.RFC3339), time.Since(start).Milliseconds(),
-				r.Method, r.RequestURI,
-				lw.String()))
-		}()
-		next.ServeHTTP(lw, r)
-	})
+	return AccessLog(Options{})(next)
 }
 
-func (lw *LoggingResponseWriter) Write(buf []byte) (int, error) {
-	lw.body.Write(buf)
-	lw.logger.WithFields(logrus.Fields{
-		"buf": string(buf),
-		"len": len(buf),
-	}).Debug("Adding bytes to body")
-	return (*lw.w).Write(buf)
+// requestInfo collects what the handlers learn about a request, for its
+// access log entry.
+type requestInfo struct {
+	id    string
+	lock  sync.Mutex
+	name  string
+	extra logrus.Fields
 }
 
-func (lw *LoggingResponseWriter) Header() http.Header {
-	return (*lw.w).Header()
+type requestInfoKey struct{}
+
+func infoFrom(ctx context.Context) *requestInfo {
+	info, _ := ctx.Value(requestInfoKey{}).(*requestInfo)
+	return info
 }
 
-func (lw *LoggingResponseWriter) WriteHeader(statusCode int) {
-	lw.logger.WithFields(logrus.Fields{
-		"code":    statusCode,
-		"oldCode": lw.statusCode,
-	}).Debug("Setting status code")
-	lw.statusCode = statusCode
-	(*lw.w).WriteHeader(statusCode)
-}
-
-func (lw *LoggingResponseWriter) String() string {
-	var buf bytes.Buffer
-	buf.WriteString(fmt.Sprintf("Status=%d", lw.statusCode))
-	buf.WriteString("Headers:")
-	for k, v := range (*lw.w).Header() {
-		buf.WriteString(fmt.Sprintf("%s=%s", k, v))
-	}
-	buf.WriteString("Body:\n")
-	buf.WriteString(lw.body.String())
-	return buf.String()
+func (info *requestInfo) route(req *http.Request) string {
+	info.lock.Lock()
+	defer info.lock.Unlock()
+	if info.name != "" {
+		return info.name
+	}
+	return req.URL.Path
+}
+
+func (info *requestInfo) fields() logrus.Fields {
+	info.lock.Lock()
+	defer info.lock.Unlock()
+	fields := make(logrus.Fields, len(info.extra))
+	for k, v := range info.extra {
+		fields[k] = v
+	}
+	return fields
+}
+
+// RequestID returns the ID of the request being handled, or "" outside of
+// the access log middleware.
+func RequestID(ctx context.Context) string {
+	if info := infoFrom(ctx); info != nil {
+		return info.id
+	}
+	return ""
+}
+
+// SetRoute names the route that matched the request, such as
+// "/api/v1/scenes/{id}", to log instead of its path.
+func SetRoute(ctx context.Context, route string) {
+	if info := infoFrom(ctx); info != nil {
+		info.lock.Lock()
+		info.name = route
+		info.lock.Unlock()
+	}
+}
+
+// AddField adds a field to the request's access log entry, such as who
+// made it.
+func AddField(ctx context.Context, key string, value interface{}) {
+	if info := infoFrom(ctx); info != nil {
+		info.lock.Lock()
+		if info.extra == nil {
+			info.extra = make(logrus.Fields)
+		}
+		info.extra[key] = value
+		info.lock.Unlock()
+	}
+}
+
+// Entry returns the logger with the request's ID, for the handlers' own log
+// entries.
+func Entry(ctx context.Context, logger *logrus.Logger) *logrus.Entry {
+	entry := logrus.NewEntry(logger)
+	if id := RequestID(ctx); id != "" {
+		entry = entry.WithField("request_id", id)
+	}
+	return entry
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return strings.ReplaceAll(time.Now().Format("150405.000000"), ".", "")
+	}
+	return hex.EncodeToString(b)
+}
+
+// validRequestID accepts short IDs made of letters, digits and dashes, so
+// that a client can't put anything odd into the log.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+func redactHeaders(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for k, v := range h {
+		if redactedHeaders[http.CanonicalHeaderKey(k)] {
+			out[k] = Redacted
+			continue
+		}
+		out[k] = strings.Join(v, ", ")
+	}
+	return out
+}
+
+func redactQuery(req *http.Request) string {
+	if req.URL.RawQuery == "" {
+		return ""
+	}
+	q := req.URL.Query()
+	for k := range q {
+		if redactedParams[strings.ToLower(k)] {
+			q[k] = []string{Redacted}
+		}
+	}
+	return q.Encode()
+}
+
+func isEventStream(h http.Header) bool {
+	return strings.HasPrefix(h.Get("Content-Type"), "text/event-stream")
+}
+
+// cappedBuffer keeps the first max bytes written to it.
+type cappedBuffer struct {
+	max       int
+	buf       []byte
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) {
+	if room := b.max - len(b.buf); room < len(p) {
+		p = p[:room]
+		b.truncated = true
+	}
+	b.buf = append(b.buf, p...)
+}
+
+func (b *cappedBuffer) Len() int {
+	return len(b.buf)
+}
+
+func (b *cappedBuffer) String() string {
+	if b.truncated {
+		return string(b.buf) + "…"
+	}
+	return string(b.buf)
+}
+
+// teeBody keeps the start of a request body as the handler reads it.
+type teeBody struct {
+	io.ReadCloser
+	copy *cappedBuffer
+}
+
+func (t *teeBody) Read(p []byte) (int, error) {
+	n, err := t.ReadCloser.Read(p)
+	t.copy.Write(p[:n])
+	return n, err
 }
@@ -0,0 +1,246 @@
+package loggingresponsewriter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+// serve runs one request through the access log, and returns its entry.
+func serve(t *testing.T, opts Options, req *http.Request, handler http.HandlerFunc) (*logrus.Entry, *httptest.ResponseRecorder) {
+	logger, hook := test.NewNullLogger()
+	opts.Logger = logger
+	rec := httptest.NewRecorder()
+	AccessLog(opts)(handler).ServeHTTP(rec, req)
+	if n := len(hook.AllEntries()); n != 1 {
+		t.Fatalf("logged %d entries, want 1", n)
+	}
+	return hook.LastEntry(), rec
+}
+
+func TestAccessLogRedactsHeaders(t *testing.T) {
+	tests := []struct {
+		header string
+		value  string
+		want   string
+	}{
+		{"Authorization", "Bearer secret", Redacted},
+		{"Proxy-Authorization", "Basic secret", Redacted},
+		{"Cookie", "session=secret", Redacted},
+		{"X-Api-Key", "secret", Redacted},
+		{"x-api-key", "secret", Redacted},
+		{"Accept", "application/json", "application/json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/status", nil)
+			req.Header.Set(tt.header, tt.value)
+			entry, _ := serve(t, Options{Headers: true}, req, func(resp http.ResponseWriter, req *http.Request) {
+				http.SetCookie(resp, &http.Cookie{Name: "session", Value: "secret"})
+			})
+
+			headers := entry.Data["request_headers"].(map[string]string)
+			if got := headers[http.CanonicalHeaderKey(tt.header)]; got != tt.want {
+				t.Errorf("logged %s as %q, want %q", tt.header, got, tt.want)
+			}
+			if got := entry.Data["response_headers"].(map[string]string)["Set-Cookie"]; got != Redacted {
+				t.Errorf("logged Set-Cookie as %q", got)
+			}
+		})
+	}
+}
+
+func TestAccessLogLeavesHeadersOut(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/status", nil)
+	req.Header.Set("Accept", "application/json")
+	entry, _ := serve(t, Options{}, req, func(resp http.ResponseWriter, req *http.Request) {})
+	if _, ok := entry.Data["request_headers"]; ok {
+		t.Errorf("logged the headers without being asked to")
+	}
+}
+
+func TestAccessLogRedactsQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", ""},
+		{"types=lights", "types=lights"},
+		{"token=hunter2", "token=" + Redacted},
+		{"access_token=hunter2&types=lights", "access_token=" + Redacted + "&types=lights"},
+		{"API_KEY=hunter2", "API_KEY=" + Redacted},
+		{"password=hunter2&password=hunter3", "password=" + Redacted},
+		{"secret=hunter2&key=hunter3", "key=" + Redacted + "&secret=" + Redacted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/events?"+tt.query, nil)
+			entry, _ := serve(t, Options{}, req, func(resp http.ResponseWriter, req *http.Request) {
+				if req.URL.RawQuery != tt.query {
+					t.Errorf("the handler got %q, want the query untouched", req.URL.RawQuery)
+				}
+			})
+			got, _ := entry.Data["query"].(string)
+			if got != tt.want {
+				t.Errorf("logged %q, want %q", got, tt.want)
+			}
+			if strings.Contains(got, "hunter") {
+				t.Errorf("logged a secret: %q", got)
+			}
+		})
+	}
+}
+
+func TestAccessLogEntry(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		wantLevel logrus.Level
+	}{
+		{"ok", http.StatusOK, logrus.InfoLevel},
+		{"rejected", http.StatusNotFound, logrus.WarnLevel},
+		{"failed", http.StatusBadGateway, logrus.ErrorLevel},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/scenes/low", strings.NewReader(`{"name":"low"}`))
+			req.Header.Set(RequestIDHeader, "abc-123")
+			entry, rec := serve(t, Options{MaxBodyBytes: 8}, req, func(resp http.ResponseWriter, req *http.Request) {
+				SetRoute(req.Context(), "/api/v1/scenes/{id}")
+				AddField(req.Context(), "user", "tester")
+				io.ReadAll(req.Body)
+				resp.WriteHeader(tt.status)
+				fmt.Fprint(resp, "0123456789")
+			})
+
+			if entry.Level != tt.wantLevel {
+				t.Errorf("logged at %s, want %s", entry.Level, tt.wantLevel)
+			}
+			want := logrus.Fields{
+				"request_id":    "abc-123",
+				"route":         "/api/v1/scenes/{id}",
+				"status":        tt.status,
+				"bytes":         int64(10),
+				"user":          "tester",
+				"request_body":  `{"name":…`,
+				"response_body": "01234567…",
+			}
+			for k, v := range want {
+				if entry.Data[k] != v {
+					t.Errorf("logged %s as %v, want %v", k, entry.Data[k], v)
+				}
+			}
+			if got := rec.Header().Get(RequestIDHeader); got != "abc-123" {
+				t.Errorf("responded with request ID %q", got)
+			}
+		})
+	}
+}
+
+func TestAccessLogRequestID(t *testing.T) {
+	tests := []struct {
+		name string
+		sent string
+		kept bool
+	}{
+		{"none", "", false},
+		{"from a proxy", "7f3a-9c.b_1", true},
+		{"odd characters", "id\nlevel=error", false},
+		{"too long", strings.Repeat("a", 65), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/status", nil)
+			if tt.sent != "" {
+				req.Header.Set(RequestIDHeader, tt.sent)
+			}
+			var seen string
+			entry, rec := serve(t, Options{}, req, func(resp http.ResponseWriter, req *http.Request) {
+				seen = RequestID(req.Context())
+			})
+			id := rec.Header().Get(RequestIDHeader)
+			if tt.kept != (id == tt.sent) {
+				t.Errorf("responded with ID %q for %q", id, tt.sent)
+			}
+			if id == "" || seen != id || entry.Data["request_id"] != id {
+				t.Errorf("IDs differ: responded %q, handler saw %q, logged %v", id, seen, entry.Data["request_id"])
+			}
+		})
+	}
+}
+
+// hijackRecorder is a response that can be hijacked, like a real connection.
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	conn net.Conn
+}
+
+func (h *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return h.conn, bufio.NewReadWriter(bufio.NewReader(h.conn), bufio.NewWriter(h.conn)), nil
+}
+
+// plainWriter supports neither flushing nor hijacking.
+type plainWriter struct {
+	header http.Header
+}
+
+func (w *plainWriter) Header() http.Header         { return w.header }
+func (w *plainWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *plainWriter) WriteHeader(int)             {}
+
+func TestPassthrough(t *testing.T) {
+	conn, other := net.Pipe()
+	defer conn.Close()
+	defer other.Close()
+	flusher := httptest.NewRecorder()
+	hijacker := &hijackRecorder{ResponseRecorder: httptest.NewRecorder(), conn: conn}
+
+	tests := []struct {
+		name string
+		w    http.ResponseWriter
+		// rec records whether the response was flushed, if it can be
+		rec        *httptest.ResponseRecorder
+		wantConn   net.Conn
+		wantStatus int
+	}{
+		{"flusher", flusher, flusher, nil, http.StatusOK},
+		{"hijacker", hijacker, hijacker.ResponseRecorder, conn, http.StatusSwitchingProtocols},
+		{"neither", &plainWriter{header: make(http.Header)}, nil, nil, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The wrapper always offers both, and passes them on when it can
+			lw := NewLoggingResponseWriter(tt.w, 0)
+			var _ http.Flusher = lw
+			var _ http.Hijacker = lw
+
+			lw.Flush()
+			if tt.rec != nil && !tt.rec.Flushed {
+				t.Errorf("not flushed")
+			}
+
+			got, _, err := lw.Hijack()
+			if tt.wantConn == nil {
+				if err == nil {
+					t.Errorf("hijacked a response that can't be")
+				}
+			} else if err != nil || got != tt.wantConn {
+				t.Errorf("got %v, %v, want the connection", got, err)
+			}
+			if status := lw.Status(); status != tt.wantStatus {
+				t.Errorf("status is %d, want %d", status, tt.wantStatus)
+			}
+			if lw.Unwrap() != tt.w {
+				t.Errorf("unwrapped to a different response")
+			}
+		})
+	}
+}
@@ -0,0 +1,400 @@
+// Package netpanel implements the node's side of the network panel protocol,
+// for control panels that talk to the node over Wi-Fi, such as an ESP8266,
+// rather than being wired to its GPIO pins.
+//
+// A panel registers when it starts, and is given a session and how often to
+// send a heartbeat. Heartbeats report the panel's Wi-Fi signal and dimmer.
+// Button presses are sent as events as they happen, numbered so that a
+// retried event is only acted on once. The node pushes feedback to the panel
+// as commands, such as a chirp to confirm a page. The panel long-polls for
+// them, and they also come back with the response to each heartbeat and
+// event, for panels that can't keep a request open. Each command is
+// delivered once.
+//
+// A panel that sends a session the node doesn't know, such as after the node
+// restarts, gets ErrUnknownSession, and registers again.
+package netpanel
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/klaital/wannetiot/pkg/health"
+)
+
+var ErrUnknownPanel = errors.New("unknown panel")
+var ErrUnknownSession = errors.New("unknown session, register again")
+var ErrInvalid = errors.New("invalid panel message")
+
+// The events a panel sends for its buttons. The light switch's gestures are
+// told apart on the panel.
+const (
+	EventPager     = "pager"
+	EventTap       = "tap"
+	EventDoubleTap = "double_tap"
+	EventLongPress = "long_press"
+)
+
+// EventTypes lists the valid event types.
+var EventTypes = []string{EventPager, EventTap, EventDoubleTap, EventLongPress}
+
+// The commands the node sends a panel, for it to play on its LED and speaker
+// as it sees fit. They match the tones of the wired panels.
+const (
+	// CommandPaged confirms that the panel's page was sent.
+	CommandPaged = "paged"
+	// CommandAlreadyPaged means the page was a repeat, and was only counted.
+	CommandAlreadyPaged = "already_paged"
+	// CommandPageAcknowledged means a page from elsewhere was received.
+	CommandPageAcknowledged = "page_acknowledged"
+	// CommandScene confirms that the switch stepped the lights to the next scene.
+	CommandScene = "scene"
+	// CommandSleep confirms that the sleep timer started.
+	CommandSleep = "sleep"
+	// CommandSnooze confirms that the wakeup was snoozed.
+	CommandSnooze = "snooze"
+	// CommandDismissOn and CommandDismissOff confirm that the wakeup was
+	// dismissed, with the lights left on or switched off.
+	CommandDismissOn  = "dismiss_on"
+	CommandDismissOff = "dismiss_off"
+	// CommandDenied means the gesture couldn't be carried out.
+	CommandDenied = "denied"
+	// CommandAlarm sounds the wakeup alarm, at Level from 1 up.
+	CommandAlarm = "alarm"
+)
+
+// Registration is sent by a panel when it starts.
+type Registration struct {
+	ID       string `json:"id"`
+	Name     string `json:"name,omitempty"`
+	Firmware string `json:"firmware,omitempty"`
+	// Features lists what the panel has, such as pager, switch, dimmer, led
+	// and speaker.
+	Features []string `json:"features,omitempty"`
+}
+
+// Welcome is the node's response to a registration.
+type Welcome struct {
+	Session string `json:"session"`
+	// HeartbeatSeconds is how often the panel should send a heartbeat.
+	HeartbeatSeconds int `json:"heartbeat_seconds"`
+	// PollSeconds is the longest a poll for commands is held open.
+	PollSeconds int       `json:"poll_seconds"`
+	Commands    []Command `json:"commands"`
+}
+
+// Heartbeat reports that the panel is still there, with its readings.
+type Heartbeat struct {
+	Session string `json:"session"`
+	// RSSI is the Wi-Fi signal strength, in dBm.
+	RSSI int `json:"rssi"`
+	// Dimmer is the dimmer knob's reading, as a percentage.
+	Dimmer        int   `json:"dimmer"`
+	UptimeSeconds int64 `json:"uptime_seconds,omitempty"`
+}
+
+// Event is a press of one of the panel's buttons. Seq counts up from 1 with
+// each new event, and starts again when the panel registers.
+type Event struct {
+	Session string `json:"session"`
+	Seq     uint64 `json:"seq"`
+	Type    string `json:"type"`
+}
+
+// Command is feedback for the panel to play.
+type Command struct {
+	ID    uint64    `json:"id"`
+	Type  string    `json:"type"`
+	Level int       `json:"level,omitempty"`
+	At    time.Time `json:"at"`
+}
+
+// Commands is the response to heartbeats, events and polls.
+type Commands struct {
+	Commands []Command `json:"commands"`
+}
+
+// Status is what the node knows of a panel.
+type Status struct {
+	Registration
+	Online        bool      `json:"online"`
+	RSSI          int       `json:"rssi"`
+	Dimmer        int       `json:"dimmer"`
+	UptimeSeconds int64     `json:"uptime_seconds"`
+	RegisteredAt  time.Time `json:"registered_at"`
+	LastSeen      time.Time `json:"last_seen"`
+	// Pending counts the commands waiting to be delivered.
+	Pending int `json:"pending"`
+}
+
+// maxPending limits the commands waiting for a panel. The oldest are
+// dropped first, as they are stale by then.
+const maxPending = 32
+
+// missedHeartbeats is how many heartbeats a panel can miss before it's
+// offline.
+const missedHeartbeats = 3
+
+type panel struct {
+	status  Status
+	session string
+	lastSeq uint64
+	pending []Command
+	// wake is closed when a command is queued, to end the polls waiting
+	wake chan struct{}
+}
+
+// Hub keeps the registered panels, and the commands waiting for them.
+type Hub struct {
+	heartbeat time.Duration
+	pollWait  time.Duration
+
+	lock    sync.Mutex
+	panels  map[string]*panel
+	lastCmd uint64
+}
+
+// NewHub creates a hub that asks panels for a heartbeat every heartbeat,
+// and holds polls open for up to pollWait.
+func NewHub(heartbeat, pollWait time.Duration) *Hub {
+	return &Hub{
+		heartbeat: heartbeat,
+		pollWait:  pollWait,
+		panels:    make(map[string]*panel),
+	}
+}
+
+// Register starts a new session for a panel, replacing any it had. Commands
+// waiting for it are kept.
+func (h *Hub) Register(r Registration) (Welcome, error) {
+	if r.ID == "" || len(r.ID) > 64 {
+		return Welcome{}, fmt.Errorf("%w: the id must be 1 to 64 characters", ErrInvalid)
+	}
+	session, err := newSession()
+	if err != nil {
+		return Welcome{}, err
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	now := time.Now()
+	p, ok := h.panels[r.ID]
+	if !ok {
+		p = &panel{wake: make(chan struct{})}
+		h.panels[r.ID] = p
+	}
+	p.session = session
+	p.lastSeq = 0
+	p.status = Status{Registration: r, RegisteredAt: now, LastSeen: now}
+	return Welcome{
+		Session:          session,
+		HeartbeatSeconds: int(h.heartbeat.Seconds()),
+		PollSeconds:      int(h.pollWait.Seconds()),
+		Commands:         p.take(),
+	}, nil
+}
+
+// Heartbeat records a panel's readings, and returns the commands waiting
+// for it.
+func (h *Hub) Heartbeat(id string, hb Heartbeat) ([]Command, error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	p, err := h.session(id, hb.Session)
+	if err != nil {
+		return nil, err
+	}
+	p.status.RSSI = hb.RSSI
+	p.status.Dimmer = hb.Dimmer
+	p.status.UptimeSeconds = hb.UptimeSeconds
+	return p.take(), nil
+}
+
+// Event checks a panel's event. It returns false for an event that was
+// already received, which shouldn't be acted on again.
+func (h *Hub) Event(id string, e Event) (bool, error) {
+	if !validEvent(e.Type) {
+		return false, fmt.Errorf("%w: unknown event type %q - valid options: %v", ErrInvalid, e.Type, EventTypes)
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	p, err := h.session(id, e.Session)
+	if err != nil {
+		return false, err
+	}
+	if e.Seq != 0 && e.Seq <= p.lastSeq {
+		return false, nil
+	}
+	p.lastSeq = e.Seq
+	return true, nil
+}
+
+// Take returns the commands waiting for a panel.
+func (h *Hub) Take(id string) []Command {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if p, ok := h.panels[id]; ok {
+		return p.take()
+	}
+	return []Command{}
+}
+
+// Poll waits for commands for a panel, for up to wait or the hub's longest
+// poll. It returns no commands if none came in time.
+func (h *Hub) Poll(ctx context.Context, id, session string, wait time.Duration) ([]Command, error) {
+	if wait <= 0 || wait > h.pollWait {
+		wait = h.pollWait
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	for {
+		h.lock.Lock()
+		p, err := h.session(id, session)
+		if err != nil {
+			h.lock.Unlock()
+			return nil, err
+		}
+		if len(p.pending) > 0 {
+			commands := p.take()
+			h.lock.Unlock()
+			return commands, nil
+		}
+		wake := p.wake
+		h.lock.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return []Command{}, nil
+		case <-timer.C:
+			return []Command{}, nil
+		case <-wake:
+		}
+	}
+}
+
+// Push queues a command for a panel.
+func (h *Hub) Push(id string, c Command) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	p, ok := h.panels[id]
+	if !ok {
+		return ErrUnknownPanel
+	}
+	h.push(p, c)
+	return nil
+}
+
+// PushAll queues a command for every panel that is online, or only for
+// those listed. It returns how many panels it was queued for.
+func (h *Hub) PushAll(c Command, only ...string) int {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	n := 0
+	for id, p := range h.panels {
+		if !h.online(p) || (len(only) > 0 && !contains(only, id)) {
+			continue
+		}
+		h.push(p, c)
+		n++
+	}
+	return n
+}
+
+// Panels returns the status of every panel, by ID.
+func (h *Hub) Panels() []Status {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	panels := make([]Status, 0, len(h.panels))
+	for _, p := range h.panels {
+		s := p.status
+		s.Online = h.online(p)
+		s.Pending = len(p.pending)
+		panels = append(panels, s)
+	}
+	sort.Slice(panels, func(i, j int) bool { return panels[i].ID < panels[j].ID })
+	return panels
+}
+
+// Health reports the hub as degraded while any registered panel is offline.
+func (h *Hub) Health() health.Report {
+	panels := h.Panels()
+	r := health.Report{Status: health.StatusOK, Details: map[string]interface{}{"panels": len(panels)}}
+	var offline []string
+	for _, p := range panels {
+		if !p.Online {
+			offline = append(offline, p.ID)
+		}
+	}
+	if len(offline) > 0 {
+		r.Status = health.StatusDegraded
+		r.Message = fmt.Sprintf("offline: %v", offline)
+	}
+	return r
+}
+
+// session finds the panel, and checks that it's using its current session.
+// It also records that the panel was seen.
+func (h *Hub) session(id, session string) (*panel, error) {
+	p, ok := h.panels[id]
+	if !ok {
+		return nil, ErrUnknownSession
+	}
+	if session == "" || session != p.session {
+		return nil, ErrUnknownSession
+	}
+	p.status.LastSeen = time.Now()
+	return p, nil
+}
+
+func (h *Hub) online(p *panel) bool {
+	return time.Since(p.status.LastSeen) <= missedHeartbeats*h.heartbeat
+}
+
+func (h *Hub) push(p *panel, c Command) {
+	h.lastCmd++
+	c.ID = h.lastCmd
+	if c.At.IsZero() {
+		c.At = time.Now()
+	}
+	p.pending = append(p.pending, c)
+	if len(p.pending) > maxPending {
+		p.pending = p.pending[len(p.pending)-maxPending:]
+	}
+	close(p.wake)
+	p.wake = make(chan struct{})
+}
+
+func (p *panel) take() []Command {
+	commands := p.pending
+	p.pending = nil
+	if commands == nil {
+		commands = []Command{}
+	}
+	return commands
+}
+
+func validEvent(t string) bool {
+	return contains(EventTypes, t)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func newSession() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
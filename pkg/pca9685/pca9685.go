@@ -0,0 +1,210 @@
+package pca9685
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/i2c"
+	"periph.io/x/conn/v3/physic"
+)
+
+// DefaultAddress is the chip's I2C address with none of the address pins pulled high.
+const DefaultAddress uint16 = 0x40
+
+// Channels is the number of PWM outputs on the chip.
+const Channels = 16
+
+// Steps is the number of duty cycle steps, as the chip has 12-bit resolution.
+const Steps = 4096
+
+// oscillator is the frequency of the chip's internal clock.
+const oscillator = 25 * physic.MegaHertz
+
+const (
+	regMode1    = 0x00
+	regMode2    = 0x01
+	regLED0     = 0x06 // ON_L, ON_H, OFF_L, OFF_H for each channel in turn
+	regAllLED   = 0xFA
+	regPrescale = 0xFE
+
+	mode1Restart = 0x80
+	mode1AutoInc = 0x20
+	mode1Sleep   = 0x10
+	mode2OutDrv  = 0x04 // totem pole outputs, to drive MOSFETs directly
+
+	fullOnOff = 0x10 // bit 4 of ON_H or OFF_H holds the output fully on or off
+)
+
+var ErrInvalidChannel = errors.New("invalid PCA9685 channel")
+
+// Dev is a PCA9685 16 channel, 12-bit PWM driver on an I2C bus. Every
+// channel shares the same PWM frequency.
+type Dev struct {
+	dev  i2c.Dev
+	freq physic.Frequency
+	lock sync.Mutex
+}
+
+// New wakes the chip and sets its PWM frequency. The chip supports roughly
+// 24Hz - 1.5kHz.
+func New(bus i2c.Bus, addr uint16, freq physic.Frequency) (*Dev, error) {
+	d := &Dev{dev: i2c.Dev{Bus: bus, Addr: addr}}
+	if err := d.write(regMode2, mode2OutDrv); err != nil {
+		return nil, err
+	}
+	if err := d.write(regMode1, mode1AutoInc); err != nil {
+		return nil, err
+	}
+	// The oscillator takes up to 500us to start after leaving sleep
+	time.Sleep(500 * time.Microsecond)
+	if err := d.SetFrequency(freq); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *Dev) String() string {
+	return fmt.Sprintf("PCA9685(%s)", d.dev.String())
+}
+
+// write sets consecutive registers, starting at reg.
+func (d *Dev) write(reg byte, values ...byte) error {
+	return d.dev.Tx(append([]byte{reg}, values...), nil)
+}
+
+// prescale calculates the prescaler value for the frequency, clamped to the
+// range the chip supports.
+func prescale(freq physic.Frequency) byte {
+	p := math.Round(float64(oscillator)/(Steps*float64(freq))) - 1
+	return byte(math.Max(3, math.Min(255, p)))
+}
+
+// SetFrequency changes the PWM frequency of every channel. The chip must be
+// asleep while the prescaler is changed, so the outputs stop briefly.
+func (d *Dev) SetFrequency(freq physic.Frequency) error {
+	if freq <= 0 {
+		return fmt.Errorf("invalid PCA9685 frequency %s", freq)
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	mode := make([]byte, 1)
+	if err := d.dev.Tx([]byte{regMode1}, mode); err != nil {
+		return err
+	}
+	awake := mode[0] &^ (mode1Sleep | mode1Restart)
+	if err := d.write(regMode1, awake|mode1Sleep); err != nil {
+		return err
+	}
+	p := prescale(freq)
+	if err := d.write(regPrescale, p); err != nil {
+		return err
+	}
+	if err := d.write(regMode1, awake); err != nil {
+		return err
+	}
+	time.Sleep(500 * time.Microsecond)
+	if err := d.write(regMode1, awake|mode1Restart|mode1AutoInc); err != nil {
+		return err
+	}
+	d.freq = oscillator / (Steps * physic.Frequency(int(p)+1))
+	return nil
+}
+
+// Frequency reports the PWM frequency the chip is actually running at,
+// after rounding to its prescaler.
+func (d *Dev) Frequency() physic.Frequency {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.freq
+}
+
+// channelRegisters encodes the duty cycle into the ON and OFF registers.
+func channelRegisters(duty gpio.Duty) []byte {
+	switch {
+	case duty <= 0:
+		return []byte{0, 0, 0, fullOnOff}
+	case duty >= gpio.DutyMax:
+		return []byte{0, fullOnOff, 0, 0}
+	}
+	off := int(math.Round(float64(duty) * Steps / float64(gpio.DutyMax)))
+	if off >= Steps {
+		off = Steps - 1
+	}
+	return []byte{0, 0, byte(off), byte(off >> 8)}
+}
+
+// SetDuty sets the duty cycle of one channel, rounded to the chip's 12 bits.
+func (d *Dev) SetDuty(channel int, duty gpio.Duty) error {
+	if channel < 0 || channel >= Channels {
+		return fmt.Errorf("%w: %d", ErrInvalidChannel, channel)
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.write(byte(regLED0+4*channel), channelRegisters(duty)...)
+}
+
+// Halt turns every channel off.
+func (d *Dev) Halt() error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.write(regAllLED, channelRegisters(0)...)
+}
+
+// Pin returns one of the chip's channels as a gpio.PinOut, so it can be
+// driven like any other LED pin.
+func (d *Dev) Pin(channel int) (*Pin, error) {
+	if channel < 0 || channel >= Channels {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidChannel, channel)
+	}
+	return &Pin{dev: d, channel: channel}, nil
+}
+
+// Pin is a single PCA9685 channel.
+type Pin struct {
+	dev     *Dev
+	channel int
+}
+
+func (p *Pin) String() string {
+	return p.Name()
+}
+
+// Name identifies the chip and channel, such as "PCA9685(I2C1(64)).LED3".
+func (p *Pin) Name() string {
+	return p.dev.String() + ".LED" + strconv.Itoa(p.channel)
+}
+
+// Number is the channel number, 0 - 15.
+func (p *Pin) Number() int {
+	return p.channel
+}
+
+// Function implements pin.Pin.
+func (p *Pin) Function() string {
+	return string(gpio.PWM)
+}
+
+// Halt turns the channel off.
+func (p *Pin) Halt() error {
+	return p.dev.SetDuty(p.channel, 0)
+}
+
+// Out holds the channel fully on or off.
+func (p *Pin) Out(l gpio.Level) error {
+	if l == gpio.High {
+		return p.dev.SetDuty(p.channel, gpio.DutyMax)
+	}
+	return p.dev.SetDuty(p.channel, 0)
+}
+
+// PWM sets the channel's duty cycle. The frequency is shared by the whole
+// chip and set with SetFrequency, so f is ignored.
+func (p *Pin) PWM(duty gpio.Duty, f physic.Frequency) error {
+	return p.dev.SetDuty(p.channel, duty)
+}
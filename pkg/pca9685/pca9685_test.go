@@ -0,0 +1,145 @@
+package pca9685
+
+import (
+	"errors"
+	"testing"
+
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/physic"
+)
+
+func newTestDev(t *testing.T, freq physic.Frequency) (*Dev, *Sim) {
+	sim := NewSim(DefaultAddress)
+	dev, err := New(sim, DefaultAddress, freq)
+	if err != nil {
+		t.Fatalf("starting the chip: %v", err)
+	}
+	return dev, sim
+}
+
+func TestNewWakesTheChip(t *testing.T) {
+	dev, sim := newTestDev(t, physic.KiloHertz)
+	if mode := sim.regs[regMode1]; mode&mode1Sleep != 0 || mode&mode1AutoInc == 0 {
+		t.Errorf("MODE1 is %#x, want awake with auto-increment", mode)
+	}
+	if mode := sim.regs[regMode2]; mode != mode2OutDrv {
+		t.Errorf("MODE2 is %#x, want totem pole outputs", mode)
+	}
+	// 25MHz / (4096 * 1kHz) rounds to a prescaler of 5, which runs at 1017Hz
+	if p := sim.regs[regPrescale]; p != 5 {
+		t.Errorf("PRE_SCALE is %d, want 5", p)
+	}
+	if dev.Frequency() != sim.Frequency() {
+		t.Errorf("the chip reports %s, but runs at %s", dev.Frequency(), sim.Frequency())
+	}
+}
+
+func TestSetFrequency(t *testing.T) {
+	tests := []struct {
+		freq physic.Frequency
+		want byte
+	}{
+		{200 * physic.Hertz, 30},
+		{physic.KiloHertz, 5},
+		{1526 * physic.Hertz, 3},
+		// Out of range frequencies are clamped to what the chip can do
+		{10 * physic.KiloHertz, 3},
+		{physic.Hertz, 255},
+	}
+	for _, tt := range tests {
+		t.Run(tt.freq.String(), func(t *testing.T) {
+			dev, sim := newTestDev(t, physic.KiloHertz)
+			if err := dev.SetFrequency(tt.freq); err != nil {
+				t.Fatal(err)
+			}
+			if p := sim.regs[regPrescale]; p != tt.want {
+				t.Errorf("PRE_SCALE is %d, want %d", p, tt.want)
+			}
+			if sim.regs[regMode1]&mode1Sleep != 0 {
+				t.Errorf("the chip was left asleep")
+			}
+		})
+	}
+}
+
+func TestSetDuty(t *testing.T) {
+	tests := []struct {
+		name string
+		duty gpio.Duty
+		// regs are ON_L, ON_H, OFF_L and OFF_H
+		regs [4]byte
+	}{
+		{"off", 0, [4]byte{0, 0, 0, fullOnOff}},
+		{"full", gpio.DutyMax, [4]byte{0, fullOnOff, 0, 0}},
+		{"half", gpio.DutyMax / 2, [4]byte{0, 0, 0x00, 0x08}},
+		{"quarter", gpio.DutyMax / 4, [4]byte{0, 0, 0x00, 0x04}},
+		{"one step", gpio.DutyMax / Steps, [4]byte{0, 0, 0x01, 0x00}},
+		// Just short of full can't be shown as 4096 steps, so it stops at 4095
+		{"nearly full", gpio.DutyMax - 1, [4]byte{0, 0, 0xFF, 0x0F}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dev, sim := newTestDev(t, physic.KiloHertz)
+			const channel = 7
+			if err := dev.SetDuty(channel, tt.duty); err != nil {
+				t.Fatal(err)
+			}
+			var got [4]byte
+			copy(got[:], sim.regs[regLED0+4*channel:])
+			if got != tt.regs {
+				t.Errorf("LED%d registers are % x, want % x", channel, got, tt.regs)
+			}
+			want := tt.duty
+			if tt.duty == gpio.DutyMax-1 {
+				want = gpio.DutyMax / Steps * (Steps - 1)
+			}
+			if d := sim.Duty(channel); d != want {
+				t.Errorf("LED%d outputs %d, want %d", channel, d, want)
+			}
+			// The other channels are left alone
+			if d := sim.Duty(channel + 1); d != 0 {
+				t.Errorf("LED%d outputs %d, want 0", channel+1, d)
+			}
+		})
+	}
+}
+
+func TestHaltTurnsEveryChannelOff(t *testing.T) {
+	dev, sim := newTestDev(t, physic.KiloHertz)
+	for ch := 0; ch < Channels; ch++ {
+		pin, err := dev.Pin(ch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := pin.Out(gpio.High); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := dev.Halt(); err != nil {
+		t.Fatal(err)
+	}
+	for ch := 0; ch < Channels; ch++ {
+		if d := sim.Duty(ch); d != 0 {
+			t.Errorf("LED%d outputs %d after halting", ch, d)
+		}
+	}
+}
+
+func TestInvalidChannel(t *testing.T) {
+	dev, _ := newTestDev(t, physic.KiloHertz)
+	for _, ch := range []int{-1, Channels} {
+		if err := dev.SetDuty(ch, gpio.DutyMax); !errors.Is(err, ErrInvalidChannel) {
+			t.Errorf("SetDuty(%d) got %v, want %v", ch, err, ErrInvalidChannel)
+		}
+		if _, err := dev.Pin(ch); !errors.Is(err, ErrInvalidChannel) {
+			t.Errorf("Pin(%d) got %v, want %v", ch, err, ErrInvalidChannel)
+		}
+	}
+}
+
+func TestSimRejectsOtherAddresses(t *testing.T) {
+	sim := NewSim(DefaultAddress)
+	if _, err := New(sim, DefaultAddress+1, physic.KiloHertz); err == nil {
+		t.Errorf("started a chip at an address with nothing there")
+	}
+}
@@ -0,0 +1,108 @@
+package pca9685
+
+import (
+	"fmt"
+	"sync"
+
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/physic"
+)
+
+// Sim is a simulated PCA9685 on its own I2C bus, for running the lights
+// without the hardware attached and for checking what would be driven.
+type Sim struct {
+	Addr uint16
+
+	lock sync.Mutex
+	regs [256]byte
+}
+
+// NewSim creates a simulated chip at the address, in its power-on state.
+func NewSim(addr uint16) *Sim {
+	s := &Sim{Addr: addr}
+	s.regs[regMode1] = mode1Sleep
+	s.regs[regPrescale] = 0x1E // 200Hz
+	for ch := 0; ch < Channels; ch++ {
+		s.regs[regLED0+4*ch+3] = fullOnOff
+	}
+	return s
+}
+
+func (s *Sim) String() string {
+	return fmt.Sprintf("SimI2C(%#x)", s.Addr)
+}
+
+// SetSpeed implements i2c.Bus. The simulated bus runs at any speed.
+func (s *Sim) SetSpeed(f physic.Frequency) error {
+	return nil
+}
+
+// Tx implements i2c.Bus. The first byte written selects the register, and
+// the rest are written from there with auto-increment. Reads start from the
+// selected register.
+func (s *Sim) Tx(addr uint16, w, r []byte) error {
+	if addr != s.Addr {
+		return fmt.Errorf("%s: no device at address %#x", s, addr)
+	}
+	if len(w) == 0 {
+		return fmt.Errorf("%s: no register selected", s)
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	reg := w[0]
+	for _, b := range w[1:] {
+		s.set(reg, b)
+		reg++
+	}
+	for i := range r {
+		r[i] = s.regs[w[0]+byte(i)]
+	}
+	return nil
+}
+
+// set writes one register. The lock must be held.
+func (s *Sim) set(reg, b byte) {
+	switch {
+	case reg == regMode1:
+		// RESTART clears itself once the outputs have restarted
+		b &^= mode1Restart
+	case reg == regPrescale && s.regs[regMode1]&mode1Sleep == 0:
+		// The prescaler can only be changed while asleep
+		return
+	case reg >= regAllLED && reg < regPrescale:
+		// The ALL_LED registers write through to every channel
+		for ch := 0; ch < Channels; ch++ {
+			s.regs[regLED0+4*ch+int(reg-regAllLED)] = b
+		}
+		return
+	}
+	s.regs[reg] = b
+}
+
+// Duty reports the duty cycle a channel is outputting.
+func (s *Sim) Duty(channel int) gpio.Duty {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.regs[regMode1]&mode1Sleep != 0 {
+		return 0
+	}
+	r := s.regs[regLED0+4*channel : regLED0+4*channel+4]
+	switch {
+	case r[3]&fullOnOff != 0:
+		return 0
+	case r[1]&fullOnOff != 0:
+		return gpio.DutyMax
+	}
+	on := int(r[0]) | int(r[1]&0x0F)<<8
+	off := int(r[2]) | int(r[3]&0x0F)<<8
+	steps := (off - on + Steps) % Steps
+	return gpio.Duty(int64(steps) * int64(gpio.DutyMax) / Steps)
+}
+
+// Frequency reports the PWM frequency set by the prescaler.
+func (s *Sim) Frequency() physic.Frequency {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return oscillator / (Steps * physic.Frequency(int(s.regs[regPrescale])+1))
+}
@@ -0,0 +1,190 @@
+// Package ratelimit limits how often clients may do something, such as call
+// the API, with a token bucket for each client.
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrInvalidRate = errors.New("invalid rate")
+
+// Rate allows Count events every Per on average, with bursts of up to Burst
+// at once. The zero Rate is no limit at all.
+type Rate struct {
+	Count int
+	Per   time.Duration
+	Burst int
+}
+
+// ParseRate reads a rate such as "10/s", "30/m" or "5/10s", optionally with
+// a burst, as in "6/m,burst=3". The burst defaults to the count. An empty
+// rate, "0" or "off" is no limit.
+func ParseRate(s string) (Rate, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "0" || s == "off" {
+		return Rate{}, nil
+	}
+	parts := strings.Split(s, ",")
+	countAndPer := strings.SplitN(parts[0], "/", 2)
+	if len(countAndPer) != 2 {
+		return Rate{}, fmt.Errorf("%w %q - expected a count per period, such as 10/s", ErrInvalidRate, s)
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(countAndPer[0]))
+	if err != nil || count < 0 {
+		return Rate{}, fmt.Errorf("%w %q - bad count", ErrInvalidRate, s)
+	}
+	per, err := parsePeriod(strings.TrimSpace(countAndPer[1]))
+	if err != nil || per <= 0 {
+		return Rate{}, fmt.Errorf("%w %q - bad period", ErrInvalidRate, s)
+	}
+	r := Rate{Count: count, Per: per, Burst: count}
+	for _, opt := range parts[1:] {
+		kv := strings.SplitN(strings.TrimSpace(opt), "=", 2)
+		if len(kv) != 2 || kv[0] != "burst" {
+			return Rate{}, fmt.Errorf("%w %q - unknown option %q", ErrInvalidRate, s, opt)
+		}
+		if r.Burst, err = strconv.Atoi(kv[1]); err != nil || r.Burst < 1 {
+			return Rate{}, fmt.Errorf("%w %q - bad burst", ErrInvalidRate, s)
+		}
+	}
+	if count == 0 {
+		return Rate{}, nil
+	}
+	return r, nil
+}
+
+// parsePeriod accepts a bare unit, such as s or m, as well as a duration.
+func parsePeriod(s string) (time.Duration, error) {
+	switch s {
+	case "s":
+		return time.Second, nil
+	case "m":
+		return time.Minute, nil
+	case "h":
+		return time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// UnmarshalText parses the rate from the environment.
+func (r *Rate) UnmarshalText(text []byte) error {
+	parsed, err := ParseRate(string(text))
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
+
+func (r Rate) String() string {
+	if !r.Limited() {
+		return "off"
+	}
+	per := r.Per.String()
+	switch r.Per {
+	case time.Second:
+		per = "s"
+	case time.Minute:
+		per = "m"
+	case time.Hour:
+		per = "h"
+	}
+	return fmt.Sprintf("%d/%s,burst=%d", r.Count, per, r.Burst)
+}
+
+// Limited reports whether the rate limits anything.
+func (r Rate) Limited() bool {
+	return r.Count > 0 && r.Per > 0
+}
+
+// interval is how long it takes to earn one token back.
+func (r Rate) interval() time.Duration {
+	return r.Per / time.Duration(r.Count)
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// sweepEvery is how often buckets that have filled up again are dropped.
+const sweepEvery = time.Minute
+
+// Limiter keeps a token bucket for each key, such as a client's address.
+// Buckets that have filled up again are dropped, so the keys don't pile up.
+type Limiter struct {
+	rate      Rate
+	lock      sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	// now is replaceable for testing.
+	now func() time.Time
+}
+
+// NewLimiter creates a limiter that allows each key the rate.
+func NewLimiter(rate Rate) *Limiter {
+	return &Limiter{
+		rate:    rate,
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Rate is the rate the limiter allows.
+func (l *Limiter) Rate() Rate {
+	return l.rate
+}
+
+// Allow takes a token from the key's bucket. When the bucket is empty, it
+// returns false and how long until the next token.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	if !l.rate.Limited() {
+		return true, 0
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.rate.Burst), last: now}
+		l.buckets[key] = b
+	}
+	interval := l.rate.interval()
+	b.tokens = math.Min(float64(l.rate.Burst), b.tokens+float64(now.Sub(b.last))/float64(interval))
+	b.last = now
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) * float64(interval))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// sweep drops the buckets that would be full by now, as they are no
+// different from a new one.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepEvery {
+		return
+	}
+	l.lastSweep = now
+	full := time.Duration(l.rate.Burst) * l.rate.interval()
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= full {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Len is how many keys have a bucket.
+func (l *Limiter) Len() int {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return len(l.buckets)
+}
@@ -0,0 +1,196 @@
+package ratelimit
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Rate
+		wantErr bool
+	}{
+		{in: "10/s", want: Rate{Count: 10, Per: time.Second, Burst: 10}},
+		{in: "30/m", want: Rate{Count: 30, Per: time.Minute, Burst: 30}},
+		{in: "100/h", want: Rate{Count: 100, Per: time.Hour, Burst: 100}},
+		{in: "5/10s", want: Rate{Count: 5, Per: 10 * time.Second, Burst: 5}},
+		{in: "6/m,burst=3", want: Rate{Count: 6, Per: time.Minute, Burst: 3}},
+		{in: " 6 / m , burst=3 ", want: Rate{Count: 6, Per: time.Minute, Burst: 3}},
+		// No limit
+		{in: ""},
+		{in: "0"},
+		{in: "off"},
+		{in: "0/s"},
+		{in: "10", wantErr: true},
+		{in: "x/s", wantErr: true},
+		{in: "-1/s", wantErr: true},
+		{in: "10/fortnight", wantErr: true},
+		{in: "10/0s", wantErr: true},
+		{in: "10/s,burst=0", wantErr: true},
+		{in: "10/s,burst=x", wantErr: true},
+		{in: "10/s,bucket=3", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseRate(tt.in)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidRate) {
+					t.Errorf("got %v, %v, want ErrInvalidRate", got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateStringRoundTrips(t *testing.T) {
+	for _, in := range []string{"10/s,burst=10", "6/m,burst=3", "5/10s,burst=2", "off"} {
+		r, err := ParseRate(in)
+		if err != nil {
+			t.Fatalf("%s: %v", in, err)
+		}
+		if got := r.String(); got != in {
+			t.Errorf("%s printed as %s", in, got)
+		}
+	}
+}
+
+// newTestLimiter returns a limiter with a clock that the test moves on.
+func newTestLimiter(rate Rate) (*Limiter, *time.Time) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	l := NewLimiter(rate)
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+func TestAllow(t *testing.T) {
+	// One token every 10 seconds, up to 3 at once
+	rate := Rate{Count: 6, Per: time.Minute, Burst: 3}
+
+	type call struct {
+		// after is how long since the previous call
+		after     time.Duration
+		key       string
+		allowed   bool
+		retryWait time.Duration
+	}
+	tests := []struct {
+		name  string
+		calls []call
+	}{
+		{
+			name: "burst, then wait",
+			calls: []call{
+				{0, "a", true, 0},
+				{0, "a", true, 0},
+				{0, "a", true, 0},
+				{0, "a", false, 10 * time.Second},
+				{4 * time.Second, "a", false, 6 * time.Second},
+			},
+		},
+		{
+			name: "refills one token at a time",
+			calls: []call{
+				{0, "a", true, 0},
+				{0, "a", true, 0},
+				{0, "a", true, 0},
+				{10 * time.Second, "a", true, 0},
+				{0, "a", false, 10 * time.Second},
+			},
+		},
+		{
+			name: "refills no more than the burst",
+			calls: []call{
+				{0, "a", true, 0},
+				{time.Hour, "a", true, 0},
+				{0, "a", true, 0},
+				{0, "a", true, 0},
+				{0, "a", false, 10 * time.Second},
+			},
+		},
+		{
+			name: "keys have their own buckets",
+			calls: []call{
+				{0, "a", true, 0},
+				{0, "a", true, 0},
+				{0, "a", true, 0},
+				{0, "a", false, 10 * time.Second},
+				{0, "b", true, 0},
+			},
+		},
+		{
+			name: "refused calls don't cost a token",
+			calls: []call{
+				{0, "a", true, 0},
+				{0, "a", true, 0},
+				{0, "a", true, 0},
+				{5 * time.Second, "a", false, 5 * time.Second},
+				{5 * time.Second, "a", true, 0},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, now := newTestLimiter(rate)
+			for i, c := range tt.calls {
+				*now = now.Add(c.after)
+				allowed, wait := l.Allow(c.key)
+				if allowed != c.allowed || wait != c.retryWait {
+					t.Fatalf("call %d: got %v, %v, want %v, %v", i, allowed, wait, c.allowed, c.retryWait)
+				}
+			}
+		})
+	}
+}
+
+func TestAllowUnlimited(t *testing.T) {
+	l, _ := newTestLimiter(Rate{})
+	for i := 0; i < 100; i++ {
+		if ok, _ := l.Allow("a"); !ok {
+			t.Fatalf("call %d refused without a limit", i)
+		}
+	}
+	if l.Len() != 0 {
+		t.Errorf("kept %d buckets without a limit", l.Len())
+	}
+}
+
+func TestSweep(t *testing.T) {
+	// The buckets are full again 30 seconds after their last call
+	rate := Rate{Count: 6, Per: time.Minute, Burst: 3}
+
+	tests := []struct {
+		name string
+		// a is when "a" calls, and b when "b" calls after it
+		a    []time.Duration
+		b    time.Duration
+		want int
+	}{
+		{"before the next sweep", []time.Duration{0}, 50 * time.Second, 2},
+		{"refilled at the sweep", []time.Duration{0}, sweepEvery, 1},
+		{"still refilling at the sweep", []time.Duration{0, 40 * time.Second}, sweepEvery, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, now := newTestLimiter(rate)
+			start := *now
+			for _, at := range tt.a {
+				*now = start.Add(at)
+				l.Allow("a")
+			}
+			*now = start.Add(tt.b)
+			l.Allow("b")
+			if got := l.Len(); got != tt.want {
+				t.Errorf("got %d buckets, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
 	"github.com/influxdata/influxdb-client-go/v2/api"
+	"github.com/klaital/wannetiot/pkg/health"
 	log "github.com/sirupsen/logrus"
 	"time"
 )
@@ -45,15 +46,91 @@ func (d AtmoData) Timestamp() time.Time {
 	return d.Ts
 }
 
+// LightsData is the estimated power draw and usage of the LED strips.
+type LightsData struct {
+	Node string
+	// Zone is empty for the totals across every zone
+	Zone         string
+	Watts        float64
+	KWhToday     float64
+	OnHoursToday float64
+	// DutyHours is each channel's duty cycle integrated over today, as the equivalent hours at 100%
+	DutyHours map[string]float64
+	Ts        time.Time
+}
+
+func (d LightsData) Fields() map[string]interface{} {
+	fields := map[string]interface{}{
+		"watts":          d.Watts,
+		"kwh_today":      d.KWhToday,
+		"on_hours_today": d.OnHoursToday,
+	}
+	for channel, h := range d.DutyHours {
+		fields[channel+"_duty_hours"] = h
+	}
+	return fields
+}
+func (d LightsData) Tags() map[string]string {
+	tags := map[string]string{
+		"node": d.Node,
+	}
+	if d.Zone != "" {
+		tags["zone"] = d.Zone
+	}
+	return tags
+}
+
+func (d LightsData) Measurement() string {
+	return "lights"
+}
+
+func (d LightsData) Timestamp() time.Time {
+	return d.Ts
+}
+
+// AmbientData is an ambient light reading.
+type AmbientData struct {
+	Node string
+	Lux  float64
+	// Level is the fraction of their brightness auto-brightness shows the scenes at
+	Level float64
+	Ts    time.Time
+}
+
+func (d AmbientData) Fields() map[string]interface{} {
+	return map[string]interface{}{
+		"lux":   d.Lux,
+		"level": d.Level,
+	}
+}
+func (d AmbientData) Tags() map[string]string {
+	return map[string]string{
+		"node": d.Node,
+	}
+}
+
+func (d AmbientData) Measurement() string {
+	return "ambient"
+}
+
+func (d AmbientData) Timestamp() time.Time {
+	return d.Ts
+}
+
+// InfluxHealth tracks the writes to InfluxDB, for the node's health report.
+var InfluxHealth = health.NewTracker(0)
+
 func FlushInfluxBuffer(data []InfluxDataPoint, client api.WriteAPIBlocking) error{
 	for _, d := range data {
 		p := influxdb2.NewPoint(d.Measurement(), d.Tags(), d.Fields(), d.Timestamp())
 		err := client.WritePoint(context.Background(), p)
 		if err != nil {
 			log.WithField("point", p).WithError(err).Error("Error writing to influx")
+			InfluxHealth.Failure(err)
 			return err
 		}
 	}
+	InfluxHealth.Success()
 	return nil
 
 }